@@ -0,0 +1,575 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of execution algorithms.
+const (
+	// AlgoTWAP slice the parent order evenly over the Duration.
+	AlgoTWAP = "twap"
+
+	// AlgoVWAP slice the parent order proportional to the traded
+	// volume observed on market.
+	AlgoVWAP = "vwap"
+)
+
+// List of AlgoExecutor states.
+const (
+	AlgoStateRunning   = "running"
+	AlgoStatePaused    = "paused"
+	AlgoStateDone      = "done"
+	AlgoStateCancelled = "cancelled"
+)
+
+// AlgoProgressHandler define a callback that will be called after each
+// child order placed and when the execution stopped.
+type AlgoProgressHandler func(progress AlgoProgress)
+
+// AlgoOrder define the parent order to be executed by AlgoExecutor.
+type AlgoOrder struct {
+	// Amount, required, is the total amount of coin to be traded.
+	Amount *big.Rat
+
+	// LimitPrice, optional, is the price cap of child orders: the
+	// highest price for "buy" or the lowest price for "sell".
+	// If its set, each child order is placed as limit order with
+	// TimeInForce "IOC", otherwise as market order.
+	LimitPrice *big.Rat
+
+	// ParticipationRate, required for VWAP, define the maximum ratio
+	// between child order amount and the traded volume observed on
+	// market since the previous child order, for example 0.1 for 10%.
+	// If its set on TWAP, it limit the size of child order.
+	ParticipationRate *big.Rat
+
+	// Algo define the execution algorithm, its either AlgoTWAP or
+	// AlgoVWAP.
+	Algo string
+
+	Pair Pair
+
+	// Type of order, its either "buy" or "sell".
+	Type string
+
+	// Duration, required, define the total time to execute the parent
+	// order, excluding the time while paused.
+	Duration time.Duration
+
+	// Interval, required, define the time between child orders.
+	Interval time.Duration
+}
+
+// AlgoProgress contains the execution progress of parent order.
+type AlgoProgress struct {
+	// LastErr contains the last error when placing the child order.
+	LastErr error
+
+	// Filled contains the total amount of coin that has been traded.
+	Filled *big.Rat
+
+	// Remaining contains the amount of coin that has not been traded.
+	Remaining *big.Rat
+
+	// AveragePrice contains the average price of traded coin, or nil
+	// if nothing traded yet.
+	// The filled amount of market order whose price can not be known,
+	// from its response or from UserOrderInfo, is excluded.
+	AveragePrice *big.Rat
+
+	// State of execution, one of the AlgoState constants.
+	State string
+
+	// Children contains the number of child orders placed.
+	Children int
+
+	// Elapsed contains the execution time, excluding the time while
+	// paused.
+	Elapsed time.Duration
+}
+
+// AlgoOptions define the options for AlgoExecutor.
+type AlgoOptions struct {
+	// Client, required, is the REST client used to place the child
+	// orders.
+	// If the Client Markets is set, the child amount is rounded to the
+	// AmountPrecision and skipped if its less than AmountMinimum; the
+	// remainder that would be less than AmountMinimum is merged into
+	// the child.
+	Client *Client
+
+	// HandleProgress, optional, is the callback that will be called on
+	// each progress.
+	HandleProgress AlgoProgressHandler
+
+	// Order, required, is the parent order.
+	Order AlgoOrder
+}
+
+// AlgoExecutor execute the parent order by slicing it into child orders
+// over time using TWAP or VWAP algorithm.
+type AlgoExecutor struct {
+	opts AlgoOptions
+
+	filled   *big.Rat
+	priced   *big.Rat // Total coin filled with known price.
+	traded   *big.Rat // Total base traded, for average price.
+	observed *big.Rat // Market volume since last child order.
+	lastErr  error
+
+	// own contains the ID of child orders, excluded from the observed
+	// volume.
+	own map[int64]struct{}
+
+	// ownVolume is the amount filled by child orders that has not been
+	// excluded from the observed volume, to exclude the counterparty
+	// orders matched by child orders.
+	ownVolume *big.Rat
+
+	done     chan struct{}
+	finished chan struct{}
+
+	state    string
+	elapsed  time.Duration
+	children int
+
+	locker sync.Mutex
+}
+
+// NewAlgoExecutor create and validate new AlgoExecutor.
+// The execution is not started until Start is called.
+func NewAlgoExecutor(opts AlgoOptions) (algo *AlgoExecutor, err error) {
+	logp := "NewAlgoExecutor"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+
+	order := opts.Order
+	switch order.Algo {
+	case AlgoTWAP:
+	case AlgoVWAP:
+		if order.ParticipationRate == nil {
+			return nil, fmt.Errorf("%s: empty ParticipationRate", logp)
+		}
+	default:
+		return nil, fmt.Errorf("%s: unknown Algo %q", logp, order.Algo)
+	}
+	switch order.Type {
+	case TradeTypeAsk, TradeTypeBid:
+	default:
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeType)
+	}
+	err = order.Pair.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if order.Amount == nil || !order.Amount.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidAmount)
+	}
+	if order.ParticipationRate != nil && !order.ParticipationRate.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: invalid ParticipationRate", logp)
+	}
+	if order.Duration <= 0 || order.Interval <= 0 {
+		return nil, fmt.Errorf("%s: invalid Duration or Interval", logp)
+	}
+
+	algo = &AlgoExecutor{
+		opts:     opts,
+		filled:   big.NewRat(0),
+		priced:   big.NewRat(0),
+		traded:   big.NewRat(0),
+		observed: big.NewRat(0),
+
+		own:       make(map[int64]struct{}),
+		ownVolume: big.NewRat(0),
+		finished:  make(chan struct{}),
+	}
+	return algo, nil
+}
+
+// Cancel stop the execution.
+// The child order that is being placed is not cancelled.
+func (algo *AlgoExecutor) Cancel() {
+	algo.finish(AlgoStateCancelled)
+}
+
+// HandleTrade observe the traded volume on market, usually from the
+// NotifTrades of WebSocketPublic.
+// The Start method call it automatically for each trade in notification
+// channel.
+//
+// The volume of child orders is excluded: the trade of child order is
+// ignored, and the volume matched by child orders is subtracted from the
+// next trades.
+func (algo *AlgoExecutor) HandleTrade(trade *Trade) {
+	if trade == nil || Pair(trade.Pair) != algo.opts.Order.Pair {
+		return
+	}
+	volume := publicTradeVolume(trade)
+	if volume == nil {
+		return
+	}
+
+	algo.locker.Lock()
+	defer algo.locker.Unlock()
+
+	_, isOwn := algo.own[trade.ID]
+	if isOwn {
+		return
+	}
+	volume = big.NewRat(volume)
+	if algo.ownVolume.IsGreater(volume) {
+		algo.ownVolume.Sub(volume)
+		return
+	}
+	volume.Sub(algo.ownVolume)
+	algo.ownVolume = big.NewRat(0)
+	algo.observed.Add(volume)
+}
+
+// Pause the execution.
+// The time while paused is not counted as part of Duration.
+func (algo *AlgoExecutor) Pause() {
+	algo.locker.Lock()
+	if algo.state == AlgoStateRunning {
+		algo.state = AlgoStatePaused
+	}
+	algo.locker.Unlock()
+}
+
+// Progress return the current execution progress.
+func (algo *AlgoExecutor) Progress() AlgoProgress {
+	algo.locker.Lock()
+	defer algo.locker.Unlock()
+	return algo.progress()
+}
+
+// Resume the paused execution.
+func (algo *AlgoExecutor) Resume() {
+	algo.locker.Lock()
+	if algo.state == AlgoStatePaused {
+		algo.state = AlgoStateRunning
+	}
+	algo.locker.Unlock()
+}
+
+// Start the execution in the background.
+// The notif parameter is usually the NotifTrades field from WebSocketPublic;
+// it is required for VWAP or if ParticipationRate is set, and may be nil
+// otherwise.
+// Calling Start more than once has no effect.
+func (algo *AlgoExecutor) Start(notif <-chan Trade) {
+	algo.locker.Lock()
+	defer algo.locker.Unlock()
+
+	if len(algo.state) > 0 {
+		return
+	}
+	algo.state = AlgoStateRunning
+	algo.done = make(chan struct{})
+
+	go algo.run(algo.done, notif)
+}
+
+// Wait until the execution done or cancelled, and return the final
+// progress.
+func (algo *AlgoExecutor) Wait() AlgoProgress {
+	<-algo.finished
+	return algo.Progress()
+}
+
+func (algo *AlgoExecutor) run(done chan struct{}, notif <-chan Trade) {
+	ticker := time.NewTicker(algo.opts.Order.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case trade, ok := <-notif:
+			if !ok {
+				// The notification channel is closed, for
+				// example the WebSocket has been closed.
+				if algo.isObserving() {
+					algo.fail(errors.New("trades notification closed"))
+					return
+				}
+				notif = nil
+				continue
+			}
+			algo.HandleTrade(&trade)
+		case <-ticker.C:
+			algo.slice()
+		}
+	}
+}
+
+// isObserving return true if the execution require the observed volume.
+func (algo *AlgoExecutor) isObserving() bool {
+	return algo.opts.Order.Algo == AlgoVWAP || algo.opts.Order.ParticipationRate != nil
+}
+
+// fail cancel the execution with an error.
+func (algo *AlgoExecutor) fail(err error) {
+	algo.locker.Lock()
+	algo.lastErr = err
+	algo.locker.Unlock()
+
+	log.Printf("AlgoExecutor: %s %s: %s", algo.opts.Order.Pair,
+		algo.opts.Order.Type, err)
+	algo.finish(AlgoStateCancelled)
+}
+
+// slice place the next child order based on the elapsed time or observed
+// volume, and finish the execution when the parent order has been filled
+// or the Duration has been elapsed.
+func (algo *AlgoExecutor) slice() {
+	order := algo.opts.Order
+
+	algo.locker.Lock()
+	if algo.state != AlgoStateRunning {
+		algo.locker.Unlock()
+		return
+	}
+	algo.elapsed += order.Interval
+	isLast := algo.elapsed >= order.Duration
+
+	remaining := big.SubRat(order.Amount, algo.filled)
+	observed := algo.observed
+	algo.observed = big.NewRat(0)
+
+	var amount *big.Rat
+	if order.Algo == AlgoTWAP {
+		elapsed := algo.elapsed
+		if isLast {
+			elapsed = order.Duration
+		}
+		amount = big.MulRat(order.Amount, int64(elapsed))
+		amount.Quo(int64(order.Duration))
+		amount.Sub(algo.filled)
+	} else {
+		amount = big.MulRat(observed, order.ParticipationRate)
+	}
+	if order.Algo == AlgoTWAP && order.ParticipationRate != nil {
+		limit := big.MulRat(observed, order.ParticipationRate)
+		if amount.IsGreater(limit) {
+			amount = limit
+		}
+	}
+	if amount.IsGreater(remaining) {
+		amount = remaining
+	}
+	algo.locker.Unlock()
+
+	amount = algo.sizeChild(amount, remaining)
+	if amount != nil && amount.IsGreaterThanZero() {
+		algo.placeChild(amount)
+	}
+
+	algo.locker.Lock()
+	isFilled := !algo.filled.IsLess(order.Amount)
+	algo.locker.Unlock()
+
+	if isFilled || isLast {
+		algo.finish(AlgoStateDone)
+		return
+	}
+	algo.notify()
+}
+
+// sizeChild round the child amount using the market information.
+// It return nil if the amount is less than the minimum amount.
+// If the amount that remain after the child is less than the minimum, it
+// is merged into the child, so the last child is never less than the
+// minimum.
+func (algo *AlgoExecutor) sizeChild(amount, remaining *big.Rat) *big.Rat {
+	markets := algo.opts.Client.Markets
+	if markets == nil {
+		return amount
+	}
+	info := markets.Get(algo.opts.Order.Pair)
+	if info == nil {
+		return amount
+	}
+	amount = info.RoundAmount(amount)
+	if info.AmountMinimum == nil {
+		return amount
+	}
+	rest := big.SubRat(remaining, amount)
+	if rest.IsGreaterThanZero() && rest.IsLess(info.AmountMinimum) {
+		amount = info.RoundAmount(remaining)
+	}
+	if amount.IsLess(info.AmountMinimum) {
+		return nil
+	}
+	return amount
+}
+
+// placeChild place the child order and record its filled amount.
+func (algo *AlgoExecutor) placeChild(amount *big.Rat) {
+	order := algo.opts.Order
+
+	ob := NewOrderBuilder(order.Pair).Amount(amount)
+	if order.LimitPrice != nil {
+		ob.Limit(order.LimitPrice).ImmediateOrCancel()
+	} else {
+		ob.Market()
+	}
+
+	var (
+		tres *TradeResponse
+		treq *TradeRequest
+		err  error
+	)
+	if order.Type == TradeTypeAsk {
+		treq, err = ob.Ask().Build()
+		if err == nil {
+			tres, err = algo.opts.Client.TradeAsk(treq)
+		}
+	} else {
+		treq, err = ob.Bid().Build()
+		if err == nil {
+			tres, err = algo.opts.Client.TradeBid(treq)
+		}
+	}
+
+	filled, priced, traded := fillOf(tres)
+	if priced.IsLess(filled) && tres.Order != nil {
+		// The price of market order is not known from response,
+		// get the traded base amount from the order information.
+		info, errInfo := algo.opts.Client.UserOrderInfo(order.Pair.String(),
+			tres.Order.ID)
+		if errInfo == nil {
+			_, infoPriced, infoTraded := fillOf(&TradeResponse{Order: info})
+			if infoPriced.IsGreater(priced) {
+				priced, traded = infoPriced, infoTraded
+			}
+		}
+	}
+
+	algo.locker.Lock()
+	algo.children++
+	algo.filled.Add(filled)
+	algo.priced.Add(priced)
+	algo.traded.Add(traded)
+	if tres != nil && tres.Order != nil {
+		algo.own[tres.Order.ID] = struct{}{}
+		algo.ownVolume.Add(filled)
+	}
+	if err != nil {
+		algo.lastErr = err
+	}
+	algo.locker.Unlock()
+
+	if err != nil {
+		log.Printf("AlgoExecutor: %s %s %s: %s", order.Pair, order.Type,
+			amount, err)
+	}
+}
+
+// finish set the final state of execution.
+func (algo *AlgoExecutor) finish(state string) {
+	algo.locker.Lock()
+	switch algo.state {
+	case AlgoStateDone, AlgoStateCancelled:
+		algo.locker.Unlock()
+		return
+	}
+	algo.state = state
+	if algo.done != nil {
+		close(algo.done)
+	}
+	close(algo.finished)
+	algo.locker.Unlock()
+
+	algo.notify()
+}
+
+func (algo *AlgoExecutor) notify() {
+	if algo.opts.HandleProgress == nil {
+		return
+	}
+	algo.opts.HandleProgress(algo.Progress())
+}
+
+// progress return the current progress.
+// It must be called while holding the lock.
+func (algo *AlgoExecutor) progress() (progress AlgoProgress) {
+	progress = AlgoProgress{
+		LastErr:   algo.lastErr,
+		Filled:    big.NewRat(algo.filled),
+		Remaining: big.SubRat(algo.opts.Order.Amount, algo.filled),
+		State:     algo.state,
+		Children:  algo.children,
+		Elapsed:   algo.elapsed,
+	}
+	if algo.priced.IsGreaterThanZero() {
+		progress.AveragePrice = big.QuoRat(algo.traded, algo.priced)
+	}
+	return progress
+}
+
+// fillOf return the filled coin amount, the part of filled amount whose
+// price is known, and the traded base amount of that part, from the
+// response of child order.
+// The market order does not have price, so its filled amount is only
+// priced from the matched trades or from the BaseFilled.
+func fillOf(tres *TradeResponse) (filled, priced, traded *big.Rat) {
+	filled = big.NewRat(0)
+	priced = big.NewRat(0)
+	traded = big.NewRat(0)
+	if tres == nil {
+		return filled, priced, traded
+	}
+	for _, trade := range tres.Trades {
+		if trade.CoinAmount == nil {
+			continue
+		}
+		filled.Add(trade.CoinAmount)
+		price := trade.Price
+		if price == nil && tres.Order != nil {
+			price = tres.Order.Price
+		}
+		if price != nil {
+			priced.Add(trade.CoinAmount)
+			traded.Add(big.MulRat(trade.CoinAmount, price))
+		}
+	}
+	order := tres.Order
+	if order != nil && order.CoinFilled != nil && order.CoinFilled.IsGreater(filled) {
+		// The response does not contains the list of matched
+		// trades, use the order price.
+		filled = big.NewRat(order.CoinFilled)
+		if order.BaseFilled != nil && order.BaseFilled.IsGreaterThanZero() {
+			priced = big.NewRat(filled)
+			traded = big.NewRat(order.BaseFilled)
+		} else if order.Price != nil {
+			priced = big.NewRat(filled)
+			traded = big.MulRat(filled, order.Price)
+		}
+	}
+	return filled, priced, traded
+}
+
+// publicTradeVolume return the matched amount from public trade
+// notification, or nil if the trade is not matched.
+func publicTradeVolume(trade *Trade) *big.Rat {
+	if trade.Status == TradeStatusFilled && trade.CoinAmount != nil {
+		return trade.CoinAmount
+	}
+	if trade.CoinFilled != nil && trade.CoinFilled.IsGreaterThanZero() {
+		return trade.CoinFilled
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestAlgoExecutor(t *testing.T) {
+	var amounts []string
+
+	// The server fill all market orders at price 100.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err := req.ParseForm()
+		if err != nil {
+			t.Error(err)
+		}
+		amount := req.PostForm.Get(ParamNameAmount)
+		amounts = append(amounts, amount)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"order":{"id":1,"status":"filled",`+
+			`"price":"100","coin_filled":"%s"}}}`, amount)
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:            PairTokenomyIdk,
+		AmountMinimum:   big.NewRat(1),
+		AmountPrecision: 1,
+		IsActive:        true,
+	}})
+
+	t.Run("TWAP", func(t *testing.T) {
+		amounts = nil
+
+		algo, err := NewAlgoExecutor(AlgoOptions{
+			Client: cl,
+			Order: AlgoOrder{
+				Amount:   big.NewRat(10),
+				Algo:     AlgoTWAP,
+				Pair:     PairTokenomyIdk,
+				Type:     TradeTypeBid,
+				Duration: 4 * time.Hour,
+				Interval: time.Hour,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		algo.state = AlgoStateRunning
+
+		algo.slice()
+		algo.Pause()
+		algo.slice()
+		algo.Resume()
+		algo.slice()
+		algo.slice()
+		algo.slice()
+
+		progress := algo.Wait()
+		test.Assert(t, "amounts", []string{"2.5", "2.5", "2.5", "2.5"}, amounts)
+		test.Assert(t, "State", AlgoStateDone, progress.State)
+		test.Assert(t, "Filled", "10", progress.Filled.String())
+		test.Assert(t, "AveragePrice", "100", progress.AveragePrice.String())
+		test.Assert(t, "Elapsed", 4*time.Hour, progress.Elapsed)
+	})
+
+	t.Run("VWAP", func(t *testing.T) {
+		amounts = nil
+
+		algo, err := NewAlgoExecutor(AlgoOptions{
+			Client: cl,
+			Order: AlgoOrder{
+				Amount:            big.NewRat(3),
+				ParticipationRate: big.NewRat("0.1"),
+				Algo:              AlgoVWAP,
+				Pair:              PairTokenomyIdk,
+				Type:              TradeTypeAsk,
+				Duration:          time.Hour,
+				Interval:          time.Minute,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		algo.state = AlgoStateRunning
+
+		// Volume 5 is less than minimum amount after rate.
+		algo.HandleTrade(&Trade{Pair: PairTokenomyIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(5)})
+		algo.slice()
+		algo.HandleTrade(&Trade{Pair: PairTokenomyIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(20)})
+		algo.HandleTrade(&Trade{Pair: PairBitcoinIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(100)})
+		algo.slice()
+
+		// The child order and its counterparty are excluded from
+		// the volume.
+		algo.HandleTrade(&Trade{ID: 1, Pair: PairTokenomyIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(2)})
+		algo.HandleTrade(&Trade{ID: 9, Pair: PairTokenomyIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(2)})
+		test.Assert(t, "observed", "0", algo.observed.String())
+		algo.HandleTrade(&Trade{ID: 10, Pair: PairTokenomyIdk, Status: TradeStatusFilled, CoinAmount: big.NewRat(15)})
+		algo.slice()
+
+		progress := algo.Wait()
+		test.Assert(t, "amounts", []string{"2", "1"}, amounts)
+		test.Assert(t, "State", AlgoStateDone, progress.State)
+		test.Assert(t, "Remaining", "0", progress.Remaining.String())
+	})
+
+	t.Run("sizeChild", func(t *testing.T) {
+		algo, err := NewAlgoExecutor(AlgoOptions{
+			Client: cl,
+			Order: AlgoOrder{
+				Amount:   big.NewRat(10),
+				Algo:     AlgoTWAP,
+				Pair:     PairTokenomyIdk,
+				Type:     TradeTypeBid,
+				Duration: time.Hour,
+				Interval: time.Minute,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cases := []struct {
+			amount    string
+			remaining string
+			exp       string
+		}{
+			{amount: "2.5", remaining: "5", exp: "2.5"},
+			{amount: "2.5", remaining: "3", exp: "3"},
+			{amount: "0.5", remaining: "5"},
+			{amount: "0.5", remaining: "0.5"},
+		}
+		for _, c := range cases {
+			var got string
+			amount := algo.sizeChild(big.NewRat(c.amount), big.NewRat(c.remaining))
+			if amount != nil {
+				got = amount.String()
+			}
+			test.Assert(t, c.amount+" of "+c.remaining, c.exp, got)
+		}
+	})
+
+	t.Run("market price from order info", func(t *testing.T) {
+		var isInfoFailed bool
+
+		// The response of market order does not contains the price.
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if req.URL.Path == APIUserOrderInfo {
+				if isInfoFailed {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprint(w, `{"code":503,"message":"maintenance"}`)
+					return
+				}
+				fmt.Fprint(w, `{"data":{"id":7,"status":"filled",`+
+					`"coin_filled":"2","base_filled":"210"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"order":{"id":7,"status":"filled",`+
+				`"coin_filled":"2"}}}`)
+		}))
+		defer srv.Close()
+
+		cl, err := NewClient(&Environment{Address: srv.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, isFailed := range []bool{false, true} {
+			isInfoFailed = isFailed
+
+			algo, err := NewAlgoExecutor(AlgoOptions{
+				Client: cl,
+				Order: AlgoOrder{
+					Amount:   big.NewRat(2),
+					Algo:     AlgoTWAP,
+					Pair:     PairTokenomyIdk,
+					Type:     TradeTypeBid,
+					Duration: time.Hour,
+					Interval: time.Hour,
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			algo.state = AlgoStateRunning
+			algo.slice()
+
+			progress := algo.Wait()
+			test.Assert(t, "Filled", "2", progress.Filled.String())
+			if isFailed {
+				test.Assert(t, "AveragePrice unknown", true, progress.AveragePrice == nil)
+			} else {
+				test.Assert(t, "AveragePrice", "105", progress.AveragePrice.String())
+			}
+		}
+	})
+
+	t.Run("closed notification", func(t *testing.T) {
+		algo, err := NewAlgoExecutor(AlgoOptions{
+			Client: cl,
+			Order: AlgoOrder{
+				Amount:            big.NewRat(3),
+				ParticipationRate: big.NewRat("0.1"),
+				Algo:              AlgoVWAP,
+				Pair:              PairTokenomyIdk,
+				Type:              TradeTypeAsk,
+				Duration:          time.Hour,
+				Interval:          time.Minute,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		notif := make(chan Trade)
+		close(notif)
+		algo.Start(notif)
+
+		progress := algo.Wait()
+		test.Assert(t, "State", AlgoStateCancelled, progress.State)
+		test.Assert(t, "LastErr", "trades notification closed", progress.LastErr.Error())
+	})
+}
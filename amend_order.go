@@ -0,0 +1,187 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of RefID used in AmendOrder bulk request.
+const (
+	amendRefIDOrder  = 1
+	amendRefIDCancel = 2
+)
+
+// AmendResult contains the result of AmendOrder.
+type AmendResult struct {
+	// Order contains the replacement order, or nil if the replacement
+	// is not placed or has been rolled back.
+	Order *Trade
+
+	// Cancelled contains the original order, if its has been cancelled.
+	Cancelled *Trade
+
+	// OrderErr contains the error when placing the replacement order.
+	OrderErr error
+
+	// CancelErr contains the error when cancelling the original order,
+	// for example when the order has been filled or closed.
+	CancelErr error
+
+	// IsApplied is true if the original order has been cancelled and
+	// the replacement order, if any, has been placed.
+	IsApplied bool
+
+	// IsRolledBack is true if the replacement order has been cancelled
+	// because the original order can not be cancelled.
+	IsRolledBack bool
+}
+
+// AmendOrder replace the open limit order with new price and/or amount
+// using single TradeBulk request that cancel the original order and place
+// the replacement order, in one round trip.
+//
+// If newPrice is nil, the replacement use the original price.
+//
+// The newAmount parameter define the new total amount of the order,
+// including the amount that has been filled.
+// The replacement amount is newAmount minus trade.CoinFilled.
+// If newAmount is nil, the replacement amount is trade.CoinRemain.
+// If the replacement amount is zero or less, the original order is
+// cancelled without replacement.
+// Since the replacement is sized before the request, the amount filled
+// after the trade is read is not accounted; the caller should pass the
+// latest state of order, for example from UserOrderInfo or the closed
+// orders broadcast.
+//
+// If the replacement order is placed but the original order can not be
+// cancelled (for example, it has been filled in the mean time) the
+// replacement order is cancelled back and the IsRolledBack is set to
+// true.
+//
+// The returned error is non-nil only if the request itself failed or the
+// rollback failed; the status of each item is reported in AmendResult.
+func (cl *Client) AmendOrder(trade *Trade, newPrice, newAmount *big.Rat) (
+	res *AmendResult, err error,
+) {
+	logp := "AmendOrder"
+
+	if trade == nil || trade.ID <= 0 {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeID)
+	}
+	switch trade.Type {
+	case TradeTypeAsk, TradeTypeBid:
+	default:
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeType)
+	}
+
+	price := newPrice
+	if price == nil {
+		price = trade.Price
+	}
+	amount := amendAmount(trade, newAmount)
+
+	tbReq := &TradeBulk{
+		Pair: Pair(trade.Pair),
+		Cancel: []*BulkOrderItem{{
+			TradeRequest: TradeRequest{
+				Type: trade.Type,
+				Pair: Pair(trade.Pair),
+			},
+			ID:    trade.ID,
+			RefID: amendRefIDCancel,
+		}},
+	}
+
+	var order *Trade
+
+	if amount.IsGreaterThanZero() {
+		var (
+			ob = NewOrderBuilder(Pair(trade.Pair)).
+				Limit(price).
+				Amount(amount).
+				RefID(amendRefIDOrder)
+			item *BulkOrderItem
+		)
+		if trade.Type == TradeTypeAsk {
+			ob.Ask()
+		} else {
+			ob.Bid()
+		}
+		item, err = ob.BuildBulkItem()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+		tbReq.Orders = []*BulkOrderItem{item}
+
+		order = &Trade{
+			Price:      copyRat(price),
+			CoinAmount: copyRat(amount),
+			CoinRemain: copyRat(amount),
+			Pair:       trade.Pair,
+			Type:       trade.Type,
+			Method:     TradeMethodLimit,
+		}
+	}
+
+	tbRes, err := cl.TradeBulk(tbReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	res = &AmendResult{}
+
+	orderResults := mapBulkItems(tbReq.Orders, tbRes.Orders, nil)
+	if len(orderResults) > 0 {
+		res.OrderErr = orderResults[0].Err
+		if res.OrderErr == nil {
+			order.ID = orderResults[0].ID
+			order.SubmitTime = tbReq.Timestamp
+			res.Order = order
+		}
+	}
+
+	cancelResults := mapBulkItems(tbReq.Cancel, tbRes.Cancel, nil)
+	res.CancelErr = cancelResults[0].Err
+	if res.CancelErr == nil {
+		res.Cancelled = trade
+	}
+
+	switch {
+	case res.CancelErr == nil:
+		res.IsApplied = res.OrderErr == nil
+	case res.Order != nil:
+		_, err = cl.TradeCancel(res.Order)
+		if err != nil {
+			return res, fmt.Errorf("%s: rollback order %d: %w", logp,
+				res.Order.ID, err)
+		}
+		res.Order = nil
+		res.IsRolledBack = true
+	}
+
+	return res, nil
+}
+
+// amendAmount return the amount for replacement order.
+func amendAmount(trade *Trade, newAmount *big.Rat) (amount *big.Rat) {
+	if newAmount != nil {
+		amount = big.NewRat(newAmount)
+		if trade.CoinFilled != nil {
+			amount.Sub(trade.CoinFilled)
+		}
+		return amount
+	}
+	if trade.CoinRemain != nil {
+		return big.NewRat(trade.CoinRemain)
+	}
+	amount = big.NewRat(trade.CoinAmount)
+	if trade.CoinFilled != nil {
+		amount.Sub(trade.CoinFilled)
+	}
+	return amount
+}
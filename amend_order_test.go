@@ -0,0 +1,57 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_AmendOrder(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	treq, err := NewOrderBuilder(PairBitcoinIdk).Bid().Limit(100).Amount(5).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tres, err := cl.TradeBid(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trade := ex.fill(tres.Order.ID, 2)
+
+	res, err := cl.AmendOrder(&trade, big.NewRat(101), big.NewRat(6))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "IsApplied", true, res.IsApplied)
+	test.Assert(t, "Cancelled.ID", tres.Order.ID, res.Cancelled.ID)
+	test.Assert(t, "bulk requests", 1, ex.count(APITradeBulk))
+
+	open := ex.open()
+	test.Assert(t, "open orders", 1, len(open))
+	test.Assert(t, "Order.ID", open[0].ID, res.Order.ID)
+	test.Assert(t, "Order.CoinAmount", "4", open[0].CoinAmount.String())
+	test.Assert(t, "Order.Price", "101", open[0].Price.String())
+
+	// The order has been filled, so the cancel failed and the
+	// replacement is cancelled back.
+	stale := *res.Order
+	ex.close(stale.ID, TradeStatusFilled)
+
+	res, err = cl.AmendOrder(&stale, big.NewRat(102), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "IsApplied", false, res.IsApplied)
+	test.Assert(t, "IsRolledBack", true, res.IsRolledBack)
+	if res.CancelErr == nil {
+		t.Fatal("want CancelErr")
+	}
+	test.Assert(t, "Order", (*Trade)(nil), res.Order)
+	test.Assert(t, "open orders", 0, len(ex.open()))
+}
@@ -0,0 +1,610 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// DefaultArbitrageCooldown define the default duration where the same
+// cycle is not executed again after its executed.
+const DefaultArbitrageCooldown = 10 * time.Second
+
+// ErrArbitragePartial is the error wrapped by all ArbitragePartialError,
+// so the partial execution can be checked using errors.Is.
+var ErrArbitragePartial = errors.New("arbitrage partially executed")
+
+// ArbitragePartialError define the execution of cycle that stop after
+// some of its legs has been executed, leaving the intermediate asset on
+// the account.
+type ArbitragePartialError struct {
+	// Err contains the error that stop the execution.
+	Err error
+
+	// Amount contains the amount of Asset received by the last
+	// executed leg, before fee.
+	Amount *big.Rat
+
+	// Asset contains the asset held after the last executed leg.
+	Asset string
+
+	// Executed contains the number of executed legs.
+	Executed int
+}
+
+// Error return the description of partial execution.
+func (perr *ArbitragePartialError) Error() string {
+	return fmt.Sprintf("%s: %d leg(s) executed, holding %s %s: %s",
+		ErrArbitragePartial, perr.Executed, perr.Amount, perr.Asset,
+		perr.Err)
+}
+
+// Is return true if the target is ErrArbitragePartial.
+func (perr *ArbitragePartialError) Is(target error) bool {
+	return target == ErrArbitragePartial
+}
+
+// Unwrap return the error that stop the execution.
+func (perr *ArbitragePartialError) Unwrap() error {
+	return perr.Err
+}
+
+// ArbitrageHandler define a callback that will be called for each
+// opportunity found by ArbitrageScanner.
+// If the AutoExecute options is true, the callback is called after the
+// legs executed, with the response of each executed leg and the error
+// that stop the execution, if any.
+// If some of the legs has been executed, the error is
+// ArbitragePartialError that contains the asset held by the account.
+type ArbitrageHandler func(opp ArbitrageOpportunity, tres []*TradeResponse, err error)
+
+// ArbitrageLeg contains single conversion in the arbitrage cycle.
+type ArbitrageLeg struct {
+	// AmountIn contains the amount of From asset spent on this leg.
+	AmountIn *big.Rat
+
+	// AmountOut contains the amount of To asset received on this leg,
+	// after fee.
+	AmountOut *big.Rat
+
+	// AveragePrice and WorstPrice contains the average and the last
+	// price level consumed in the book.
+	AveragePrice *big.Rat
+	WorstPrice   *big.Rat
+
+	// CoinAmount contains the amount of pair coin traded on this leg.
+	CoinAmount *big.Rat
+
+	Pair Pair
+
+	// Type of order, its "sell" if the From asset is the pair coin, or
+	// "buy" if the From asset is the pair base.
+	Type string
+
+	From string
+	To   string
+}
+
+// ArbitrageOpportunity contains the executable cycle that return more than
+// the minimum return.
+type ArbitrageOpportunity struct {
+	DetectedAt time.Time
+
+	// StartAmount is the amount of StartAsset spent on first leg.
+	StartAmount *big.Rat
+
+	// EndAmount is the amount of StartAsset received on last leg.
+	EndAmount *big.Rat
+
+	// Return contains the ratio of profit, (EndAmount/StartAmount)-1.
+	Return *big.Rat
+
+	StartAsset string
+
+	Legs []ArbitrageLeg
+}
+
+// ArbitrageOptions define the options for ArbitrageScanner.
+type ArbitrageOptions struct {
+	// Client, required, is the REST client used to fetch the market
+	// information and depths, and to execute the legs.
+	// If the Client Markets is set, its used to build the pair graph,
+	// otherwise the MarketInfo is fetched.
+	Client *Client
+
+	// HandleOpportunity, optional, is the callback that will be called
+	// on each opportunity.
+	HandleOpportunity ArbitrageHandler
+
+	// Amounts, required, define the amount spent on the first leg for
+	// each starting asset, for example {"idk": 1000000}.
+	// Only the cycles that start and end on these assets are scanned.
+	Amounts map[string]*big.Rat
+
+	// Fee, optional, is the taker fee on each leg in ratio, for example
+	// 0.003 for 0.3%.
+	Fee *big.Rat
+
+	// MinReturn, optional, is the minimum return of cycle, after fee,
+	// to be reported as opportunity, for example 0.001 for 0.1%.
+	// Default to zero, any cycle with positive return.
+	MinReturn *big.Rat
+
+	// AutoExecute, optional, execute the legs of each opportunity using
+	// fill-or-kill limit order at the worst price of each leg.
+	AutoExecute bool
+
+	// Cooldown, optional, define the duration where the same cycle is
+	// not executed again after its executed, since the books may not
+	// be updated yet.
+	// Default to DefaultArbitrageCooldown.
+	Cooldown time.Duration
+}
+
+// arbitrageCycle define the three legs that start and end on the same
+// asset.
+type arbitrageCycle struct {
+	start string
+	legs  [3]ArbitrageLeg
+}
+
+// ArbitrageScanner scan the triangular cycles, for example
+// idk -> btc -> eth -> idk, on the pair graph built from market
+// information, and report the cycle that return more than the minimum
+// return after walking the depths of local books and paying the fee.
+type ArbitrageScanner struct {
+	opts ArbitrageOptions
+
+	books  map[Pair]*OrderBook
+	cycles []*arbitrageCycle
+	byPair map[Pair][]*arbitrageCycle
+	pairs  []Pair
+
+	// executedAt contains the last time each cycle executed, by the
+	// cycle key.
+	executedAt map[string]time.Time
+
+	done chan struct{}
+
+	// removeNotif remove the depths channel from WebSocketPublic.
+	removeNotif func()
+
+	locker sync.Mutex
+
+	isRunning   bool
+	isExecuting bool
+}
+
+// NewArbitrageScanner create new ArbitrageScanner and build the triangular
+// cycles from active pairs.
+func NewArbitrageScanner(opts ArbitrageOptions) (arb *ArbitrageScanner, err error) {
+	logp := "NewArbitrageScanner"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if len(opts.Amounts) == 0 {
+		return nil, fmt.Errorf("%s: empty Amounts", logp)
+	}
+	for asset, amount := range opts.Amounts {
+		if amount == nil || !amount.IsGreaterThanZero() {
+			return nil, fmt.Errorf("%s: %s: %w", logp, asset, ErrInvalidAmount)
+		}
+	}
+	if opts.Fee != nil && (opts.Fee.IsLessThanZero() || !opts.Fee.IsLess(1)) {
+		return nil, fmt.Errorf("%s: invalid Fee", logp)
+	}
+	if opts.Cooldown <= 0 {
+		opts.Cooldown = DefaultArbitrageCooldown
+	}
+
+	markets := opts.Client.Markets
+	if markets == nil {
+		markets, err = opts.Client.MarketRegistry()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+
+	arb = &ArbitrageScanner{
+		opts:       opts,
+		books:      make(map[Pair]*OrderBook),
+		byPair:     make(map[Pair][]*arbitrageCycle),
+		executedAt: make(map[string]time.Time),
+	}
+	arb.buildCycles(markets)
+	sort.Slice(arb.pairs, func(x, y int) bool {
+		return arb.pairs[x] < arb.pairs[y]
+	})
+	if len(arb.cycles) == 0 {
+		return nil, fmt.Errorf("%s: no cycle found", logp)
+	}
+	return arb, nil
+}
+
+// Book return the local book of pair, or nil if the pair is not used by
+// any cycle.
+func (arb *ArbitrageScanner) Book(pair Pair) *OrderBook {
+	return arb.books[pair]
+}
+
+// Execute the legs of opportunity in order, using fill-or-kill limit
+// order at the worst price of each leg.
+// If the Client Markets is set, the coin amount of each leg is rounded down
+// to the AmountPrecision.
+// The execution stop on the first leg that failed.
+func (arb *ArbitrageScanner) Execute(opp ArbitrageOpportunity) (
+	tres []*TradeResponse, err error,
+) {
+	markets := arb.opts.Client.Markets
+	for x, leg := range opp.Legs {
+		amount := leg.CoinAmount
+		if markets != nil {
+			info := markets.Get(leg.Pair)
+			if info != nil {
+				amount = info.RoundAmount(amount)
+			}
+		}
+		ob := NewOrderBuilder(leg.Pair).
+			Limit(leg.WorstPrice).
+			Amount(amount).
+			FillOrKill()
+
+		var (
+			treq *TradeRequest
+			res  *TradeResponse
+		)
+		if leg.Type == TradeTypeAsk {
+			treq, err = ob.Ask().Build()
+			if err == nil {
+				res, err = arb.opts.Client.TradeAsk(treq)
+			}
+		} else {
+			treq, err = ob.Bid().Build()
+			if err == nil {
+				res, err = arb.opts.Client.TradeBid(treq)
+			}
+		}
+		if err != nil {
+			err = fmt.Errorf("leg %d %s %s: %w", x+1, leg.Type,
+				leg.Pair, err)
+			if x > 0 {
+				err = stranded(opp.Legs[x-1], tres[x-1], x, err)
+			}
+			return tres, fmt.Errorf("Execute: %w", err)
+		}
+		tres = append(tres, res)
+	}
+	return tres, nil
+}
+
+// stranded return the ArbitragePartialError after the leg, the last
+// executed leg, with its response.
+func stranded(leg ArbitrageLeg, res *TradeResponse, executed int, err error) error {
+	perr := &ArbitragePartialError{
+		Err:      err,
+		Asset:    leg.To,
+		Executed: executed,
+	}
+	coin, priced, base := fillOf(res)
+	if leg.Type == TradeTypeAsk {
+		if priced.IsLess(coin) {
+			// The base amount of market order is not known.
+			base = big.NewRat(0)
+		}
+		perr.Amount = base
+	} else {
+		perr.Amount = coin
+	}
+	if !perr.Amount.IsGreaterThanZero() {
+		// The response does not contains the filled amount, use
+		// the estimated amount.
+		perr.Amount = leg.AmountOut
+	}
+	return perr
+}
+
+// HandleDepths update the local book of pair and scan the cycles that
+// contains the pair.
+// The Start method call it automatically for each depths in notification
+// channel.
+func (arb *ArbitrageScanner) HandleDepths(depths *MarketDepths) {
+	if depths == nil {
+		return
+	}
+	pair := Pair(depths.Pair)
+	book := arb.books[pair]
+	if book == nil {
+		return
+	}
+	book.Update(depths)
+
+	for _, opp := range arb.scan(arb.byPair[pair]) {
+		arb.report(opp)
+	}
+}
+
+// Pairs return the list of pairs used by the cycles.
+func (arb *ArbitrageScanner) Pairs() []Pair {
+	return arb.pairs
+}
+
+// Scan all cycles using the current local books and return the
+// opportunities, ordered by the highest return.
+func (arb *ArbitrageScanner) Scan() (opps []ArbitrageOpportunity) {
+	return arb.scan(arb.cycles)
+}
+
+// Start initialize the local books from MarketDepths, subscribe the
+// depths of all pairs in cycles, and scan the cycles on each depths
+// broadcast from WebSocketPublic.
+// The depths are consumed from NotifyDepths, so the WebSocketPublic can be
+// shared with other consumers, for example MarketMaker.
+// Calling Start on running ArbitrageScanner has no effect.
+func (arb *ArbitrageScanner) Start(ws *WebSocketPublic) (err error) {
+	logp := "Start"
+
+	arb.locker.Lock()
+	defer arb.locker.Unlock()
+
+	if arb.isRunning {
+		return nil
+	}
+
+	for _, pair := range arb.pairs {
+		depths, err := arb.opts.Client.MarketDepths(pair.String())
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", logp, pair, err)
+		}
+		arb.books[pair].Reset(depths)
+	}
+
+	names := make([]string, 0, len(arb.pairs))
+	for _, pair := range arb.pairs {
+		names = append(names, pair.String())
+	}
+	_, err = ws.SubscribeDepths(names)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	notif, remove := ws.NotifyDepths()
+
+	arb.isRunning = true
+	arb.done = make(chan struct{})
+	arb.removeNotif = remove
+	go arb.run(arb.done, notif)
+	return nil
+}
+
+// Stop scanning the cycles.
+// The depths subscription is not removed.
+func (arb *ArbitrageScanner) Stop() {
+	arb.locker.Lock()
+	defer arb.locker.Unlock()
+
+	if !arb.isRunning {
+		return
+	}
+	close(arb.done)
+	arb.removeNotif()
+	arb.isRunning = false
+}
+
+func (arb *ArbitrageScanner) run(done chan struct{}, notif <-chan MarketDepths) {
+	for {
+		select {
+		case <-done:
+			return
+		case depths := <-notif:
+			arb.HandleDepths(&depths)
+		}
+	}
+}
+
+// buildCycles build the pair graph from active markets and enumerate the
+// triangular cycles that start on one of the asset in Amounts.
+func (arb *ArbitrageScanner) buildCycles(markets *MarketRegistry) {
+	graph := make(map[string]map[string]Pair)
+	link := func(from, to string, pair Pair) {
+		if graph[from] == nil {
+			graph[from] = make(map[string]Pair)
+		}
+		graph[from][to] = pair
+	}
+	for _, pair := range markets.Pairs() {
+		info := markets.Get(pair)
+		if !info.IsActive {
+			continue
+		}
+		link(pair.Coin().String(), pair.Base().String(), pair)
+		link(pair.Base().String(), pair.Coin().String(), pair)
+	}
+
+	starts := make([]string, 0, len(arb.opts.Amounts))
+	for asset := range arb.opts.Amounts {
+		starts = append(starts, asset)
+	}
+	sort.Strings(starts)
+
+	for _, start := range starts {
+		for x, pairX := range graph[start] {
+			for y, pairY := range graph[x] {
+				if y == start {
+					continue
+				}
+				pairZ, ok := graph[y][start]
+				if !ok {
+					continue
+				}
+				cycle := &arbitrageCycle{
+					start: start,
+					legs: [3]ArbitrageLeg{
+						newArbitrageLeg(pairX, start, x),
+						newArbitrageLeg(pairY, x, y),
+						newArbitrageLeg(pairZ, y, start),
+					},
+				}
+				arb.addCycle(cycle)
+			}
+		}
+	}
+}
+
+func (arb *ArbitrageScanner) addCycle(cycle *arbitrageCycle) {
+	arb.cycles = append(arb.cycles, cycle)
+	for _, leg := range cycle.legs {
+		if _, ok := arb.books[leg.Pair]; !ok {
+			arb.books[leg.Pair] = NewOrderBook(leg.Pair)
+			arb.pairs = append(arb.pairs, leg.Pair)
+		}
+		arb.byPair[leg.Pair] = append(arb.byPair[leg.Pair], cycle)
+	}
+}
+
+// report the opportunity to the handler, after executing it if
+// AutoExecute is true.
+// Only one opportunity is executed at a time, and the same cycle is not
+// executed again until the Cooldown passed; the opportunity that can not
+// be executed is reported with an error.
+func (arb *ArbitrageScanner) report(opp ArbitrageOpportunity) {
+	var (
+		tres []*TradeResponse
+		err  error
+	)
+	if arb.opts.AutoExecute {
+		key := opp.key()
+
+		arb.locker.Lock()
+		isExecuting := arb.isExecuting
+		lastAt := arb.executedAt[key]
+		isCooldown := !lastAt.IsZero() && time.Since(lastAt) < arb.opts.Cooldown
+		if !isExecuting && !isCooldown {
+			arb.isExecuting = true
+		}
+		arb.locker.Unlock()
+
+		switch {
+		case isExecuting:
+			err = errors.New("another opportunity is being executed")
+		case isCooldown:
+			err = fmt.Errorf("cycle %s is in cooldown", key)
+		default:
+			tres, err = arb.Execute(opp)
+			arb.locker.Lock()
+			arb.isExecuting = false
+			arb.executedAt[key] = time.Now()
+			arb.locker.Unlock()
+		}
+		if err != nil {
+			log.Printf("ArbitrageScanner: %s", err)
+		}
+	}
+	if arb.opts.HandleOpportunity != nil {
+		arb.opts.HandleOpportunity(opp, tres, err)
+	}
+}
+
+// scan the cycles and return the opportunities ordered by the highest
+// return.
+func (arb *ArbitrageScanner) scan(cycles []*arbitrageCycle) (opps []ArbitrageOpportunity) {
+	now := time.Now()
+	for _, cycle := range cycles {
+		opp, ok := arb.evaluate(cycle)
+		if !ok {
+			continue
+		}
+		if !opp.Return.IsGreaterThanZero() {
+			continue
+		}
+		if arb.opts.MinReturn != nil && opp.Return.IsLess(arb.opts.MinReturn) {
+			continue
+		}
+		opp.DetectedAt = now
+		opps = append(opps, opp)
+	}
+	sort.Slice(opps, func(x, y int) bool {
+		return opps[x].Return.IsGreater(opps[y].Return)
+	})
+	return opps
+}
+
+// evaluate walk the books on each leg of cycle, starting with the start
+// amount.
+// It return false if one of the books does not have enough depth.
+func (arb *ArbitrageScanner) evaluate(cycle *arbitrageCycle) (
+	opp ArbitrageOpportunity, ok bool,
+) {
+	start := arb.opts.Amounts[cycle.start]
+	opp = ArbitrageOpportunity{
+		StartAmount: big.NewRat(start),
+		StartAsset:  cycle.start,
+	}
+
+	amount := start
+	for _, leg := range cycle.legs {
+		depths := arb.books[leg.Pair].Snapshot()
+
+		var est *FillEstimate
+		if leg.Type == TradeTypeAsk {
+			est = depths.EstimateByCoin(TradeTypeAsk, amount)
+			leg.AmountOut = big.NewRat(est.Base)
+		} else {
+			est = depths.EstimateByBase(TradeTypeBid, amount)
+			leg.AmountOut = big.NewRat(est.Coin)
+		}
+		if !est.IsFilled {
+			return opp, false
+		}
+		leg.CoinAmount = est.Coin
+		leg.AmountIn = big.NewRat(amount)
+		leg.AveragePrice = est.AveragePrice
+		leg.WorstPrice = est.WorstPrice
+		if arb.opts.Fee != nil {
+			fee := big.MulRat(leg.AmountOut, arb.opts.Fee)
+			leg.AmountOut.Sub(fee)
+		}
+		opp.Legs = append(opp.Legs, leg)
+		amount = leg.AmountOut
+	}
+
+	opp.EndAmount = big.NewRat(amount)
+	opp.Return = big.QuoRat(amount, start)
+	opp.Return.Sub(1)
+	return opp, true
+}
+
+// key return the unique key of opportunity cycle, the start asset
+// followed by the pair of each leg.
+func (opp ArbitrageOpportunity) key() string {
+	var sb strings.Builder
+	sb.WriteString(opp.StartAsset)
+	for _, leg := range opp.Legs {
+		sb.WriteString(">")
+		sb.WriteString(leg.Pair.String())
+	}
+	return sb.String()
+}
+
+func newArbitrageLeg(pair Pair, from, to string) (leg ArbitrageLeg) {
+	leg = ArbitrageLeg{
+		Pair: pair,
+		Type: TradeTypeBid,
+		From: from,
+		To:   to,
+	}
+	if pair.Coin().String() == from {
+		leg.Type = TradeTypeAsk
+	}
+	return leg
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestArbitrageScanner(t *testing.T) {
+	ex, cl := newTestExchange(t)
+	cl.Markets = NewMarketRegistry([]MarketInfo{
+		{Pair: "btc_idk", IsActive: true},
+		{Pair: "eth_btc", IsActive: true},
+		{Pair: "eth_idk", IsActive: true},
+		{Pair: "sol_idk", IsActive: true},
+		{Pair: "sol_usdt", IsActive: false},
+	})
+
+	var (
+		gotOpp  ArbitrageOpportunity
+		gotTres []*TradeResponse
+		gotErr  error
+	)
+	opts := ArbitrageOptions{
+		Client:      cl,
+		Amounts:     map[string]*big.Rat{"idk": big.NewRat(1000)},
+		Fee:         big.NewRat("0.001"),
+		AutoExecute: true,
+		HandleOpportunity: func(opp ArbitrageOpportunity, tres []*TradeResponse, err error) {
+			gotOpp = opp
+			gotTres = tres
+			gotErr = err
+		},
+	}
+	arb, err := NewArbitrageScanner(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Pairs", []Pair{"btc_idk", "eth_btc", "eth_idk"}, arb.Pairs())
+
+	level := func(price string) []*Depth {
+		return []*Depth{{Price: big.NewRat(price), TotalCoin: big.NewRat(1000)}}
+	}
+	arb.Book("btc_idk").Reset(&MarketDepths{Asks: level("100"), Bids: level("99")})
+	arb.Book("eth_btc").Reset(&MarketDepths{Asks: level("0.1"), Bids: level("0.09")})
+	arb.Book("eth_idk").Reset(&MarketDepths{Asks: level("11"), Bids: level("9")})
+
+	test.Assert(t, "no opportunity", 0, len(arb.Scan()))
+
+	// The eth bid on idk rise, so idk -> btc -> eth -> idk is
+	// profitable.
+	arb.HandleDepths(&MarketDepths{Pair: "eth_idk", Bids: level("10.5")})
+
+	test.Assert(t, "StartAsset", "idk", gotOpp.StartAsset)
+	test.Assert(t, "EndAmount", "1046.85314895", gotOpp.EndAmount.String())
+
+	var route []string
+	for _, leg := range gotOpp.Legs {
+		route = append(route, leg.Type+" "+leg.Pair.String())
+	}
+	test.Assert(t, "route", []string{"buy btc_idk", "buy eth_btc", "sell eth_idk"}, route)
+	test.Assert(t, "executed", 3, len(gotTres))
+	test.Assert(t, "error", nil, gotErr)
+	test.Assert(t, "requests", 2, ex.count(APITradeBid))
+
+	// The same cycle is not executed again until the cooldown passed.
+	arb.HandleDepths(&MarketDepths{Pair: "eth_idk", Bids: level("10.5")})
+	test.Assert(t, "cooldown", "cycle idk>btc_idk>eth_btc>eth_idk is in cooldown",
+		gotErr.Error())
+	test.Assert(t, "requests after cooldown", 2, ex.count(APITradeBid))
+
+	// The last leg rejected, the asset from the second leg is held.
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeAsk {
+			return false
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte(`{"name":"ERR_TRADE_FILL_OR_KILL"}`))
+		return true
+	}
+	arb, err = NewArbitrageScanner(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	arb.Book("btc_idk").Reset(&MarketDepths{Asks: level("100"), Bids: level("99")})
+	arb.Book("eth_btc").Reset(&MarketDepths{Asks: level("0.1"), Bids: level("0.09")})
+	arb.Book("eth_idk").Reset(&MarketDepths{Asks: level("11"), Bids: level("10.5")})
+	arb.HandleDepths(&MarketDepths{Pair: "eth_idk", Bids: level("10.5")})
+
+	var perr *ArbitragePartialError
+	test.Assert(t, "partial", true, errors.As(gotErr, &perr))
+	test.Assert(t, "ErrArbitragePartial", true, errors.Is(gotErr, ErrArbitragePartial))
+	test.Assert(t, "partial Executed", 2, perr.Executed)
+	test.Assert(t, "partial Asset", "eth", perr.Asset)
+	test.Assert(t, "partial Amount", gotOpp.Legs[1].AmountOut.String(), perr.Amount.String())
+}
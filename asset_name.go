@@ -0,0 +1,64 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AssetName define the name of asset, for example "btc" or "idk".
+// A valid name contains only lower case letters and digits.
+type AssetName string
+
+// ParseAssetName parse and validate the asset name from string.
+// The name is converted to lower case before validated.
+// It will return ErrInvalidAsset if the name is empty or contains
+// characters other than letters and digits.
+func ParseAssetName(name string) (asset AssetName, err error) {
+	asset = AssetName(strings.ToLower(strings.TrimSpace(name)))
+	err = asset.Validate()
+	if err != nil {
+		return "", err
+	}
+	return asset, nil
+}
+
+// MarshalText implement the encoding.TextMarshaler interface.
+func (asset AssetName) MarshalText() ([]byte, error) {
+	return []byte(asset), nil
+}
+
+// String return the asset name.
+func (asset AssetName) String() string {
+	return string(asset)
+}
+
+// UnmarshalText implement the encoding.TextUnmarshaler interface.
+// Empty text is allowed, otherwise it must be a valid asset name.
+func (asset *AssetName) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		*asset = ""
+		return nil
+	}
+	*asset, err = ParseAssetName(string(text))
+	if err != nil {
+		return fmt.Errorf("AssetName.UnmarshalText: %q: %w", text, err)
+	}
+	return nil
+}
+
+// Validate the asset name.
+func (asset AssetName) Validate() error {
+	if len(asset) == 0 {
+		return ErrInvalidAsset
+	}
+	for _, c := range asset {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return ErrInvalidAsset
+		}
+	}
+	return nil
+}
@@ -0,0 +1,612 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// BalanceDiscrepancyHandler define the callback that will be called when
+// the local balances differ with the server during reconciliation.
+type BalanceDiscrepancyHandler func(discrepancies []BalanceDiscrepancy)
+
+// BalanceDiscrepancy define the difference between local and server
+// balance of single asset.
+type BalanceDiscrepancy struct {
+	// Local contains the balance tracked locally, and Server contains
+	// the balance from UserInfo.
+	Local  *big.Rat
+	Server *big.Rat
+
+	Asset string
+
+	// IsFrozen is true if the difference is on FrozenBalances.
+	IsFrozen bool
+}
+
+// BalanceTrackerOptions define the options for BalanceTracker.
+type BalanceTrackerOptions struct {
+	// Client, required, is the REST client used to fetch the balances
+	// and the open orders from server.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The handler is registered using AddOrdersClosedHandler.
+	WebSocket *WebSocketPrivate
+
+	// HandleDiscrepancy, optional, is the callback that will be called
+	// when reconciliation found the local balances differ with server.
+	HandleDiscrepancy BalanceDiscrepancyHandler
+
+	// Tolerance, optional, define the maximum difference between local
+	// and server balance that is not reported as discrepancy.
+	// Default to 0.
+	Tolerance *big.Rat
+
+	// ReconcileInterval, optional, define the interval to reconcile the
+	// local balances with the server.
+	// Default to DefaultReconcileInterval.
+	ReconcileInterval time.Duration
+}
+
+// balanceReconcileAttempts define the maximum number of fetches on
+// reconciliation, when the local balances keep changing while fetching
+// the server.
+const balanceReconcileAttempts = 3
+
+// BalanceTracker maintain the local copy of UserAssets, so the available
+// funds can be known without calling UserInfo on each trade.
+//
+// The balances are initialized from UserInfo and then updated locally from
+// the TradeResponse of placed and cancelled orders and from the closed
+// orders broadcast: the amount of open order is added to FrozenBalances,
+// the matched amount is moved between the coin and base Balances, and the
+// remaining frozen amount is released when the order closed.
+// The trading fee is not known locally, so it is corrected on the next
+// reconciliation.
+//
+// The orders in TradeBulk are tracked as open orders, since the bulk
+// response does not contains the order status, and the cancelled one are
+// released without their matched amount, which is corrected on the next
+// reconciliation.
+//
+// The tracker is updated automatically by Client and WebSocketPrivate by
+// setting it on their Balances field.
+type BalanceTracker struct {
+	opts   BalanceTrackerOptions
+	assets *UserAssets
+	orders map[int64]*balanceOrder
+
+	// early contains the closed orders broadcast that received before
+	// the response of order.
+	early map[int64]Trade
+
+	done chan struct{}
+
+	// seq is increased on each update, to detect the update while
+	// fetching the server on reconciliation.
+	seq uint64
+
+	locker sync.Mutex
+
+	isRunning bool
+}
+
+// balanceOrder contains the state of open order tracked by
+// BalanceTracker.
+type balanceOrder struct {
+	// frozen contains the amount of frozenAsset locked by the
+	// remaining amount of order when its placed.
+	frozen      *big.Rat
+	frozenAsset string
+
+	// filled contains the coin and traded contains the base amount
+	// that has been applied to the balances.
+	filled *big.Rat
+	traded *big.Rat
+
+	price     *big.Rat
+	pair      Pair
+	tradeType string
+}
+
+// NewBalanceTracker create new BalanceTracker.
+// The balances is empty until Start or Reconcile is called.
+func NewBalanceTracker(opts BalanceTrackerOptions) (bt *BalanceTracker, err error) {
+	logp := "NewBalanceTracker"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if opts.Tolerance == nil {
+		opts.Tolerance = big.NewRat(0)
+	}
+	if opts.ReconcileInterval <= 0 {
+		opts.ReconcileInterval = DefaultReconcileInterval
+	}
+
+	bt = &BalanceTracker{
+		opts:   opts,
+		assets: NewUserAssets(),
+		orders: make(map[int64]*balanceOrder),
+		early:  make(map[int64]Trade),
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(bt.HandleOrdersClosed)
+	}
+	return bt, nil
+}
+
+// Assets return the copy of local balances.
+func (bt *BalanceTracker) Assets() *UserAssets {
+	bt.locker.Lock()
+	defer bt.locker.Unlock()
+	return bt.assets.Copy()
+}
+
+// Available return the Balances minus FrozenBalances of asset.
+func (bt *BalanceTracker) Available(asset string) *big.Rat {
+	bt.locker.Lock()
+	defer bt.locker.Unlock()
+	return big.SubRat(bt.assets.Balances[asset], bt.assets.FrozenBalances[asset])
+}
+
+// HandleOrdersClosed apply the remaining matched amount of closed order to
+// the balances and release its frozen amount.
+// The order that is not tracked yet is kept until its response is handled,
+// in case the broadcast received before the response; the one that is
+// placed by other client is dropped on the next reconciliation.
+func (bt *BalanceTracker) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+
+	bt.locker.Lock()
+	defer bt.locker.Unlock()
+
+	bt.seq++
+	border := bt.orders[trade.ID]
+	if border == nil {
+		bt.early[trade.ID] = *trade
+		return
+	}
+	filled, traded := closedFillOf(trade, border.price)
+	bt.applyFill(border, filled, traded)
+	bt.unfreeze(border)
+	delete(bt.orders, trade.ID)
+}
+
+// HandleTradeResponse update the balances from the response of placed or
+// cancelled order.
+// If the response contains the User balances, the local balances is
+// replaced with it; otherwise the matched amount in Trades is applied and
+// the remaining amount of order is frozen.
+// The treq is optional, its used to fill the pair and price that are not
+// in tres.Order.
+func (bt *BalanceTracker) HandleTradeResponse(
+	tradeType string, treq *TradeRequest, tres *TradeResponse,
+) {
+	if tres == nil || tres.Order == nil {
+		return
+	}
+	order := tres.Order
+
+	bt.locker.Lock()
+	defer bt.locker.Unlock()
+
+	bt.seq++
+	isSnapshot := tres.User.UserAssets != nil && len(tres.User.Balances) > 0
+	if isSnapshot {
+		bt.assets = tres.User.UserAssets.Copy()
+	}
+
+	border := bt.orders[order.ID]
+	if border == nil {
+		if order.Status == TradeStatusCancelled {
+			// Cancelling order that is not tracked.
+			return
+		}
+		border = newBalanceOrder(tradeType, treq, order)
+		if border == nil {
+			return
+		}
+		if !isSnapshot {
+			bt.freeze(border)
+		}
+	}
+
+	if early, ok := bt.early[order.ID]; ok {
+		delete(bt.early, order.ID)
+		if len(order.Status) == 0 {
+			order = &early
+		}
+	}
+
+	filled, _, traded := fillOf(tres)
+	if len(order.Status) > 0 {
+		closedFilled, closedTraded := closedFillOf(order, border.price)
+		if closedFilled.IsGreater(filled) {
+			filled, traded = closedFilled, closedTraded
+		}
+	}
+	if isSnapshot {
+		border.filled, border.traded = filled, traded
+	} else {
+		bt.applyFill(border, filled, traded)
+	}
+
+	if len(order.Status) > 0 {
+		if !isSnapshot {
+			bt.unfreeze(border)
+		}
+		delete(bt.orders, order.ID)
+		return
+	}
+	bt.orders[order.ID] = border
+}
+
+// Reconcile fetch the balances from UserInfo and the open orders from
+// UserOrdersOpen, replace the local state with them, and report the
+// difference, if any, to HandleDiscrepancy.
+func (bt *BalanceTracker) Reconcile() (discrepancies []BalanceDiscrepancy, err error) {
+	discrepancies, err = bt.reconcile()
+	if err != nil {
+		return nil, fmt.Errorf("Reconcile: %w", err)
+	}
+	if len(discrepancies) > 0 && bt.opts.HandleDiscrepancy != nil {
+		bt.opts.HandleDiscrepancy(discrepancies)
+	}
+	return discrepancies, nil
+}
+
+// Start initialize the balances from server and reconcile them
+// periodically until Stop is called.
+func (bt *BalanceTracker) Start() (err error) {
+	bt.locker.Lock()
+	isRunning := bt.isRunning
+	bt.locker.Unlock()
+	if isRunning {
+		return nil
+	}
+
+	_, err = bt.reconcile()
+	if err != nil {
+		return fmt.Errorf("Start: %w", err)
+	}
+
+	bt.locker.Lock()
+	bt.isRunning = true
+	bt.done = make(chan struct{})
+	go bt.run(bt.done)
+	bt.locker.Unlock()
+	return nil
+}
+
+// Stop the periodic reconciliation.
+func (bt *BalanceTracker) Stop() {
+	bt.locker.Lock()
+	defer bt.locker.Unlock()
+
+	if bt.isRunning {
+		close(bt.done)
+		bt.isRunning = false
+	}
+}
+
+func (bt *BalanceTracker) run(done chan struct{}) {
+	ticker := time.NewTicker(bt.opts.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			_, err := bt.Reconcile()
+			if err != nil {
+				log.Printf("BalanceTracker: %s", err)
+			}
+		}
+	}
+}
+
+// reconcile replace the local state with the server and return the
+// differences.
+// If the local state is updated while fetching the server, the server
+// state may not contains the update, so it is fetched again.
+func (bt *BalanceTracker) reconcile() (discrepancies []BalanceDiscrepancy, err error) {
+	for x := 0; x < balanceReconcileAttempts; x++ {
+		bt.locker.Lock()
+		seq := bt.seq
+		bt.locker.Unlock()
+
+		server, orders, err := bt.fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		bt.locker.Lock()
+		if bt.seq != seq {
+			bt.locker.Unlock()
+			continue
+		}
+
+		discrepancies = diffBalances(bt.assets.Balances, server.Balances, bt.opts.Tolerance, false)
+		discrepancies = append(discrepancies,
+			diffBalances(bt.assets.FrozenBalances, server.FrozenBalances, bt.opts.Tolerance, true)...)
+
+		bt.assets = server
+		bt.orders = orders
+		// Any closed orders broadcast that does not have their
+		// response until now are not ours.
+		bt.early = make(map[int64]Trade)
+		bt.locker.Unlock()
+		return discrepancies, nil
+	}
+	return nil, fmt.Errorf("balances updated while fetching after %d attempts",
+		balanceReconcileAttempts)
+}
+
+// fetch the balances from UserInfo and the open orders from
+// UserOrdersOpen.
+func (bt *BalanceTracker) fetch() (server *UserAssets, orders map[int64]*balanceOrder, err error) {
+	user, err := bt.opts.Client.UserInfo()
+	if err != nil {
+		return nil, nil, err
+	}
+	pto, err := bt.opts.Client.UserOrdersOpen("")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	server = NewUserAssets()
+	if user != nil && user.UserAssets != nil {
+		server = user.UserAssets.Copy()
+	}
+
+	orders = make(map[int64]*balanceOrder)
+	for pair, tradesOpen := range pto {
+		sides := map[string][]Trade{
+			TradeTypeAsk: tradesOpen.Asks,
+			TradeTypeBid: tradesOpen.Bids,
+		}
+		for tradeType, list := range sides {
+			for _, order := range list {
+				order := order
+				order.Pair = pair
+				border := newBalanceOrder(tradeType, nil, &order)
+				if border == nil {
+					continue
+				}
+				// The amount filled before has been applied by
+				// server.
+				border.filled, border.traded = closedFillOf(&order, border.price)
+				orders[order.ID] = border
+			}
+		}
+	}
+	return server, orders, nil
+}
+
+// applyFill move the total matched coin and base amount of order between
+// the Balances, minus the amount that has been applied before.
+// It must be called while holding the lock.
+func (bt *BalanceTracker) applyFill(border *balanceOrder, filled, traded *big.Rat) {
+	deltaCoin := big.SubRat(filled, border.filled)
+	deltaBase := big.SubRat(traded, border.traded)
+	if !deltaCoin.IsGreaterThanZero() {
+		return
+	}
+	border.filled, border.traded = filled, traded
+
+	coin, base := border.pair.Coin().String(), border.pair.Base().String()
+	balances := bt.assets.Balances
+	if border.tradeType == TradeTypeBid {
+		balances[coin] = big.AddRat(balances[coin], deltaCoin)
+		balances[base] = big.SubRat(balances[base], deltaBase)
+	} else {
+		balances[coin] = big.SubRat(balances[coin], deltaCoin)
+		balances[base] = big.AddRat(balances[base], deltaBase)
+	}
+}
+
+// freeze add the locked amount of order to FrozenBalances.
+// It must be called while holding the lock.
+func (bt *BalanceTracker) freeze(border *balanceOrder) {
+	if border.frozen == nil {
+		return
+	}
+	frozen := bt.assets.FrozenBalances
+	frozen[border.frozenAsset] = big.AddRat(frozen[border.frozenAsset], border.frozen)
+}
+
+// unfreeze release the remaining locked amount of order from
+// FrozenBalances.
+// It must be called while holding the lock.
+func (bt *BalanceTracker) unfreeze(border *balanceOrder) {
+	if border.frozen == nil {
+		return
+	}
+	frozen := bt.assets.FrozenBalances
+	frozen[border.frozenAsset] = big.SubRat(frozen[border.frozenAsset], border.frozen)
+	if frozen[border.frozenAsset].IsLessThanZero() {
+		frozen[border.frozenAsset] = big.NewRat(0)
+	}
+	border.frozen = nil
+}
+
+// newBalanceOrder create the state of open order with the amount that it
+// locks: the remaining coin for "sell", or the remaining base for "buy".
+// It return nil if the pair or type of order is unknown.
+func newBalanceOrder(tradeType string, treq *TradeRequest, order *Trade) (
+	border *balanceOrder,
+) {
+	border = &balanceOrder{
+		filled:    big.NewRat(0),
+		traded:    big.NewRat(0),
+		price:     order.Price,
+		pair:      Pair(order.Pair),
+		tradeType: order.Type,
+	}
+	if len(border.tradeType) == 0 {
+		border.tradeType = tradeType
+	}
+	if treq != nil {
+		if border.pair.IsEmpty() {
+			border.pair = treq.Pair
+		}
+		if border.price == nil {
+			border.price = treq.Price
+		}
+	}
+	if border.pair.IsEmpty() ||
+		(border.tradeType != TradeTypeAsk && border.tradeType != TradeTypeBid) {
+		return nil
+	}
+
+	remain := order.CoinRemain
+	if remain == nil && order.CoinAmount != nil {
+		remain = big.SubRat(order.CoinAmount, order.CoinFilled)
+	}
+	if remain == nil && treq != nil {
+		remain = treq.Amount
+	}
+	if remain == nil {
+		return border
+	}
+
+	if border.tradeType == TradeTypeAsk {
+		border.frozenAsset = border.pair.Coin().String()
+		border.frozen = big.NewRat(remain)
+	} else if border.price != nil {
+		border.frozenAsset = border.pair.Base().String()
+		border.frozen = big.MulRat(remain, border.price)
+	}
+	return border
+}
+
+// closedFillOf return the total matched coin and base amount of closed
+// order.
+func closedFillOf(order *Trade, price *big.Rat) (filled, traded *big.Rat) {
+	switch {
+	case order.CoinFilled != nil:
+		filled = big.NewRat(order.CoinFilled)
+	case order.Status == TradeStatusFilled && order.CoinAmount != nil:
+		filled = big.NewRat(order.CoinAmount)
+	default:
+		filled = big.NewRat(0)
+	}
+
+	switch {
+	case order.BaseFilled != nil && order.BaseFilled.IsGreaterThanZero():
+		traded = big.NewRat(order.BaseFilled)
+	case order.Price != nil:
+		traded = big.MulRat(filled, order.Price)
+	default:
+		traded = big.MulRat(filled, price)
+	}
+	return filled, traded
+}
+
+// diffBalances return the assets which local and server balance differ
+// more than tolerance, ordered by asset name.
+func diffBalances(local, server map[string]*big.Rat, tolerance *big.Rat, isFrozen bool) (
+	discrepancies []BalanceDiscrepancy,
+) {
+	assets := make(map[string]*big.Rat, len(local)+len(server))
+	for asset := range local {
+		assets[asset] = nil
+	}
+	for asset := range server {
+		assets[asset] = nil
+	}
+
+	for _, asset := range sortedAssets(assets) {
+		localValue := big.NewRat(local[asset])
+		serverValue := big.NewRat(server[asset])
+		diff := big.SubRat(localValue, serverValue)
+		if diff.IsLessThanZero() {
+			diff = big.SubRat(serverValue, localValue)
+		}
+		if !diff.IsGreater(tolerance) {
+			continue
+		}
+		discrepancies = append(discrepancies, BalanceDiscrepancy{
+			Local:    localValue,
+			Server:   serverValue,
+			Asset:    asset,
+			IsFrozen: isFrozen,
+		})
+	}
+	return discrepancies
+}
+
+// recordBalance update the balance tracker, if its set, from the response
+// of order.
+func recordBalance(bt *BalanceTracker, api string, treq *TradeRequest, tres *TradeResponse) {
+	if bt == nil {
+		return
+	}
+	tradeType := TradeTypeAsk
+	if api == APITradeBid || api == APITradeCancelBid {
+		tradeType = TradeTypeBid
+	}
+	bt.HandleTradeResponse(tradeType, treq, tres)
+}
+
+// recordBalanceBulk update the balance tracker, if its set, from the
+// orders and cancel items in TradeBulk.
+func recordBalanceBulk(bt *BalanceTracker, tbReq, tbRes *TradeBulk) {
+	if bt == nil || tbRes == nil {
+		return
+	}
+	for _, itemResult := range mapBulkItems(tbReq.Orders, tbRes.Orders, nil) {
+		if itemResult.Err != nil {
+			continue
+		}
+		treq := itemResult.Request.TradeRequest
+		if treq.Pair.IsEmpty() {
+			treq.Pair = tbReq.Pair
+		}
+		order := &Trade{
+			Price:  treq.Price,
+			Pair:   treq.Pair.String(),
+			Type:   treq.Type,
+			Method: treq.Method,
+			ID:     itemResult.ID,
+		}
+		bt.HandleTradeResponse(treq.Type, &treq, &TradeResponse{Order: order})
+	}
+
+	var cancelled []Trade
+	for _, itemResult := range mapBulkItems(tbReq.Cancel, tbRes.Cancel, nil) {
+		if itemResult.Err == nil {
+			cancelled = append(cancelled, Trade{ID: itemResult.Request.ID})
+		}
+	}
+	recordBalanceCancelled(bt, cancelled)
+}
+
+// recordBalanceCancelled release the cancelled orders, for example from
+// TradeCancelAll, from the balance tracker, if its set.
+func recordBalanceCancelled(bt *BalanceTracker, orders []Trade) {
+	if bt == nil {
+		return
+	}
+	for _, order := range orders {
+		order := order
+		if len(order.Status) == 0 {
+			order.Status = TradeStatusCancelled
+		}
+		bt.HandleTradeResponse(order.Type, nil, &TradeResponse{Order: &order})
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestBalanceTracker(t *testing.T) {
+	userInfo := `{"data":{"balances":{"idk":"1000","ten":"10"},"frozen_balances":{"ten":"2"}}}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APIUserInfo:
+			_, _ = w.Write([]byte(userInfo))
+		case APIUserOrdersOpen:
+			_, _ = w.Write([]byte(`{"data":{"ten_idk":{"asks":[{"id":1,"price":"110","coin_remain":"2"}],"bids":[]}}}`))
+		case APITradeBid:
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":2,"price":"100",` +
+				`"coin_filled":"1","base_filled":"100","coin_remain":"2"}}}`))
+		case APITradeAsk:
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":3,"price":"120","coin_remain":"1"},` +
+				`"user":{"balances":{"idk":"1500","ten":"5"},"frozen_balances":{"ten":"1"}}}}`))
+		case APITradeCancelAsk:
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":3,"status":"cancelled"}}}`))
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []BalanceDiscrepancy
+	cl.Balances, err = NewBalanceTracker(BalanceTrackerOptions{
+		Client: cl,
+		HandleDiscrepancy: func(discrepancies []BalanceDiscrepancy) {
+			events = append(events, discrepancies...)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cl.Balances.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Balances.Stop()
+
+	assertBalances := func(desc, idk, ten, frozenIdk, frozenTen string) {
+		assets := cl.Balances.Assets()
+		test.Assert(t, desc+": idk", idk, assets.Balances["idk"].String())
+		test.Assert(t, desc+": ten", ten, assets.Balances["ten"].String())
+		test.Assert(t, desc+": frozen idk", frozenIdk, big.NewRat(assets.FrozenBalances["idk"]).String())
+		test.Assert(t, desc+": frozen ten", frozenTen, big.NewRat(assets.FrozenBalances["ten"]).String())
+	}
+
+	assertBalances("start", "1000", "10", "0", "2")
+
+	_, err = cl.TradeBid(&TradeRequest{
+		Amount: big.NewRat(3),
+		Price:  big.NewRat(100),
+		Pair:   "ten_idk",
+		Method: TradeMethodLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("bid partially filled", "900", "11", "200", "2")
+	test.Assert(t, "available idk", "700", cl.Balances.Available("idk").String())
+
+	cl.Balances.HandleOrdersClosed(&Trade{
+		CoinFilled: big.NewRat(3),
+		BaseFilled: big.NewRat(300),
+		Status:     TradeStatusFilled,
+		ID:         2,
+	})
+	assertBalances("bid filled", "700", "13", "0", "2")
+
+	cl.Balances.HandleOrdersClosed(&Trade{
+		Status: TradeStatusCancelled,
+		ID:     1,
+	})
+	assertBalances("ask cancelled", "700", "13", "0", "0")
+
+	// The server charge fee on the bid.
+	userInfo = `{"data":{"balances":{"idk":"699.5","ten":"13"}}}`
+	discrepancies, err := cl.Balances.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []BalanceDiscrepancy{{
+		Local:  big.NewRat(700),
+		Server: big.NewRat("699.5"),
+		Asset:  "idk",
+	}}
+	test.Assert(t, "discrepancies", exp, discrepancies)
+	test.Assert(t, "events", exp, events)
+	assertBalances("reconciled", "699.5", "13", "0", "0")
+
+	_, err = cl.TradeAsk(&TradeRequest{
+		Amount: big.NewRat(1),
+		Price:  big.NewRat(120),
+		Pair:   "ten_idk",
+		Method: TradeMethodLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("ask with user", "1500", "5", "0", "1")
+
+	_, err = cl.TradeCancelAsk("ten_idk", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("ask cancelled by client", "1500", "5", "0", "0")
+}
+
+func TestBalanceTracker_TradeBulk(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+
+		isEarlyClosed   bool
+		isUpdatedOnOpen bool
+	)
+
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		switch req.URL.Path {
+		case APIUserInfo:
+			ex.write(w, http.StatusOK, &UserAssets{
+				Balances: map[string]*big.Rat{
+					"idk": big.NewRat(800),
+					"ten": big.NewRat(12),
+				},
+			})
+			return true
+
+		case APIUserOrdersOpen:
+			ex.locker.Lock()
+			isUpdated := isUpdatedOnOpen
+			isUpdatedOnOpen = false
+			ex.locker.Unlock()
+			if isUpdated {
+				// The order closed while fetching the server.
+				cl.Balances.HandleOrdersClosed(&Trade{ID: 99})
+			}
+			return false
+
+		case APITradeBulk:
+			ex.locker.Lock()
+			if isEarlyClosed {
+				ex.locker.Unlock()
+				return false
+			}
+			isEarlyClosed = true
+			ex.locker.Unlock()
+
+			rec := httptest.NewRecorder()
+			ex.serveBulk(rec, req)
+
+			// The bid is closed before the response received.
+			cl.Balances.HandleOrdersClosed(ex.close(1, TradeStatusFilled))
+
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(rec.Body.Bytes())
+			return true
+		}
+		return false
+	}
+
+	var err error
+	cl.Balances, err = NewBalanceTracker(BalanceTrackerOptions{
+		Client: cl,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cl.Balances.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cl.Balances.Stop()
+
+	assertBalances := func(desc, idk, ten, frozenIdk, frozenTen string) {
+		assets := cl.Balances.Assets()
+		test.Assert(t, desc+": idk", idk, big.NewRat(assets.Balances["idk"]).String())
+		test.Assert(t, desc+": ten", ten, big.NewRat(assets.Balances["ten"]).String())
+		test.Assert(t, desc+": frozen idk", frozenIdk, big.NewRat(assets.FrozenBalances["idk"]).String())
+		test.Assert(t, desc+": frozen ten", frozenTen, big.NewRat(assets.FrozenBalances["ten"]).String())
+	}
+
+	newItem := func(tradeType string, amount, price int64) *BulkOrderItem {
+		return &BulkOrderItem{
+			TradeRequest: TradeRequest{
+				Amount: big.NewRat(amount),
+				Price:  big.NewRat(price),
+				Type:   tradeType,
+				Method: TradeMethodLimit,
+			},
+		}
+	}
+
+	// Start from the server state after the bid below is filled.
+	cl.Balances.assets.Balances["idk"] = big.NewRat(1000)
+	cl.Balances.assets.Balances["ten"] = big.NewRat(10)
+
+	_, err = cl.TradeBulk(&TradeBulk{
+		Pair: "ten_idk",
+		Orders: []*BulkOrderItem{
+			newItem(TradeTypeBid, 2, 100),
+			newItem(TradeTypeAsk, 1, 120),
+			newItem(TradeTypeAsk, 1, 130),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("bid filled early", "800", "12", "0", "2")
+
+	_, err = cl.TradeBulk(&TradeBulk{
+		Pair: "ten_idk",
+		Cancel: []*BulkOrderItem{{
+			TradeRequest: TradeRequest{Type: TradeTypeAsk},
+			ID:           2,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("ask cancelled by bulk", "800", "12", "0", "1")
+
+	_, err = cl.TradeCancelAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertBalances("all cancelled", "800", "12", "0", "0")
+
+	ex.locker.Lock()
+	isUpdatedOnOpen = true
+	ex.locker.Unlock()
+
+	nfetch := ex.count(APIUserInfo)
+	discrepancies, err := cl.Balances.Reconcile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "fetched again", nfetch+2, ex.count(APIUserInfo))
+	test.Assert(t, "discrepancies", 0, len(discrepancies))
+}
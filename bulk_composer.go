@@ -0,0 +1,260 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultBulkSize define the default maximum number of orders and cancel
+// items in single TradeBulk request.
+const DefaultBulkSize = 20
+
+// BulkComposer collect the orders and cancellations on multiple pairs and
+// compose them into one or more TradeBulk requests, grouped by pair and
+// split by the maximum size of bulk request.
+//
+// Use Client.TradeBulkBatch to send the composed requests and map the
+// results back to each item.
+type BulkComposer struct {
+	pairs  []Pair
+	orders map[Pair][]*BulkOrderItem
+	cancel map[Pair][]*BulkOrderItem
+
+	// MaxSize define the maximum number of orders and cancel items in
+	// single TradeBulk request.
+	// Default to DefaultBulkSize.
+	MaxSize int
+
+	lastRefID int64
+}
+
+// BulkItemResult contains the result of single order or cancel item.
+type BulkItemResult struct {
+	// Err contains the error from server, usually as *liberrors.E, or
+	// nil if the item success.
+	Err error
+
+	// Request is the item as passed to AddOrder or AddCancel.
+	Request *BulkOrderItem
+
+	// ID contains the ID of created or cancelled order.
+	ID int64
+}
+
+// BulkResult contains the results of all items in BulkComposer.
+// The results are grouped by pair, in the same order as returned by
+// BulkComposer.Compose.
+type BulkResult struct {
+	Orders []*BulkItemResult
+	Cancel []*BulkItemResult
+}
+
+// NewBulkComposer create new composer with specific maximum size per bulk
+// request.
+// If maxSize is less or equal to zero, it will set to DefaultBulkSize.
+func NewBulkComposer(maxSize int) (bc *BulkComposer) {
+	if maxSize <= 0 {
+		maxSize = DefaultBulkSize
+	}
+	bc = &BulkComposer{
+		orders:  make(map[Pair][]*BulkOrderItem),
+		cancel:  make(map[Pair][]*BulkOrderItem),
+		MaxSize: maxSize,
+	}
+	return bc
+}
+
+// AddOrder add new order into composer.
+// If the item RefID is zero, it will be set to unique number in composer,
+// otherwise it must be unique among all items in composer.
+// It return the RefID of item.
+func (bc *BulkComposer) AddOrder(item *BulkOrderItem) (refID int64) {
+	bc.setRefID(item)
+	bc.addPair(item.Pair)
+	bc.orders[item.Pair] = append(bc.orders[item.Pair], item)
+	return item.RefID
+}
+
+// AddTradeRequest add new order from TradeRequest into composer.
+// It return the RefID of created item.
+func (bc *BulkComposer) AddTradeRequest(treq *TradeRequest) (refID int64) {
+	item := &BulkOrderItem{
+		TradeRequest: *treq,
+	}
+	return bc.AddOrder(item)
+}
+
+// AddCancel add the open order to be cancelled.
+// It return the RefID of created cancel item.
+func (bc *BulkComposer) AddCancel(trade *Trade) (refID int64) {
+	item := &BulkOrderItem{
+		TradeRequest: TradeRequest{
+			Type: trade.Type,
+			Pair: Pair(trade.Pair),
+		},
+		ID: trade.ID,
+	}
+	bc.setRefID(item)
+	bc.addPair(item.Pair)
+	bc.cancel[item.Pair] = append(bc.cancel[item.Pair], item)
+	return item.RefID
+}
+
+// Compose the items into list of TradeBulk requests.
+// Each request contains items on single pair, with total orders and cancel
+// items is not more than MaxSize.
+// The pairs are ordered by the time they are first added.
+func (bc *BulkComposer) Compose() (tbReqs []*TradeBulk) {
+	maxSize := bc.MaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultBulkSize
+	}
+
+	for _, pair := range bc.pairs {
+		var (
+			orders = bc.orders[pair]
+			cancel = bc.cancel[pair]
+		)
+		for len(orders) > 0 || len(cancel) > 0 {
+			tbReq := &TradeBulk{
+				Pair: pair,
+			}
+
+			n := len(orders)
+			if n > maxSize {
+				n = maxSize
+			}
+			tbReq.Orders = orders[:n]
+			orders = orders[n:]
+
+			n = len(cancel)
+			if n > maxSize-len(tbReq.Orders) {
+				n = maxSize - len(tbReq.Orders)
+			}
+			tbReq.Cancel = cancel[:n]
+			cancel = cancel[n:]
+
+			tbReqs = append(tbReqs, tbReq)
+		}
+	}
+	return tbReqs
+}
+
+// Len return the total number of orders and cancel items in composer.
+func (bc *BulkComposer) Len() (n int) {
+	for _, items := range bc.orders {
+		n += len(items)
+	}
+	for _, items := range bc.cancel {
+		n += len(items)
+	}
+	return n
+}
+
+func (bc *BulkComposer) addPair(pair Pair) {
+	_, hasOrders := bc.orders[pair]
+	_, hasCancel := bc.cancel[pair]
+	if !hasOrders && !hasCancel {
+		bc.pairs = append(bc.pairs, pair)
+	}
+}
+
+func (bc *BulkComposer) setRefID(item *BulkOrderItem) {
+	if item.RefID == 0 {
+		bc.lastRefID++
+		item.RefID = bc.lastRefID
+	} else if item.RefID > bc.lastRefID {
+		bc.lastRefID = item.RefID
+	}
+}
+
+// TradeBulkBatch send all items in composer using one or more TradeBulk
+// requests and map the response of each item back to its request.
+//
+// If one of the TradeBulk request failed, all items in that request will
+// have the same error and the returned error will be non-nil, but the
+// rest of requests are still processed.
+func (cl *Client) TradeBulkBatch(bc *BulkComposer) (result *BulkResult, err error) {
+	var (
+		tbReqs = bc.Compose()
+		errs   []string
+	)
+
+	result = &BulkResult{}
+
+	for _, tbReq := range tbReqs {
+		tbRes, errReq := cl.TradeBulk(tbReq)
+		if errReq != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", tbReq.Pair, errReq))
+		}
+		var resOrders, resCancel []*BulkOrderItem
+		if tbRes != nil {
+			resOrders = tbRes.Orders
+			resCancel = tbRes.Cancel
+		}
+		result.Orders = append(result.Orders,
+			mapBulkItems(tbReq.Orders, resOrders, errReq)...)
+		result.Cancel = append(result.Cancel,
+			mapBulkItems(tbReq.Cancel, resCancel, errReq)...)
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("TradeBulkBatch: %s", strings.Join(errs, "; "))
+	}
+	return result, nil
+}
+
+// Failed return the list of orders and cancel items that failed.
+func (result *BulkResult) Failed() (failed []*BulkItemResult) {
+	for _, list := range [][]*BulkItemResult{result.Orders, result.Cancel} {
+		for _, itemResult := range list {
+			if itemResult.Err != nil {
+				failed = append(failed, itemResult)
+			}
+		}
+	}
+	return failed
+}
+
+// mapBulkItems map each request item with its response by RefID, or by its
+// position if the response does not contains RefID.
+func mapBulkItems(reqs, resItems []*BulkOrderItem, errReq error) (
+	results []*BulkItemResult,
+) {
+	byRefID := make(map[int64]*BulkOrderItem, len(resItems))
+	for _, resItem := range resItems {
+		if resItem != nil && resItem.RefID != 0 {
+			byRefID[resItem.RefID] = resItem
+		}
+	}
+
+	for x, req := range reqs {
+		itemResult := &BulkItemResult{
+			Request: req,
+		}
+		results = append(results, itemResult)
+
+		if errReq != nil {
+			itemResult.Err = errReq
+			continue
+		}
+
+		resItem := byRefID[req.RefID]
+		if resItem == nil && x < len(resItems) && resItems[x] != nil &&
+			resItems[x].RefID == 0 {
+			resItem = resItems[x]
+		}
+		if resItem == nil {
+			itemResult.Err = fmt.Errorf("missing response for ref_id %d", req.RefID)
+			continue
+		}
+
+		itemResult.ID = resItem.ID
+		itemResult.Err = resItem.Err()
+	}
+	return results
+}
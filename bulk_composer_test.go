@@ -0,0 +1,80 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestBulkComposer_Compose(t *testing.T) {
+	treqs, err := OrderLadder{
+		Pair:       PairBitcoinIdk,
+		Type:       TradeTypeAsk,
+		StartPrice: big.NewRat(100),
+		PriceStep:  big.NewRat(10),
+		Amount:     big.NewRat(1),
+		Levels:     5,
+	}.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "last price", "140", treqs[4].Price.String())
+
+	bc := NewBulkComposer(2)
+	for _, treq := range treqs {
+		bc.AddTradeRequest(treq)
+	}
+	bc.AddCancel(&Trade{Pair: PairTokenomyIdk, Type: TradeTypeBid, ID: 1})
+	bc.AddCancel(&Trade{Pair: PairBitcoinIdk, Type: TradeTypeAsk, ID: 2})
+
+	tbReqs := bc.Compose()
+
+	type size struct {
+		Pair   Pair
+		Orders int
+		Cancel int
+	}
+	var got []size
+	for _, tbReq := range tbReqs {
+		got = append(got, size{tbReq.Pair, len(tbReq.Orders), len(tbReq.Cancel)})
+	}
+	exp := []size{
+		{PairBitcoinIdk, 2, 0},
+		{PairBitcoinIdk, 2, 0},
+		{PairBitcoinIdk, 1, 1},
+		{PairTokenomyIdk, 0, 1},
+	}
+	test.Assert(t, "Compose", exp, got)
+}
+
+func TestClient_TradeBulkBatch(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	bc := NewBulkComposer(1)
+	for _, price := range []string{"1", "2", "3"} {
+		item, err := NewOrderBuilder(PairTokenomyIdk).Bid().Limit(price).Amount(10).BuildBulkItem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bc.AddOrder(item)
+	}
+
+	result, err := cl.TradeBulkBatch(bc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "len(Orders)", 3, len(result.Orders))
+	test.Assert(t, "len(Failed)", 0, len(result.Failed()))
+	test.Assert(t, "requests", 3, ex.count(APITradeBulk))
+	for x, itemResult := range result.Orders {
+		test.Assert(t, "RefID", int64(x+1), itemResult.Request.RefID)
+		if itemResult.ID <= 0 {
+			t.Fatalf("order %d: want ID, got %d", x, itemResult.ID)
+		}
+	}
+}
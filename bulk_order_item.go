@@ -4,7 +4,11 @@
 
 package tokenomy
 
-import liberrors "github.com/shuLhan/share/lib/errors"
+import (
+	"net/http"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+)
 
 // BulkOrderItem represent single order in bulk trading.
 type BulkOrderItem struct {
@@ -14,3 +18,13 @@ type BulkOrderItem struct {
 	ID    int64 `json:"id,omitempty"`
 	RefID int64 `json:"ref_id,omitempty"`
 }
+
+// Err return the error of item from bulk trading response, or nil if the
+// item Code is 200 or not set.
+func (item *BulkOrderItem) Err() error {
+	if item.Code == 0 || item.Code == http.StatusOK {
+		return nil
+	}
+	e := item.E
+	return &e
+}
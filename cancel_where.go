@@ -0,0 +1,98 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"time"
+)
+
+// CancelFailure contains the open order that can not be cancelled and its
+// error.
+type CancelFailure struct {
+	Err   error
+	Trade *Trade
+}
+
+// CancelReport contains the result of CancelWhere.
+type CancelReport struct {
+	// Cancelled contains list of orders that has been cancelled.
+	Cancelled []*Trade
+
+	// Failed contains list of orders that can not be cancelled.
+	Failed []*CancelFailure
+}
+
+// CancelWhere cancel all open orders that match the filter.
+//
+// The open orders are fetched using UserOrdersOpen, for each pair in
+// filter.Pairs or for all pairs if its empty, and cancelled using one or
+// more TradeBulk requests per pair.
+//
+// If one of the TradeBulk request failed, the returned error is non-nil
+// but the report still contains the result of all orders.
+func (cl *Client) CancelWhere(filter *OrderFilter) (
+	report *CancelReport, err error,
+) {
+	logp := "CancelWhere"
+
+	if filter == nil {
+		filter = &OrderFilter{}
+	}
+
+	var (
+		pairNames = []string{""}
+		trades    []Trade
+	)
+	if len(filter.Pairs) > 0 {
+		pairNames = pairNames[:0]
+		for _, pair := range filter.Pairs {
+			pairNames = append(pairNames, pair.String())
+		}
+	}
+	for _, pairName := range pairNames {
+		pto, err := cl.UserOrdersOpen(pairName)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+		trades = append(trades, pto.Trades()...)
+	}
+
+	var (
+		now     = time.Now()
+		bc      = NewBulkComposer(0)
+		byRefID = make(map[int64]*Trade)
+	)
+	for x := range trades {
+		trade := &trades[x]
+		if !filter.IsMatch(trade, now) {
+			continue
+		}
+		refID := bc.AddCancel(trade)
+		byRefID[refID] = trade
+	}
+
+	report = &CancelReport{}
+	if bc.Len() == 0 {
+		return report, nil
+	}
+
+	result, errBatch := cl.TradeBulkBatch(bc)
+	for _, itemResult := range result.Cancel {
+		trade := byRefID[itemResult.Request.RefID]
+		if itemResult.Err != nil {
+			report.Failed = append(report.Failed, &CancelFailure{
+				Err:   itemResult.Err,
+				Trade: trade,
+			})
+			continue
+		}
+		report.Cancelled = append(report.Cancelled, trade)
+	}
+	if errBatch != nil {
+		return report, fmt.Errorf("%s: %w", logp, errBatch)
+	}
+	return report, nil
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_CancelWhere(t *testing.T) {
+	var (
+		now = time.Now().Unix()
+		old = now - 3600
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APIUserOrdersOpen:
+			fmt.Fprintf(w, `{"data":{"btc_idk":{`+
+				`"asks":[{"id":1,"price":"110","submit_time":%d},{"id":2,"price":"130","submit_time":%d}],`+
+				`"bids":[{"id":3,"price":"90","submit_time":%d},{"id":4,"price":"80","submit_time":%d}]}}}`,
+				old, old, old, now)
+		case APITradeBulk:
+			var tbReq TradeBulk
+			err := json.NewDecoder(req.Body).Decode(&tbReq)
+			if err != nil {
+				t.Error(err)
+			}
+			tbRes := &TradeBulk{}
+			for _, item := range tbReq.Cancel {
+				resItem := &BulkOrderItem{ID: item.ID, RefID: item.RefID}
+				resItem.Code = http.StatusOK
+				if item.ID == 3 {
+					resItem.Code = http.StatusNotFound
+					resItem.Message = "order not found"
+				}
+				tbRes.Cancel = append(tbRes.Cancel, resItem)
+			}
+			_ = json.NewEncoder(w).Encode(&Response{Data: tbRes})
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := cl.CancelWhere(&OrderFilter{
+		Pairs:     []Pair{PairBitcoinIdk},
+		MaxPrice:  big.NewRat(120),
+		OlderThan: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cancelled []int64
+	for _, trade := range report.Cancelled {
+		cancelled = append(cancelled, trade.ID)
+	}
+	sort.Slice(cancelled, func(x, y int) bool {
+		return cancelled[x] < cancelled[y]
+	})
+	test.Assert(t, "Cancelled", []int64{1}, cancelled)
+	test.Assert(t, "Failed", 1, len(report.Failed))
+	test.Assert(t, "Failed.ID", int64(3), report.Failed[0].Trade.ID)
+
+	// The server does not set the type on open orders, so the Type is
+	// taken from the asks and bids list.
+	report, err = cl.CancelWhere(&OrderFilter{
+		Pairs:     []Pair{PairBitcoinIdk},
+		Type:      TradeTypeAsk,
+		OlderThan: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled = nil
+	for _, trade := range report.Cancelled {
+		test.Assert(t, "Type", TradeTypeAsk, trade.Type)
+		cancelled = append(cancelled, trade.ID)
+	}
+	sort.Slice(cancelled, func(x, y int) bool {
+		return cancelled[x] < cancelled[y]
+	})
+	test.Assert(t, "Cancelled by Type", []int64{1, 2}, cancelled)
+	test.Assert(t, "Failed by Type", 0, len(report.Failed))
+}
@@ -0,0 +1,367 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrCassetteNoMatch define an error when replaying request that does not
+// have any recorded interaction.
+var ErrCassetteNoMatch = errors.New("no matching interaction in cassette")
+
+// CassetteRequest contains the recorded request.
+// The Key and Sign headers are never recorded, and the timestamp parameter
+// is removed from Params and Body.
+type CassetteRequest struct {
+	Params url.Values `json:"params,omitempty"`
+	Method string     `json:"method"`
+	Path   string     `json:"path"`
+
+	// Body contains the request body in JSON, for example in
+	// TradeBulk.
+	Body string `json:"body,omitempty"`
+}
+
+// CassetteResponse contains the recorded response.
+type CassetteResponse struct {
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+	Code        int    `json:"code"`
+}
+
+// CassetteInteraction contains single recorded request and its response.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// Cassette is an HTTP transport that record the requests and responses
+// made by Client into file and replay them later, for example in tests.
+//
+// To record the interactions, create the cassette using NewCassetteRecorder,
+// set it to Client using UseCassette, and call Save after all requests has
+// been made.
+//
+// To replay the interactions, load the cassette using LoadCassette and set
+// it to Client using UseCassette.
+// The request is matched with recorded interaction by method, path,
+// parameters, and body, excluding the timestamp parameter.
+// Identical requests are replayed in the same order as they were recorded.
+type Cassette struct {
+	// Transport is the underlying transport used to send the request
+	// to server on recording.
+	// Its default to the clone of Client transport.
+	Transport http.RoundTripper `json:"-"`
+
+	path string
+
+	Interactions []*CassetteInteraction `json:"interactions"`
+
+	// Redact contains list of string that will be replaced with
+	// "[REDACTED]" in recorded request and response.
+	// The Client Token and Secret are added automatically by
+	// UseCassette.
+	Redact []string `json:"-"`
+
+	isUsed []bool
+
+	locker sync.Mutex
+
+	isRecording bool
+}
+
+// LoadCassette load the recorded interactions from file for replaying.
+func LoadCassette(path string) (cas *Cassette, err error) {
+	logp := "LoadCassette"
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	cas = &Cassette{
+		path: path,
+	}
+	err = json.Unmarshal(b, cas)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", logp, path, err)
+	}
+	cas.isUsed = make([]bool, len(cas.Interactions))
+
+	return cas, nil
+}
+
+// NewCassetteRecorder create new cassette for recording the interactions
+// into file.
+func NewCassetteRecorder(path string) (cas *Cassette) {
+	cas = &Cassette{
+		path:        path,
+		isRecording: true,
+	}
+	return cas
+}
+
+// UseCassette set the client to record or replay the HTTP requests using
+// cassette.
+// The client HTTP client is replaced with its clone that use the dedicated
+// transport, so the original transport is not changed.
+func (cl *Client) UseCassette(cas *Cassette) (err error) {
+	logp := "UseCassette"
+
+	if cl.transport == nil {
+		trans, ok := cl.Client.Client.Transport.(*http.Transport)
+		if !ok {
+			return fmt.Errorf("%s: unknown transport %T", logp,
+				cl.Client.Client.Transport)
+		}
+		cl.transport = trans
+	}
+
+	token, secret := cl.env.credential()
+
+	cas.locker.Lock()
+	if cas.Transport == nil {
+		cas.Transport = cl.transport.Clone()
+	}
+	if len(token) > 0 {
+		cas.Redact = append(cas.Redact, token)
+	}
+	if !secret.IsEmpty() {
+		cas.Redact = append(cas.Redact, string(secret))
+	}
+	cas.locker.Unlock()
+
+	// The libhttp.Client require the transport to be *http.Transport, so
+	// instead of setting the cassette as the transport we register it
+	// as the handler for all requests on the new clone of the original
+	// transport.
+	trans := cl.transport.Clone()
+	trans.RegisterProtocol("http", cas)
+	trans.RegisterProtocol("https", cas)
+
+	httpClient := *cl.Client.Client
+	httpClient.Transport = trans
+
+	libClient := *cl.Client
+	libClient.Client = &httpClient
+	cl.Client = &libClient
+
+	return nil
+}
+
+// RoundTrip implement the http.RoundTripper interface.
+func (cas *Cassette) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	creq, err := cas.newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cas.isRecording {
+		return cas.record(req, creq)
+	}
+	return cas.replay(req, creq)
+}
+
+// Save the recorded interactions into file.
+func (cas *Cassette) Save() (err error) {
+	cas.locker.Lock()
+	defer cas.locker.Unlock()
+
+	b, err := json.MarshalIndent(cas, "", "\t")
+	if err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+
+	err = os.WriteFile(cas.path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("Save: %w", err)
+	}
+	return nil
+}
+
+func (cas *Cassette) record(req *http.Request, creq *CassetteRequest) (
+	res *http.Response, err error,
+) {
+	res, err = cas.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	interaction := &CassetteInteraction{
+		Request: *creq,
+		Response: CassetteResponse{
+			ContentType: res.Header.Get("Content-Type"),
+			Body:        cas.redact(string(body)),
+			Code:        res.StatusCode,
+		},
+	}
+
+	cas.locker.Lock()
+	cas.Interactions = append(cas.Interactions, interaction)
+	cas.isUsed = append(cas.isUsed, true)
+	cas.locker.Unlock()
+
+	return res, nil
+}
+
+func (cas *Cassette) replay(req *http.Request, creq *CassetteRequest) (
+	res *http.Response, err error,
+) {
+	cas.locker.Lock()
+	defer cas.locker.Unlock()
+
+	for x, interaction := range cas.Interactions {
+		if cas.isUsed[x] || !interaction.Request.isMatch(creq) {
+			continue
+		}
+		cas.isUsed[x] = true
+
+		res = &http.Response{
+			Status:        http.StatusText(interaction.Response.Code),
+			StatusCode:    interaction.Response.Code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{},
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}
+		if len(interaction.Response.ContentType) > 0 {
+			res.Header.Set("Content-Type", interaction.Response.ContentType)
+		}
+		return res, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s %s", ErrCassetteNoMatch, creq.Method,
+		creq.Path, creq.Params.Encode())
+}
+
+// newRequest create the CassetteRequest from HTTP request.
+// The request body is read and restored back, so it can be send by
+// underlying transport.
+func (cas *Cassette) newRequest(req *http.Request) (
+	creq *CassetteRequest, err error,
+) {
+	creq = &CassetteRequest{
+		Method: req.Method,
+		Path:   req.URL.Path,
+		Params: req.URL.Query(),
+	}
+
+	if req.Body != nil {
+		var body []byte
+
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		switch mediaType {
+		case "application/x-www-form-urlencoded":
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range form {
+				creq.Params[k] = append(creq.Params[k], v...)
+			}
+		case "application/json":
+			creq.Body, err = cassetteJSON(body)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			creq.Body = string(body)
+		}
+	}
+
+	creq.Params.Del(ParamNameTimestamp)
+	if len(creq.Params) == 0 {
+		creq.Params = nil
+	}
+	for _, values := range creq.Params {
+		for x, v := range values {
+			values[x] = cas.redact(v)
+		}
+	}
+	creq.Body = cas.redact(creq.Body)
+
+	return creq, nil
+}
+
+func (cas *Cassette) redact(s string) string {
+	for _, secret := range cas.Redact {
+		if len(secret) == 0 {
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, secretRedacted)
+	}
+	return s
+}
+
+// isMatch return true if both requests have the same method, path,
+// parameters, and body.
+func (creq *CassetteRequest) isMatch(other *CassetteRequest) bool {
+	if creq.Method != other.Method {
+		return false
+	}
+	if creq.Path != other.Path {
+		return false
+	}
+	if creq.Params.Encode() != other.Params.Encode() {
+		return false
+	}
+	return creq.Body == other.Body
+}
+
+// cassetteJSON return the JSON body in canonical form, with the timestamp
+// field removed.
+func cassetteJSON(body []byte) (out string, err error) {
+	var (
+		dec = json.NewDecoder(bytes.NewReader(body))
+		v   interface{}
+	)
+
+	// Keep the numbers as is, without converting them to float64.
+	dec.UseNumber()
+
+	err = dec.Decode(&v)
+	if err != nil {
+		return "", err
+	}
+
+	obj, ok := v.(map[string]interface{})
+	if ok {
+		delete(obj, ParamNameTimestamp)
+	}
+
+	body, err = json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
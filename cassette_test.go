@@ -0,0 +1,117 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestCassette(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APIMarketTicker:
+			_, _ = w.Write([]byte(`{"data":{"pair":"btc_idk","last_price":"100.5"}}`))
+		case APITradeBulk:
+			_, _ = w.Write([]byte(`{"data":{"pair":"btc_idk","orders":[{"id":7,"ref_id":1,"code":200}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":404,"message":"not found"}`))
+		}
+	}))
+
+	var (
+		path = filepath.Join(t.TempDir(), "cassette.json")
+		env  = &Environment{
+			Address: srv.URL,
+			Token:   "t0ken",
+			Secret:  "s3cr3t",
+		}
+		tbReq = &TradeBulk{
+			Pair: PairBitcoinIdk,
+			Orders: []*BulkOrderItem{{
+				TradeRequest: TradeRequest{
+					Type:   TradeTypeBid,
+					Price:  big.NewRat("0.000_000_01"),
+					Amount: big.NewRat(1),
+				},
+				RefID: 1,
+			}},
+		}
+	)
+
+	// Record.
+
+	cl, err := NewClient(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cas := NewCassetteRecorder(path)
+	err = cl.UseCassette(cas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cl.MarketTicker(PairBitcoinIdk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cas.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{env.Token, string(env.Secret), HeaderNameSign} {
+		if strings.Contains(string(b), leak) {
+			t.Fatalf("cassette contains %q: %s", leak, b)
+		}
+	}
+
+	// Replay.
+
+	cas, err = LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The same client can switch to other cassette.
+	err = cl.UseCassette(cas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tick, err := cl.MarketTicker(PairBitcoinIdk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "MarketTicker.LastPrice", "100.5", tick.LastPrice.String())
+
+	tbRes, err := cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "TradeBulk.Orders.ID", int64(7), tbRes.Orders[0].ID)
+
+	_, err = cl.MarketTicker(PairBitcoinIdk)
+	if err == nil {
+		t.Fatal("want error on replaying used interaction")
+	}
+}
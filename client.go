@@ -21,7 +21,42 @@ type Client struct {
 	*libhttp.Client
 
 	User *User
-	env  *Environment
+
+	// Markets, optional, contains the registry of markets.
+	// If its set, the pair on trade requests is validated against it
+	// before sending the request to server.
+	Markets *MarketRegistry
+
+	// ClientOrders, optional, is the store where the ClientOrderID of
+	// placed orders are recorded.
+	ClientOrders *ClientOrderStore
+
+	// GTT, optional, is the scheduler to cancel the orders with
+	// TimeInForce "GTT".
+	// It is required to place the order with TimeInForce "GTT".
+	GTT *GTTScheduler
+
+	// Slippage, optional, protect the market orders on TradeAsk and
+	// TradeBid by converting them into limit orders.
+	Slippage *SlippageGuard
+
+	// Risk, optional, check each order on TradeAsk, TradeBid and
+	// TradeBulk against the pre-trade risk limits.
+	Risk *RiskEngine
+
+	// SelfTrade, optional, prevent the orders on TradeAsk, TradeBid and
+	// TradeBulk from being matched with our own open orders.
+	SelfTrade *SelfTradeGuard
+
+	// Balances, optional, is the local balances that is updated from
+	// the response of TradeAsk, TradeBid, TradeBulk, and cancel.
+	Balances *BalanceTracker
+
+	env *Environment
+
+	// transport is the original HTTP transport, before UseCassette
+	// replace the HTTP client.
+	transport *http.Transport
 }
 
 // NewClient create and initialize new client for REST API v2.
@@ -65,6 +100,13 @@ func (cl *Client) Authenticate() (err error) {
 	return nil
 }
 
+// RotateCredential reload the Token and Secret from the Environment
+// Provider.
+// The next request will be signed using the new credential.
+func (cl *Client) RotateCredential() (err error) {
+	return cl.env.Rotate()
+}
+
 // MarketDepths fetch list of market's depth for specific pair.
 func (cl *Client) MarketDepths(pairName string) (depths *MarketDepths, err error) {
 	params := url.Values{
@@ -102,7 +144,7 @@ func (cl *Client) MarketInfo() (marketInfos []MarketInfo, err error) {
 
 	marketInfos = make([]MarketInfo, 0)
 	res := &Response{
-		Data: marketInfos,
+		Data: &marketInfos,
 	}
 
 	err = json.Unmarshal(resBody, res)
@@ -113,6 +155,18 @@ func (cl *Client) MarketInfo() (marketInfos []MarketInfo, err error) {
 	return marketInfos, nil
 }
 
+// MarketRegistry fetch the market information of all pairs and return it
+// as MarketRegistry.
+// The returned registry can be set to Client.Markets and/or
+// WebSocketPrivate.Markets to validate the pair before trading.
+func (cl *Client) MarketRegistry() (reg *MarketRegistry, err error) {
+	marketInfos, err := cl.MarketInfo()
+	if err != nil {
+		return nil, fmt.Errorf("MarketRegistry: %w", err)
+	}
+	return NewMarketRegistry(marketInfos), nil
+}
+
 // MarketTradesOpen return list of all open trades in the market, specific to
 // pair's name, grouped by ask and bid.
 func (cl *Client) MarketTradesOpen(pairName string) (openTrades *TradesOpen, err error) {
@@ -489,6 +543,13 @@ func (cl *Client) UserWithdraw(
 		ParamNameAmount:      []string{amount.String()},
 	}
 
+	if cl.env.IsDryRun {
+		cl.dryRun(http.MethodPost, APIUserWithdraw, params)
+		withdraw = newDryRunWithdraw(requestID, asset, network,
+			address, addressType, memo, amount)
+		return withdraw, nil
+	}
+
 	b, err := cl.doSecureRequest(http.MethodPost, APIUserWithdraw,
 		params)
 	if err != nil {
@@ -555,6 +616,8 @@ func (cl *Client) TradeBid(treq *TradeRequest) (
 }
 
 // TradeBulk request trade with multiple orders and/or cancellation.
+// The order with TimeInForce IOC or GTT is rejected, since they are
+// emulated by client only on TradeAsk and TradeBid.
 func (cl *Client) TradeBulk(tbReq *TradeBulk) (tbRes *TradeBulk, err error) {
 	var (
 		logp    = "TradeBulk"
@@ -571,6 +634,31 @@ func (cl *Client) TradeBulk(tbReq *TradeBulk) (tbRes *TradeBulk, err error) {
 		return nil, nil
 	}
 
+	err = cl.validatePair(tbReq.Pair)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	for _, item := range tbReq.Orders {
+		err = checkBulkTimeInForce(&item.TradeRequest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+	if cl.Risk != nil {
+		err = cl.Risk.CheckBulk(tbReq)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+
+	// The RefID is set before the guard copy the items, so the caller
+	// can map the response using its own items.
+	setBulkRefIDs(tbReq)
+	tbReq, err = checkSelfTradeBulk(cl.SelfTrade, cl.Risk, tbReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
 	tbReq.Timestamp = timestamp()
 
 	payload, err = json.Marshal(tbReq)
@@ -578,10 +666,21 @@ func (cl *Client) TradeBulk(tbReq *TradeBulk) (tbRes *TradeBulk, err error) {
 		return nil, fmt.Errorf("%s: %w", logp, err)
 	}
 
-	sign = Sign(string(payload), cl.env.Secret)
-	headers.Set(HeaderNameKey, cl.env.Token)
+	token, secret := cl.env.credential()
+	sign = Sign(string(payload), string(secret))
+	headers.Set(HeaderNameKey, token)
 	headers.Set(HeaderNameSign, sign)
 
+	if cl.env.IsDryRun {
+		dryRunLog(http.MethodPost, APITradeBulk, headers, payload)
+		return newDryRunTradeBulk(tbReq), nil
+	}
+
+	err = recordBulkClientOrdersPending(cl.ClientOrders, tbReq)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
 	httpres, resBody, err = cl.PostJSON(APITradeBulk, headers, tbReq)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", logp, err)
@@ -597,22 +696,69 @@ func (cl *Client) TradeBulk(tbReq *TradeBulk) (tbRes *TradeBulk, err error) {
 	}
 
 	if httpres.StatusCode >= 400 {
+		res.Code = httpres.StatusCode
+		rejectBulkClientOrders(cl.ClientOrders, tbReq, &res.E)
 		return nil, fmt.Errorf("%s: %w", logp, res)
 	}
 
+	recordBulkClientOrders(cl.ClientOrders, tbReq, tbRes)
+	recordRiskBulk(cl.Risk, tbReq, tbRes)
+	recordSelfTradeBulk(cl.SelfTrade, tbReq, tbRes)
+	recordBalanceBulk(cl.Balances, tbReq, tbRes)
+
 	return tbRes, nil
 }
 
 func (cl *Client) trade(api string, treq *TradeRequest) (
 	trade *TradeResponse, err error,
 ) {
+	treq, err = cl.protectMarketOrder(api, treq)
+	if err != nil {
+		return nil, err
+	}
+
 	params, _, err := treq.Pack()
 	if err != nil {
 		return nil, err
 	}
 
+	err = cl.validatePair(treq.Pair)
+	if err != nil {
+		return nil, err
+	}
+	err = checkTimeInForce(treq, cl.GTT)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRisk(cl.Risk, api, treq)
+	if err != nil {
+		return nil, err
+	}
+	guarded, err := checkSelfTrade(cl.SelfTrade, cl.Risk, api, treq, cl.env.IsDryRun)
+	if err != nil {
+		return nil, err
+	}
+	if guarded != treq {
+		treq = guarded
+		params, _, err = treq.Pack()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cl.env.IsDryRun {
+		cl.dryRun(http.MethodPost, api, params)
+		return newDryRunTradeResponse(api, treq), nil
+	}
+
+	err = recordClientOrderPending(cl.ClientOrders, api, treq)
+	if err != nil {
+		return nil, err
+	}
+
 	b, err := cl.doSecureRequest(http.MethodPost, api, params)
 	if err != nil {
+		rejectClientOrderPending(cl.ClientOrders, treq, err)
 		return nil, err
 	}
 
@@ -626,6 +772,16 @@ func (cl *Client) trade(api string, treq *TradeRequest) (
 		return nil, err
 	}
 
+	recordClientOrder(cl.ClientOrders, treq, trade.Order)
+	recordRisk(cl.Risk, treq, trade)
+	recordSelfTrade(cl.SelfTrade, api, treq, trade)
+	recordBalance(cl.Balances, api, treq, trade)
+
+	err = applyTimeInForce(cl, cl.GTT, treq, trade)
+	if err != nil {
+		return trade, err
+	}
+
 	return trade, nil
 }
 
@@ -653,6 +809,15 @@ func (cl *Client) TradeCancel(trade *Trade) (*Trade, error) {
 
 // TradeCancelAll cancel all user's open ask and bid orders.
 func (cl *Client) TradeCancelAll() (canceled []Trade, err error) {
+	if cl.env.IsDryRun {
+		cl.dryRun(http.MethodDelete, APITradeCancelAll, nil)
+		pto, err := cl.UserOrdersOpen("")
+		if err != nil {
+			return nil, err
+		}
+		return newDryRunCancelAll(pto), nil
+	}
+
 	b, err := cl.doSecureRequest(http.MethodDelete, APITradeCancelAll, nil)
 	if err != nil {
 		return nil, err
@@ -667,6 +832,12 @@ func (cl *Client) TradeCancelAll() (canceled []Trade, err error) {
 		return nil, err
 	}
 
+	for _, trade := range canceled {
+		forgetRisk(cl.Risk, trade.ID)
+		forgetSelfTrade(cl.SelfTrade, trade.ID)
+	}
+	recordBalanceCancelled(cl.Balances, canceled)
+
 	return canceled, nil
 }
 
@@ -689,6 +860,10 @@ func (cl *Client) cancel(api, pairName string, id int64) (
 ) {
 	params := url.Values{}
 
+	err = cl.validatePair(Pair(pairName))
+	if err != nil {
+		return nil, err
+	}
 	params.Set(ParamNamePair, pairName)
 
 	if id <= 0 {
@@ -696,6 +871,11 @@ func (cl *Client) cancel(api, pairName string, id int64) (
 	}
 	params.Set(ParamNameTradeID, strconv.FormatInt(id, 10))
 
+	if cl.env.IsDryRun {
+		cl.dryRun(http.MethodDelete, api, params)
+		return newDryRunCancelResponse(api, pairName, id), nil
+	}
+
 	b, err := cl.doSecureRequest(http.MethodDelete, api, params)
 	if err != nil {
 		return nil, err
@@ -710,6 +890,9 @@ func (cl *Client) cancel(api, pairName string, id int64) (
 	if err != nil {
 		return nil, err
 	}
+	forgetRisk(cl.Risk, id)
+	forgetSelfTrade(cl.SelfTrade, id)
+	recordBalance(cl.Balances, api, nil, trade)
 
 	return trade, nil
 }
@@ -721,15 +904,7 @@ func (cl *Client) doSecureRequest(httpMethod, path string, params url.Values) (
 		params = url.Values{}
 	}
 
-	params.Set(ParamNameTimestamp, timestampAsString())
-
-	payload := params.Encode()
-	sign := Sign(payload, cl.env.Secret)
-
-	headers := http.Header{
-		HeaderNameKey:  []string{cl.env.Token},
-		HeaderNameSign: []string{sign},
-	}
+	headers := cl.sign(params)
 
 	var httpres *http.Response
 
@@ -760,3 +935,37 @@ func (cl *Client) doSecureRequest(httpMethod, path string, params url.Values) (
 
 	return resBody, nil
 }
+
+// validatePair validate the pair format and, if Markets is set, check that
+// the pair is registered and active.
+func (cl *Client) validatePair(pair Pair) error {
+	if cl.Markets != nil {
+		return cl.Markets.Validate(pair)
+	}
+	return pair.Validate()
+}
+
+// dryRun sign and log the request that would be send to server.
+func (cl *Client) dryRun(httpMethod, path string, params url.Values) {
+	if params == nil {
+		params = url.Values{}
+	}
+	headers := cl.sign(params)
+	dryRunLog(httpMethod, path, headers, []byte(params.Encode()))
+}
+
+// sign set the timestamp parameter and return the HTTP headers that
+// contains the API token and signature of params.
+func (cl *Client) sign(params url.Values) (headers http.Header) {
+	params.Set(ParamNameTimestamp, timestampAsString())
+
+	token, secret := cl.env.credential()
+	payload := params.Encode()
+	sign := Sign(payload, string(secret))
+
+	headers = http.Header{
+		HeaderNameKey:  []string{token},
+		HeaderNameSign: []string{sign},
+	}
+	return headers
+}
@@ -0,0 +1,628 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+)
+
+// ErrClientOrderNotFound define an error when the client order ID is not
+// recorded or its order can not be found on server.
+var ErrClientOrderNotFound = errors.New("client order not found")
+
+// clientOrderTimeMargin define the number of seconds subtracted from the
+// recorded time when searching the order in trade history, to cover the
+// difference between local and server time.
+const clientOrderTimeMargin = 60
+
+// ClientOrderRef contains the mapping between client order ID and the order
+// on server.
+type ClientOrderRef struct {
+	ClientOrderID string `json:"client_order_id"`
+	Pair          Pair   `json:"pair"`
+	Type          string `json:"type"`
+
+	// ID is the order ID on server, or zero if the order is being sent
+	// and its response has not been received.
+	ID int64 `json:"id"`
+
+	CreatedAt int64 `json:"created_at"`
+
+	// ClosedAt contains the time when the order is closed, or zero if
+	// its still open.
+	ClosedAt int64 `json:"closed_at,omitempty"`
+}
+
+// IsPending return true if the order has been sent but its ID is not
+// known, for example when the program stopped before receiving the
+// response.
+func (ref *ClientOrderRef) IsPending() bool {
+	return ref.ID == 0
+}
+
+// clientOrderEntry is single line in the store file.
+type clientOrderEntry struct {
+	ClientOrderRef
+	IsRemoved bool `json:"is_removed,omitempty"`
+}
+
+// ClientOrderStore is a durable map of client order ID to the order ID on
+// server.
+//
+// The server does not accept client order ID on single order, so the
+// mapping is recorded locally and saved into file to survive the restart.
+// The pending mapping is written before the order is sent, and updated
+// with the order ID once the response is received.
+// On TradeBulk, the client order ID is correlated with the response using
+// the RefID.
+//
+// Each change is appended to the file as single line of JSON, and the file
+// is compacted on load and on Prune.
+// The closed orders are kept, so they can be found by FindOrder, until
+// they are removed by Prune.
+type ClientOrderStore struct {
+	refs map[string]*ClientOrderRef
+	ids  map[int64]string
+
+	path string
+
+	locker sync.RWMutex
+}
+
+// NewClientOrderID generate new random client order ID.
+func NewClientOrderID() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return fmt.Sprintf("%x", nextDryRunID())
+	}
+	return hex.EncodeToString(b)
+}
+
+// NewClientOrderStore create new store and load the existing mapping from
+// file path.
+// If the path is empty, the mapping is kept in memory only.
+func NewClientOrderStore(path string) (store *ClientOrderStore, err error) {
+	logp := "NewClientOrderStore"
+
+	store = &ClientOrderStore{
+		refs: make(map[string]*ClientOrderRef),
+		ids:  make(map[int64]string),
+		path: path,
+	}
+	if len(path) == 0 {
+		return store, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	var nline int
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		nline++
+
+		var entry clientOrderEntry
+		err = json.Unmarshal(line, &entry)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: line %d: %w", logp, path,
+				nline, err)
+		}
+		if entry.IsRemoved {
+			store.delete(entry.ClientOrderID)
+			continue
+		}
+		store.set(&entry.ClientOrderRef)
+	}
+
+	if nline > len(store.refs) {
+		err = store.compact()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+	return store, nil
+}
+
+// ClientOrderID return the client order ID of the order ID on server, or
+// empty string if its not recorded.
+func (store *ClientOrderStore) ClientOrderID(id int64) string {
+	store.locker.RLock()
+	defer store.locker.RUnlock()
+	return store.ids[id]
+}
+
+// ClientOrderIDOf return the client order ID of trade.
+// It can be used as OrderFilter.TagOf.
+func (store *ClientOrderStore) ClientOrderIDOf(trade *Trade) string {
+	return store.ClientOrderID(trade.ID)
+}
+
+// Find the trade with client order ID in the list of trades, for example
+// from UserOrdersOpen, UserTrades, or from closed orders broadcast.
+// It will return nil if the trade is not found.
+func (store *ClientOrderStore) Find(clientOrderID string, trades []Trade) *Trade {
+	ref, ok := store.Get(clientOrderID)
+	if !ok || ref.IsPending() {
+		return nil
+	}
+	for x := range trades {
+		if trades[x].ID == ref.ID {
+			return &trades[x]
+		}
+	}
+	return nil
+}
+
+// Get the mapping by client order ID.
+func (store *ClientOrderStore) Get(clientOrderID string) (
+	ref ClientOrderRef, ok bool,
+) {
+	store.locker.RLock()
+	defer store.locker.RUnlock()
+
+	p := store.refs[clientOrderID]
+	if p == nil {
+		return ref, false
+	}
+	return *p, true
+}
+
+// HandleOrdersClosed mark the recorded order as closed.
+// The handler is called by WebSocketPrivate when ClientOrders is set.
+func (store *ClientOrderStore) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	clientOrderID, ok := store.ids[trade.ID]
+	if !ok {
+		return
+	}
+	ref := store.refs[clientOrderID]
+	if ref.ClosedAt != 0 {
+		return
+	}
+	ref.ClosedAt = trade.FinishTime
+	if ref.ClosedAt == 0 {
+		ref.ClosedAt = timestamp()
+	}
+	err := store.append(clientOrderEntry{ClientOrderRef: *ref})
+	if err != nil {
+		log.Printf("ClientOrderStore.HandleOrdersClosed %s: %s",
+			clientOrderID, err)
+	}
+}
+
+// Pending return the orders that has been sent but their response has not
+// been received, ordered by CreatedAt.
+// The pending orders after restart should be searched manually, for
+// example using UserOrdersOpen or UserTrades by pair and time, and
+// recorded or removed.
+func (store *ClientOrderStore) Pending() (refs []ClientOrderRef) {
+	store.locker.RLock()
+	for _, ref := range store.refs {
+		if ref.IsPending() {
+			refs = append(refs, *ref)
+		}
+	}
+	store.locker.RUnlock()
+
+	sort.Slice(refs, func(x, y int) bool {
+		if refs[x].CreatedAt == refs[y].CreatedAt {
+			return refs[x].ClientOrderID < refs[y].ClientOrderID
+		}
+		return refs[x].CreatedAt < refs[y].CreatedAt
+	})
+	return refs
+}
+
+// Prune remove the orders that has been closed before the time t and
+// compact the file.
+// It return the number of removed orders.
+func (store *ClientOrderStore) Prune(t time.Time) (n int, err error) {
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	before := t.Unix()
+	for clientOrderID, ref := range store.refs {
+		if ref.ClosedAt != 0 && ref.ClosedAt < before {
+			store.delete(clientOrderID)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	err = store.compact()
+	if err != nil {
+		return n, fmt.Errorf("Prune: %w", err)
+	}
+	return n, nil
+}
+
+// Record the mapping of client order ID to the placed order and save it
+// to file.
+func (store *ClientOrderStore) Record(clientOrderID string, trade *Trade) (
+	err error,
+) {
+	if len(clientOrderID) == 0 || trade == nil {
+		return nil
+	}
+
+	ref := &ClientOrderRef{
+		ClientOrderID: clientOrderID,
+		Pair:          Pair(trade.Pair),
+		Type:          trade.Type,
+		ID:            trade.ID,
+		CreatedAt:     trade.SubmitTime,
+	}
+	if ref.CreatedAt == 0 {
+		ref.CreatedAt = timestamp()
+	}
+
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	store.set(ref)
+
+	return store.append(clientOrderEntry{ClientOrderRef: *ref})
+}
+
+// RecordPending record the client order ID of the order that is about to
+// be sent.
+// It will return an error if the client order ID has been used by other
+// order, or if the mapping can not be saved, in which case the order
+// should not be sent.
+func (store *ClientOrderStore) RecordPending(clientOrderID string, pair Pair, tradeType string) (
+	err error,
+) {
+	if len(clientOrderID) == 0 {
+		return nil
+	}
+
+	logp := "RecordPending"
+
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	if store.refs[clientOrderID] != nil {
+		return fmt.Errorf("%s: %s: duplicate client order ID", logp,
+			clientOrderID)
+	}
+
+	ref := &ClientOrderRef{
+		ClientOrderID: clientOrderID,
+		Pair:          pair,
+		Type:          tradeType,
+		CreatedAt:     timestamp(),
+	}
+	store.set(ref)
+
+	err = store.append(clientOrderEntry{ClientOrderRef: *ref})
+	if err != nil {
+		store.delete(clientOrderID)
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// Remove the mapping of client order ID and save it to file.
+func (store *ClientOrderStore) Remove(clientOrderID string) (err error) {
+	store.locker.Lock()
+	defer store.locker.Unlock()
+
+	ref := store.refs[clientOrderID]
+	if ref == nil {
+		return nil
+	}
+	store.delete(clientOrderID)
+
+	return store.append(clientOrderEntry{
+		ClientOrderRef: ClientOrderRef{ClientOrderID: clientOrderID},
+		IsRemoved:      true,
+	})
+}
+
+// append the entry as new line in the file.
+// It must be called while holding the lock.
+func (store *ClientOrderStore) append(entry clientOrderEntry) (err error) {
+	if len(store.path) == 0 {
+		return nil
+	}
+
+	logp := "ClientOrderStore.append"
+
+	b, err := json.Marshal(&entry)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	b = append(b, '\n')
+
+	f, err := os.OpenFile(store.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	errClose := f.Close()
+	if err == nil {
+		err = errClose
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// compact rewrite the file with the current mapping only.
+// It must be called while holding the lock.
+func (store *ClientOrderStore) compact() (err error) {
+	if len(store.path) == 0 {
+		return nil
+	}
+
+	logp := "ClientOrderStore.compact"
+
+	clientOrderIDs := make([]string, 0, len(store.refs))
+	for clientOrderID := range store.refs {
+		clientOrderIDs = append(clientOrderIDs, clientOrderID)
+	}
+	sort.Strings(clientOrderIDs)
+
+	var buf bytes.Buffer
+	for _, clientOrderID := range clientOrderIDs {
+		b, err := json.Marshal(&clientOrderEntry{
+			ClientOrderRef: *store.refs[clientOrderID],
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", logp, err)
+		}
+		buf.Write(b)
+		buf.WriteByte('\n')
+	}
+
+	err = writeFileAtomic(store.path, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// delete the mapping from memory.
+// It must be called while holding the lock.
+func (store *ClientOrderStore) delete(clientOrderID string) {
+	ref := store.refs[clientOrderID]
+	if ref == nil {
+		return
+	}
+	delete(store.refs, clientOrderID)
+	if ref.ID != 0 {
+		delete(store.ids, ref.ID)
+	}
+}
+
+// set the mapping in memory.
+// It must be called while holding the lock.
+func (store *ClientOrderStore) set(ref *ClientOrderRef) {
+	store.delete(ref.ClientOrderID)
+	store.refs[ref.ClientOrderID] = ref
+	if ref.ID != 0 {
+		store.ids[ref.ID] = ref.ClientOrderID
+	}
+}
+
+// recordClientOrderPending record the client order ID on the request as
+// pending into store, if both are set.
+func recordClientOrderPending(store *ClientOrderStore, api string, treq *TradeRequest) error {
+	if store == nil || treq == nil {
+		return nil
+	}
+	tradeType := TradeTypeAsk
+	if api == APITradeBid {
+		tradeType = TradeTypeBid
+	}
+	return store.RecordPending(treq.ClientOrderID, treq.Pair, tradeType)
+}
+
+// recordBulkClientOrdersPending record the client order ID of each orders
+// in TradeBulk as pending into store.
+// If one of them failed, the recorded pending orders are removed back.
+func recordBulkClientOrdersPending(store *ClientOrderStore, tbReq *TradeBulk) (err error) {
+	if store == nil {
+		return nil
+	}
+	for x, item := range tbReq.Orders {
+		pair := item.Pair
+		if pair.IsEmpty() {
+			pair = tbReq.Pair
+		}
+		err = store.RecordPending(item.ClientOrderID, pair, item.Type)
+		if err != nil {
+			for _, recorded := range tbReq.Orders[:x] {
+				removeClientOrderPending(store, &recorded.TradeRequest)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// removeClientOrderPending remove the client order ID on the request, if
+// its still pending.
+func removeClientOrderPending(store *ClientOrderStore, treq *TradeRequest) {
+	if store == nil || treq == nil || len(treq.ClientOrderID) == 0 {
+		return
+	}
+	ref, ok := store.Get(treq.ClientOrderID)
+	if !ok || !ref.IsPending() {
+		return
+	}
+	err := store.Remove(treq.ClientOrderID)
+	if err != nil {
+		log.Printf("removeClientOrderPending %s: %s", treq.ClientOrderID, err)
+	}
+}
+
+// rejectClientOrderPending remove the pending client order ID if the
+// server reject the order.
+// If err is not from server, for example the connection is lost, the
+// order may have been placed so the pending mapping is kept.
+func rejectClientOrderPending(store *ClientOrderStore, treq *TradeRequest, err error) {
+	var errServer *liberrors.E
+	if !errors.As(err, &errServer) {
+		return
+	}
+	removeClientOrderPending(store, treq)
+}
+
+// recordClientOrder record the client order ID on the request into store,
+// if both are set.
+// The error is logged since the order has been placed.
+func recordClientOrder(store *ClientOrderStore, treq *TradeRequest, trade *Trade) {
+	if store == nil || treq == nil || len(treq.ClientOrderID) == 0 || trade == nil {
+		return
+	}
+	if len(trade.Pair) == 0 {
+		trade.Pair = treq.Pair.String()
+	}
+	if len(trade.Type) == 0 {
+		trade.Type = treq.Type
+	}
+	err := store.Record(treq.ClientOrderID, trade)
+	if err != nil {
+		log.Printf("recordClientOrder %s: %s", treq.ClientOrderID, err)
+	}
+}
+
+// setBulkRefIDs set the RefID of orders that have ClientOrderID but
+// without RefID, so the response can be correlated with the request.
+func setBulkRefIDs(tbReq *TradeBulk) {
+	var (
+		lastRefID int64
+		isNeeded  bool
+	)
+	for _, item := range tbReq.Orders {
+		if item.RefID > lastRefID {
+			lastRefID = item.RefID
+		}
+		if item.RefID == 0 && len(item.ClientOrderID) > 0 {
+			isNeeded = true
+		}
+	}
+	if !isNeeded {
+		return
+	}
+	for _, item := range tbReq.Orders {
+		if item.RefID == 0 {
+			lastRefID++
+			item.RefID = lastRefID
+		}
+	}
+}
+
+// recordBulkClientOrders record the ClientOrderID of the success orders in
+// TradeBulk into store, and remove the pending one that are rejected.
+func recordBulkClientOrders(store *ClientOrderStore, tbReq, tbRes *TradeBulk) {
+	if store == nil || tbRes == nil {
+		return
+	}
+	results := mapBulkItems(tbReq.Orders, tbRes.Orders, nil)
+	for _, result := range results {
+		if len(result.Request.ClientOrderID) == 0 {
+			continue
+		}
+		if result.Err != nil {
+			removeClientOrderPending(store, &result.Request.TradeRequest)
+			continue
+		}
+		trade := &Trade{
+			Pair:       tbReq.Pair.String(),
+			Type:       result.Request.Type,
+			ID:         result.ID,
+			SubmitTime: tbReq.Timestamp,
+		}
+		recordClientOrder(store, &result.Request.TradeRequest, trade)
+	}
+}
+
+// rejectBulkClientOrders remove the pending client order ID of all orders
+// in TradeBulk if the server reject the request.
+func rejectBulkClientOrders(store *ClientOrderStore, tbReq *TradeBulk, err error) {
+	if store == nil {
+		return
+	}
+	for _, item := range tbReq.Orders {
+		rejectClientOrderPending(store, &item.TradeRequest, err)
+	}
+}
+
+// FindOrder find the order by client order ID in the open orders and then
+// in the trade history.
+// The Client ClientOrders must be set.
+func (cl *Client) FindOrder(clientOrderID string) (trade *Trade, err error) {
+	logp := "FindOrder"
+
+	if cl.ClientOrders == nil {
+		return nil, fmt.Errorf("%s: %w", logp, ErrClientOrderNotFound)
+	}
+	ref, ok := cl.ClientOrders.Get(clientOrderID)
+	if !ok {
+		return nil, fmt.Errorf("%s: %s: %w", logp, clientOrderID,
+			ErrClientOrderNotFound)
+	}
+
+	pairTradesOpen, err := cl.UserOrdersOpen(ref.Pair.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	trade = cl.ClientOrders.Find(clientOrderID, pairTradesOpen.Trades())
+	if trade != nil {
+		return trade, nil
+	}
+
+	trades, err := cl.UserTrades(ListTradeParams{
+		Pair:      ref.Pair.String(),
+		Sort:      SortAscending,
+		TimeAfter: ref.CreatedAt - clientOrderTimeMargin,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	trade = cl.ClientOrders.Find(clientOrderID, trades)
+	if trade != nil {
+		if len(trade.Pair) == 0 {
+			trade.Pair = ref.Pair.String()
+		}
+		return trade, nil
+	}
+	return nil, fmt.Errorf("%s: %s: %w", logp, clientOrderID,
+		ErrClientOrderNotFound)
+}
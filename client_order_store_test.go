@@ -0,0 +1,135 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClientOrderStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "client_orders.json")
+
+	store, err := NewClientOrderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ex, cl := newTestExchange(t)
+	cl.ClientOrders = store
+
+	// The pending mapping must be saved before the order is sent.
+	var isPending bool
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path == APITradeBid {
+			reloaded, err := NewClientOrderStore(path)
+			if err != nil {
+				t.Error(err)
+				return false
+			}
+			ref, _ := reloaded.Get("single")
+			isPending = ref.IsPending()
+		}
+		return false
+	}
+
+	treq, err := NewOrderBuilder(PairBitcoinIdk).
+		Bid().Limit(100).Amount(1).ClientOrderID("single").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tres, err := cl.TradeBid(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "pending before send", true, isPending)
+	ex.handle = nil
+
+	_, err = cl.TradeBid(treq)
+	if err == nil {
+		t.Fatal("want error on duplicate client order ID")
+	}
+
+	// The order rejected by server is removed from pending.
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"code":400,"message":"insufficient balance","name":"ERR_BALANCE"}`))
+		return true
+	}
+	treq.ClientOrderID = "rejected"
+	_, err = cl.TradeBid(treq)
+	if err == nil {
+		t.Fatal("want error on rejected order")
+	}
+	_, ok := store.Get("rejected")
+	test.Assert(t, "rejected is removed", false, ok)
+	ex.handle = nil
+
+	tbReq := &TradeBulk{Pair: PairBitcoinIdk}
+	for _, clientID := range []string{"bulk-1", "", "bulk-3"} {
+		item, err := NewOrderBuilder(PairBitcoinIdk).
+			Ask().Limit(200).Amount(1).BuildBulkItem()
+		if err != nil {
+			t.Fatal(err)
+		}
+		item.ClientOrderID = clientID
+		tbReq.Orders = append(tbReq.Orders, item)
+	}
+	tbRes, err := cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the store from file.
+	store, err = NewClientOrderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, ok := store.Get("single")
+	test.Assert(t, "Get single", true, ok)
+	test.Assert(t, "single ID", tres.Order.ID, ref.ID)
+	test.Assert(t, "single Type", TradeTypeBid, ref.Type)
+
+	test.Assert(t, "ClientOrderID bulk-1", "bulk-1", store.ClientOrderID(tbRes.Orders[0].ID))
+	test.Assert(t, "ClientOrderID no ID", "", store.ClientOrderID(tbRes.Orders[1].ID))
+	test.Assert(t, "ClientOrderID bulk-3", "bulk-3", store.ClientOrderID(tbRes.Orders[2].ID))
+
+	trades := []Trade{{ID: 1}, {ID: tbRes.Orders[2].ID}}
+	test.Assert(t, "Find", &trades[1], store.Find("bulk-3", trades))
+	test.Assert(t, "Pending", 0, len(store.Pending()))
+
+	// The reload compact the file into one line per mapping.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "lines", 3, bytes.Count(b, []byte("\n")))
+
+	closed := ex.close(tres.Order.ID, TradeStatusFilled)
+	closed.FinishTime = time.Now().Add(-time.Hour).Unix()
+	store.HandleOrdersClosed(closed)
+
+	n, err := store.Prune(time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Prune", 1, n)
+
+	store, err = NewClientOrderStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok = store.Get("single")
+	test.Assert(t, "pruned", false, ok)
+	_, ok = store.Get("bulk-1")
+	test.Assert(t, "not pruned", true, ok)
+}
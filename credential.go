@@ -0,0 +1,382 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// List of keyfile parameters.
+const (
+	keyfileKDF       = "pbkdf2-sha512"
+	keyfileIteration = 210000
+	keyfileKeyLen    = 32
+	keyfileSaltLen   = 16
+
+	// keyfileMinIteration and keyfileMaxIteration limit the iteration
+	// read from keyfile, so the crafted file can not weaken the key
+	// derivation or make the decryption run for hours.
+	keyfileMinIteration = 100000
+	keyfileMaxIteration = 10 * keyfileIteration
+)
+
+// List of errors returned by credential providers.
+var (
+	ErrCredentialEmpty      = errors.New("empty token or secret")
+	ErrCredentialPassphrase = errors.New("empty or invalid passphrase")
+	ErrCredentialPermission = errors.New("credential file is accessible by group or others")
+)
+
+// Credential contains the API key pair.
+type Credential struct {
+	// Token is the public part of API key.
+	Token string
+
+	// Secret is the private part of API key.
+	Secret Secret
+}
+
+// credentialFile define the format of credential file, in JSON.
+type credentialFile struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+}
+
+// keyfile define the format of encrypted credential file, in JSON.
+type keyfile struct {
+	KDF   string `json:"kdf"`
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+	Iter  int    `json:"iter"`
+}
+
+// CredentialProvider define an interface to load the API credential from
+// external resources.
+type CredentialProvider interface {
+	Credential() (cred *Credential, err error)
+}
+
+// EnvCredentialProvider load the credential from environment variables
+// TOKENOMY_TOKEN and TOKENOMY_SECRET.
+type EnvCredentialProvider struct{}
+
+// Credential return the credential from environment variables.
+func (EnvCredentialProvider) Credential() (cred *Credential, err error) {
+	cred = &Credential{
+		Token:  os.Getenv(EnvNameToken),
+		Secret: Secret(os.Getenv(EnvNameSecret)),
+	}
+	if len(cred.Token) == 0 || cred.Secret.IsEmpty() {
+		return nil, fmt.Errorf("EnvCredentialProvider: %w", ErrCredentialEmpty)
+	}
+	return cred, nil
+}
+
+// FileCredentialProvider load the credential from plain JSON file with the
+// following format,
+//
+//	{"token": "...", "secret": "..."}
+//
+// The file must be readable only by its owner (for example, mode 0600 or
+// 0400), otherwise it will return ErrCredentialPermission.
+type FileCredentialProvider struct {
+	Path string
+}
+
+// Credential read and return the credential from file.
+func (fcp FileCredentialProvider) Credential() (cred *Credential, err error) {
+	var (
+		logp = "FileCredentialProvider"
+		cf   credentialFile
+		b    []byte
+	)
+
+	b, err = readCredentialFile(fcp.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	err = json.Unmarshal(b, &cf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", logp, fcp.Path, err)
+	}
+
+	cred = &Credential{
+		Token:  cf.Token,
+		Secret: Secret(cf.Secret),
+	}
+	if len(cred.Token) == 0 || cred.Secret.IsEmpty() {
+		return nil, fmt.Errorf("%s: %s: %w", logp, fcp.Path, ErrCredentialEmpty)
+	}
+	return cred, nil
+}
+
+// KeyfileCredentialProvider load the credential from file encrypted by
+// EncryptCredential, unlocked using Passphrase.
+//
+// Like FileCredentialProvider, the file must be readable only by its owner.
+type KeyfileCredentialProvider struct {
+	Path       string
+	Passphrase Secret
+}
+
+// Credential read, decrypt, and return the credential from keyfile.
+func (kcp KeyfileCredentialProvider) Credential() (cred *Credential, err error) {
+	var (
+		logp = "KeyfileCredentialProvider"
+		b    []byte
+	)
+
+	b, err = readCredentialFile(kcp.Path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	cred, err = DecryptCredential(b, kcp.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s: %w", logp, kcp.Path, err)
+	}
+	return cred, nil
+}
+
+// ChainCredentialProvider load the credential from the first provider that
+// return it successfully.
+type ChainCredentialProvider []CredentialProvider
+
+// Credential return the first credential that successfully loaded by one of
+// the providers.
+// If all providers fail, it will return all of their error messages.
+func (chain ChainCredentialProvider) Credential() (cred *Credential, err error) {
+	var errs []string
+
+	for _, provider := range chain {
+		cred, err = provider.Credential()
+		if err == nil {
+			return cred, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("ChainCredentialProvider: %w", ErrCredentialEmpty)
+	}
+	return nil, fmt.Errorf("ChainCredentialProvider: %s", strings.Join(errs, "; "))
+}
+
+// EncryptCredential encrypt the credential using passphrase and return the
+// content of keyfile that can be read by KeyfileCredentialProvider.
+//
+// The key is derived from passphrase using PBKDF2 with HMAC-SHA512, and the
+// credential is encrypted using AES-256-GCM.
+func EncryptCredential(cred *Credential, passphrase Secret) (b []byte, err error) {
+	var (
+		logp = "EncryptCredential"
+		kf   = keyfile{
+			KDF:  keyfileKDF,
+			Iter: keyfileIteration,
+			Salt: make([]byte, keyfileSaltLen),
+		}
+
+		aead  cipher.AEAD
+		plain []byte
+	)
+
+	if cred == nil || len(cred.Token) == 0 || cred.Secret.IsEmpty() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrCredentialEmpty)
+	}
+	if passphrase.IsEmpty() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrCredentialPassphrase)
+	}
+
+	_, err = rand.Read(kf.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	aead, err = newKeyfileCipher(passphrase, kf.Salt, kf.Iter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	kf.Nonce = make([]byte, aead.NonceSize())
+	_, err = rand.Read(kf.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	plain, err = json.Marshal(credentialFile{
+		Token:  cred.Token,
+		Secret: string(cred.Secret),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	kf.Data = aead.Seal(nil, kf.Nonce, plain, nil)
+
+	b, err = json.Marshal(&kf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	return b, nil
+}
+
+// DecryptCredential decrypt the content of keyfile created by
+// EncryptCredential using passphrase.
+func DecryptCredential(b []byte, passphrase Secret) (cred *Credential, err error) {
+	var (
+		logp = "DecryptCredential"
+
+		kf    keyfile
+		cf    credentialFile
+		aead  cipher.AEAD
+		plain []byte
+	)
+
+	err = json.Unmarshal(b, &kf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if kf.KDF != keyfileKDF {
+		return nil, fmt.Errorf("%s: unknown kdf %q", logp, kf.KDF)
+	}
+	if kf.Iter < keyfileMinIteration {
+		return nil, fmt.Errorf("%s: iteration %d is less than %d", logp,
+			kf.Iter, keyfileMinIteration)
+	}
+	if kf.Iter > keyfileMaxIteration {
+		return nil, fmt.Errorf("%s: iteration %d is more than %d", logp,
+			kf.Iter, keyfileMaxIteration)
+	}
+
+	aead, err = newKeyfileCipher(passphrase, kf.Salt, kf.Iter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if len(kf.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%s: invalid nonce size", logp)
+	}
+
+	plain, err = aead.Open(nil, kf.Nonce, kf.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, ErrCredentialPassphrase)
+	}
+
+	err = json.Unmarshal(plain, &cf)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if len(cf.Token) == 0 || len(cf.Secret) == 0 {
+		return nil, fmt.Errorf("%s: %w", logp, ErrCredentialEmpty)
+	}
+
+	cred = &Credential{
+		Token:  cf.Token,
+		Secret: Secret(cf.Secret),
+	}
+	return cred, nil
+}
+
+func newKeyfileCipher(passphrase Secret, salt []byte, iter int) (
+	aead cipher.AEAD, err error,
+) {
+	if iter <= 0 || len(salt) == 0 {
+		return nil, errors.New("invalid kdf parameters")
+	}
+
+	var (
+		key   = pbkdf2SHA512([]byte(passphrase), salt, iter, keyfileKeyLen)
+		block cipher.Block
+	)
+
+	block, err = aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA512 derive key from password and salt using PBKDF2 (RFC 8018)
+// with HMAC-SHA512 as pseudorandom function.
+func pbkdf2SHA512(password, salt []byte, iter, keyLen int) (key []byte) {
+	var (
+		prf     = hmac.New(sha512.New, password)
+		hashLen = prf.Size()
+		nblock  = (keyLen + hashLen - 1) / hashLen
+		buf     = make([]byte, 4)
+
+		u, t  []byte
+		block int
+		n, x  int
+	)
+
+	key = make([]byte, 0, nblock*hashLen)
+
+	for block = 1; block <= nblock; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(buf, uint32(block))
+		prf.Write(buf)
+		u = prf.Sum(nil)
+
+		t = make([]byte, len(u))
+		copy(t, u)
+
+		for n = 1; n < iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for x = range t {
+				t[x] ^= u[x]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
+
+// readCredentialFile read the content of credential file after checking
+// that the file is not accessible by group or others.
+// The permission is checked on the opened file, so the file can not be
+// replaced between the check and the read.
+func readCredentialFile(path string) (b []byte, err error) {
+	var (
+		f  *os.File
+		fi os.FileInfo
+	)
+
+	if len(path) == 0 {
+		return nil, ErrCredentialEmpty
+	}
+
+	f, err = os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err = f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("%s: %w: %s", path, ErrCredentialPermission,
+			fi.Mode().Perm())
+	}
+
+	return io.ReadAll(f)
+}
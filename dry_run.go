@@ -0,0 +1,161 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// dryRunMessage is the message set on synthetic BulkOrderItem.
+const dryRunMessage = "dry-run"
+
+// dryRunLastID contains the last ID generated for synthetic order.
+// It is initialized with current time to minimize collision with orders
+// from previous run.
+var dryRunLastID = time.Now().UnixNano() / int64(time.Microsecond)
+
+// nextDryRunID generate unique ID for synthetic order.
+func nextDryRunID() int64 {
+	return atomic.AddInt64(&dryRunLastID, 1)
+}
+
+// dryRunLog log the request that would be send to server on dry-run mode.
+// The headers parameter may be nil for WebSocket request.
+func dryRunLog(httpMethod, path string, headers http.Header, payload []byte) {
+	if headers == nil {
+		log.Printf("DRY-RUN: %s %s %s", httpMethod, path, payload)
+		return
+	}
+	log.Printf("DRY-RUN: %s %s %s=%s %s=%s %s", httpMethod, path,
+		HeaderNameKey, headers.Get(HeaderNameKey),
+		HeaderNameSign, headers.Get(HeaderNameSign), payload)
+}
+
+// newDryRunTradeResponse create synthetic response for TradeAsk or
+// TradeBid.
+// The order is considered open, without any matched trades.
+func newDryRunTradeResponse(api string, treq *TradeRequest) (
+	tres *TradeResponse,
+) {
+	order := &Trade{
+		Price:      big.NewRat(treq.Price),
+		CoinAmount: big.NewRat(treq.Amount),
+		CoinFilled: big.NewRat(0),
+		CoinRemain: big.NewRat(treq.Amount),
+		Pair:       treq.Pair.String(),
+		Method:     treq.Method,
+		ID:         nextDryRunID(),
+		SubmitTime: timestamp(),
+	}
+	switch api {
+	case APITradeAsk:
+		order.Type = TradeTypeAsk
+	case APITradeBid:
+		order.Type = TradeTypeBid
+	default:
+		order.Type = treq.Type
+	}
+	if treq.Price != nil {
+		order.BaseAmount = big.MulRat(treq.Amount, treq.Price)
+		order.BaseFilled = big.NewRat(0)
+		order.BaseRemain = big.MulRat(treq.Amount, treq.Price)
+	}
+
+	tres = &TradeResponse{
+		Order: order,
+	}
+	return tres
+}
+
+// newDryRunCancelResponse create synthetic response for TradeCancelAsk and
+// TradeCancelBid.
+func newDryRunCancelResponse(api, pairName string, id int64) (
+	tres *TradeResponse,
+) {
+	order := &Trade{
+		Pair:       pairName,
+		Status:     TradeStatusCancelled,
+		ID:         id,
+		FinishTime: timestamp(),
+	}
+	switch api {
+	case APITradeCancelAsk:
+		order.Type = TradeTypeAsk
+	case APITradeCancelBid:
+		order.Type = TradeTypeBid
+	}
+
+	tres = &TradeResponse{
+		Order: order,
+	}
+	return tres
+}
+
+// newDryRunCancelAll create synthetic response for TradeCancelAll from
+// the current open orders.
+func newDryRunCancelAll(pto PairTradesOpen) (trades []Trade) {
+	trades = pto.Trades()
+	for x := range trades {
+		trades[x].Status = TradeStatusCancelled
+		trades[x].FinishTime = timestamp()
+	}
+	return trades
+}
+
+// newDryRunTradeBulk create synthetic response for TradeBulk.
+// Each orders will have new synthetic ID, and each cancel will have the same
+// ID as requested.
+func newDryRunTradeBulk(tbReq *TradeBulk) (tbRes *TradeBulk) {
+	tbRes = &TradeBulk{
+		Pair:      tbReq.Pair,
+		Orders:    make([]*BulkOrderItem, 0, len(tbReq.Orders)),
+		Cancel:    make([]*BulkOrderItem, 0, len(tbReq.Cancel)),
+		Timestamp: tbReq.Timestamp,
+	}
+	for _, req := range tbReq.Orders {
+		item := &BulkOrderItem{
+			ID:    nextDryRunID(),
+			RefID: req.RefID,
+		}
+		item.Code = http.StatusOK
+		item.Message = dryRunMessage
+		tbRes.Orders = append(tbRes.Orders, item)
+	}
+	for _, req := range tbReq.Cancel {
+		item := &BulkOrderItem{
+			ID:    req.ID,
+			RefID: req.RefID,
+		}
+		item.Code = http.StatusOK
+		item.Message = dryRunMessage
+		tbRes.Cancel = append(tbRes.Cancel, item)
+	}
+	return tbRes
+}
+
+// newDryRunWithdraw create synthetic response for UserWithdraw.
+func newDryRunWithdraw(
+	requestID, asset, network, address, addressType, memo string,
+	amount *big.Rat,
+) (withdraw *WithdrawItem) {
+	withdraw = &WithdrawItem{
+		Amount:      big.NewRat(amount),
+		RequestID:   requestID,
+		Asset:       asset,
+		Network:     network,
+		Status:      dryRunMessage,
+		Address:     address,
+		AddressType: addressType,
+		Memo:        memo,
+		ID:          nextDryRunID(),
+		SubmitTime:  timestamp(),
+	}
+	return withdraw
+}
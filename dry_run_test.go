@@ -0,0 +1,94 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_dryRun(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Only the read-only request is allowed.
+		if req.URL.Path == APIUserOrdersOpen {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data":{"btc_idk":{` +
+				`"asks":[{"id":11,"price":"100","coin_amount":"1","coin_remain":"1"}]}}}`))
+			return
+		}
+		t.Errorf("unexpected request on dry-run: %s %s", req.Method, req.URL)
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{
+		Address:  srv.URL,
+		Token:    "t0ken",
+		Secret:   "s3cr3t",
+		IsDryRun: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl.ClientOrders, err = NewClientOrderStore(filepath.Join(t.TempDir(), "orders.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cl.TradeBid(&TradeRequest{Pair: PairBitcoinIdk})
+	test.Assert(t, "TradeBid: invalid amount", ErrInvalidAmount, err)
+
+	tres, err := cl.TradeBid(&TradeRequest{
+		Pair:          PairBitcoinIdk,
+		Amount:        big.NewRat("0.5"),
+		Price:         big.NewRat(100),
+		ClientOrderID: "dry-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Order.Type", TradeTypeBid, tres.Order.Type)
+	test.Assert(t, "Order.BaseAmount", "50", tres.Order.BaseAmount.String())
+	if tres.Order.ID <= 0 {
+		t.Fatalf("want synthetic order ID, got %d", tres.Order.ID)
+	}
+	_, ok := cl.ClientOrders.Get("dry-1")
+	test.Assert(t, "ClientOrders recorded", false, ok)
+
+	order, err := cl.TradeCancel(tres.Order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "TradeCancel.ID", tres.Order.ID, order.ID)
+	test.Assert(t, "TradeCancel.Status", TradeStatusCancelled, order.Status)
+
+	tbRes, err := cl.TradeBulk(&TradeBulk{
+		Pair: PairBitcoinIdk,
+		Orders: []*BulkOrderItem{{
+			RefID: 1,
+		}},
+		Cancel: []*BulkOrderItem{{
+			ID: order.ID,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "TradeBulk.Orders.RefID", int64(1), tbRes.Orders[0].RefID)
+	test.Assert(t, "TradeBulk.Cancel.ID", order.ID, tbRes.Cancel[0].ID)
+
+	cancelled, err := cl.TradeCancelAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "TradeCancelAll", 1, len(cancelled))
+	test.Assert(t, "TradeCancelAll.ID", int64(11), cancelled[0].ID)
+	test.Assert(t, "TradeCancelAll.Type", TradeTypeAsk, cancelled[0].Type)
+	test.Assert(t, "TradeCancelAll.Status", TradeStatusCancelled, cancelled[0].Status)
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"sync"
 )
 
 // Environment contains default and dynamic values that gathered from external
@@ -21,7 +22,13 @@ type Environment struct {
 	Token string
 
 	// Secret, required, is the private part of API key.
-	Secret string
+	// Its value is never printed, see Secret type for more information.
+	Secret Secret
+
+	// Provider, optional, define the source of Token and Secret.
+	// If its set, the Token and Secret can be reloaded at runtime by
+	// calling Rotate.
+	Provider CredentialProvider
 
 	//
 	// Debug define level of logging in our library.
@@ -34,8 +41,27 @@ type Environment struct {
 	// IsInsecure, optional, allow self-signed certificate, should be use
 	// for testing only.
 	IsInsecure bool
+
+	// IsDryRun, optional, if its true the mutating requests (trade,
+	// cancel, and withdraw) are validated, signed, and logged, but not
+	// send to server.
+	// The response is generated locally and it is not recorded into
+	// ClientOrders, GTT, Risk, SelfTrade, or Balances, so the state of
+	// live trading is not changed.
+	// The SelfTrade log the resting orders that would be cancelled
+	// instead of cancelling them.
+	// The TradeCancelAll return the current open orders, fetched from
+	// server, as cancelled.
+	// Its value is set from environment variable "TOKENOMY_DRY_RUN".
+	IsDryRun bool
 }
 
+// credLocker protect the Token and Secret of all Environment from being
+// read while its rotated.
+// Its not part of Environment, so the Environment can be created and
+// copied by value.
+var credLocker sync.RWMutex
+
 // NewEnvironment create and initialize environment.
 //
 // If token and/or secret is empty it will set from environment variables
@@ -44,24 +70,110 @@ func NewEnvironment(token, secret string) (env *Environment) {
 	env = &Environment{
 		Address: os.Getenv(EnvNameAddress),
 		Token:   os.Getenv(EnvNameToken),
-		Secret:  os.Getenv(EnvNameSecret),
+		Secret:  Secret(os.Getenv(EnvNameSecret)),
 	}
 
 	if len(token) > 0 {
 		env.Token = token
 	}
 	if len(secret) > 0 {
-		env.Secret = secret
+		env.Secret = Secret(secret)
+	}
+
+	env.loadOptions()
+
+	if env.Debug >= 1 {
+		fmt.Printf(">>> Environment: %s\n", env)
+	}
+
+	return env
+}
+
+// NewEnvironmentFromProvider create and initialize environment with Token
+// and Secret loaded from provider.
+//
+// Other than credential, the Address and Debug values are set from
+// environment variables TOKENOMY_ADDRESS and TOKENOMY_DEBUG.
+func NewEnvironmentFromProvider(provider CredentialProvider) (
+	env *Environment, err error,
+) {
+	env = &Environment{
+		Address:  os.Getenv(EnvNameAddress),
+		Provider: provider,
+	}
+
+	env.loadOptions()
+
+	err = env.Rotate()
+	if err != nil {
+		return nil, fmt.Errorf("NewEnvironmentFromProvider: %w", err)
+	}
+
+	if env.Debug >= 1 {
+		fmt.Printf(">>> Environment: %s\n", env)
 	}
 
+	return env, nil
+}
+
+// Rotate reload the Token and Secret from Provider.
+//
+// The Client that use this environment will sign the next request using the
+// new credential.
+// The WebSocketPrivate need to reconnect to use the new credential, see
+// WebSocketPrivate.RotateCredential.
+func (env *Environment) Rotate() (err error) {
+	if env.Provider == nil {
+		return fmt.Errorf("Rotate: %w", ErrCredentialEmpty)
+	}
+
+	cred, err := env.Provider.Credential()
+	if err != nil {
+		return fmt.Errorf("Rotate: %w", err)
+	}
+
+	env.SetCredential(cred)
+
+	return nil
+}
+
+// SetCredential replace the Token and Secret with new credential.
+func (env *Environment) SetCredential(cred *Credential) {
+	if cred == nil {
+		return
+	}
+	credLocker.Lock()
+	env.Token = cred.Token
+	env.Secret = cred.Secret
+	credLocker.Unlock()
+}
+
+// String return the environment values with Secret redacted.
+func (env *Environment) String() string {
+	token, secret := env.credential()
+	return fmt.Sprintf("{Address:%s Token:%s Secret:%s Debug:%d IsInsecure:%t IsDryRun:%t}",
+		env.Address, token, secret, env.Debug, env.IsInsecure,
+		env.IsDryRun)
+}
+
+// loadOptions set the Debug and IsDryRun from environment variables.
+func (env *Environment) loadOptions() {
 	v := os.Getenv(EnvNameDebug)
 	if len(v) > 0 {
 		env.Debug, _ = strconv.Atoi(v)
 	}
 
-	if env.Debug >= 1 {
-		fmt.Printf(">>> Environment: %+v\n", env)
+	v = os.Getenv(EnvNameDryRun)
+	if len(v) > 0 {
+		env.IsDryRun, _ = strconv.ParseBool(v)
 	}
+}
 
-	return env
+// credential return the current Token and Secret.
+func (env *Environment) credential() (token string, secret Secret) {
+	credLocker.RLock()
+	token = env.Token
+	secret = env.Secret
+	credLocker.RUnlock()
+	return token, secret
 }
@@ -0,0 +1,220 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestEnvironment_redacted(t *testing.T) {
+	env := &Environment{
+		Token:  "t0ken",
+		Secret: "s3cr3t",
+	}
+
+	cases := []string{
+		fmt.Sprintf("%v", env),
+		fmt.Sprintf("%+v", env),
+		fmt.Sprintf("%s", env.Secret),
+		fmt.Sprintf("%#v", env.Secret),
+		fmt.Sprintf("%d", env.Secret),
+		fmt.Sprintf("%x", env.Secret),
+		fmt.Sprintf("%+v", Credential{Secret: env.Secret}),
+	}
+
+	b, err := json.Marshal(Credential{Secret: env.Secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases = append(cases, string(b))
+
+	for _, got := range cases {
+		if strings.Contains(got, string(env.Secret)) {
+			t.Fatalf("secret is leaked: %s", got)
+		}
+	}
+}
+
+func TestFileCredentialProvider(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "credential.json")
+		fcp  = FileCredentialProvider{Path: path}
+	)
+
+	err := os.WriteFile(path, []byte(`{"token":"t0ken","secret":"s3cr3t"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fcp.Credential()
+	if !errors.Is(err, ErrCredentialPermission) {
+		t.Fatalf("want ErrCredentialPermission, got %v", err)
+	}
+
+	err = os.Chmod(path, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cred, err := fcp.Credential()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Token", "t0ken", cred.Token)
+	test.Assert(t, "Secret", "s3cr3t", string(cred.Secret))
+}
+
+func TestKeyfileCredentialProvider(t *testing.T) {
+	var (
+		path = filepath.Join(t.TempDir(), "credential.key")
+		exp  = &Credential{
+			Token:  "t0ken",
+			Secret: "s3cr3t",
+		}
+	)
+
+	b, err := EncryptCredential(exp, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, b, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = KeyfileCredentialProvider{Path: path, Passphrase: "wrong"}.Credential()
+	if !errors.Is(err, ErrCredentialPassphrase) {
+		t.Fatalf("want ErrCredentialPassphrase, got %v", err)
+	}
+
+	chain := ChainCredentialProvider{
+		FileCredentialProvider{Path: path + ".notexist"},
+		KeyfileCredentialProvider{Path: path, Passphrase: "passphrase"},
+	}
+
+	env, err := NewEnvironmentFromProvider(chain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, secret := env.credential()
+	test.Assert(t, "Token", exp.Token, token)
+	test.Assert(t, "Secret", string(exp.Secret), string(secret))
+}
+
+func TestDecryptCredential(t *testing.T) {
+	b, err := EncryptCredential(&Credential{Token: "t0ken", Secret: "s3cr3t"}, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var kf keyfile
+	err = json.Unmarshal(b, &kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kf.Iter = keyfileMaxIteration + 1
+	b, err = json.Marshal(&kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptCredential(b, "passphrase")
+	if err == nil {
+		t.Fatal("want error on too many iterations")
+	}
+
+	kf.Iter = keyfileMinIteration - 1
+	b, err = json.Marshal(&kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptCredential(b, "passphrase")
+	if err == nil {
+		t.Fatal("want error on too few iterations")
+	}
+
+	kf.Iter = keyfileIteration
+	b, err = json.Marshal(&kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptCredential(b, "wrong")
+	if !errors.Is(err, ErrCredentialPassphrase) {
+		t.Fatalf("want ErrCredentialPassphrase, got %v", err)
+	}
+
+	// Keyfile with empty credential.
+	kf.Iter = keyfileIteration
+	aead, err := newKeyfileCipher("passphrase", kf.Salt, kf.Iter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kf.Data = aead.Seal(nil, kf.Nonce, []byte(`{"token":"","secret":""}`), nil)
+	b, err = json.Marshal(&kf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptCredential(b, "passphrase")
+	if !errors.Is(err, ErrCredentialEmpty) {
+		t.Fatalf("want ErrCredentialEmpty, got %v", err)
+	}
+}
+
+// TestPBKDF2SHA512 test the key derivation using the RFC 6070 inputs, with
+// the expected keys derived using HMAC-SHA512.
+func TestPBKDF2SHA512(t *testing.T) {
+	cases := []struct {
+		password string
+		salt     string
+		exp      string
+		iter     int
+		keyLen   int
+	}{{
+		password: "password",
+		salt:     "salt",
+		iter:     1,
+		keyLen:   64,
+		exp:      "867f70cf1ade02cff3752599a3a53dc4af34c7a669815ae5d513554e1c8cf252c02d470a285a0501bad999bfe943c08f050235d7d68b1da55e63f73b60a57fce",
+	}, {
+		password: "password",
+		salt:     "salt",
+		iter:     2,
+		keyLen:   64,
+		exp:      "e1d9c16aa681708a45f5c7c4e215ceb66e011a2e9f0040713f18aefdb866d53cf76cab2868a39b9f7840edce4fef5a82be67335c77a6068e04112754f27ccf4e",
+	}, {
+		password: "password",
+		salt:     "salt",
+		iter:     4096,
+		keyLen:   64,
+		exp:      "d197b1b33db0143e018b12f3d1d1479e6cdebdcc97c5c0f87f6902e072f457b5143f30602641b3d55cd335988cb36b84376060ecd532e039b742a239434af2d5",
+	}, {
+		password: "passwordPASSWORDpassword",
+		salt:     "saltSALTsaltSALTsaltSALTsaltSALTsalt",
+		iter:     4096,
+		keyLen:   80,
+		exp:      "8c0511f4c6e597c6ac6315d8f0362e225f3c501495ba23b868c005174dc4ee71115b59f9e60cd9532fa33e0f75aefe30225c583a186cd82bd4daea9724a3d3b804f75bdd41494fa324cab24bcc680fb3",
+	}, {
+		password: "pass\x00word",
+		salt:     "sa\x00lt",
+		iter:     4096,
+		keyLen:   16,
+		exp:      "9d9e9c4cd21fe4be24d5b8244c759665",
+	}}
+
+	for _, c := range cases {
+		key := pbkdf2SHA512([]byte(c.password), []byte(c.salt), c.iter, c.keyLen)
+		test.Assert(t, fmt.Sprintf("%q %d", c.password, c.iter), c.exp, hex.EncodeToString(key))
+	}
+}
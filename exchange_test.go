@@ -0,0 +1,289 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// testExchange is the in-memory server that accept the trade, cancel, and
+// open orders requests and response with the same shape as the server.
+// The placed limit orders are kept open until cancelled or closed by test.
+type testExchange struct {
+	t   *testing.T
+	srv *httptest.Server
+
+	// handle, optional, is called before the default handler.
+	// If it return true, the request is considered handled.
+	handle func(w http.ResponseWriter, req *http.Request) bool
+
+	orders map[int64]*Trade
+
+	// paths contains the path of all requests, in order.
+	paths []string
+
+	locker sync.Mutex
+
+	lastID int64
+}
+
+// newTestExchange create and start new testExchange, and the Client that
+// connect to it.
+func newTestExchange(t *testing.T) (ex *testExchange, cl *Client) {
+	ex = &testExchange{
+		t:      t,
+		orders: make(map[int64]*Trade),
+	}
+	ex.srv = httptest.NewServer(http.HandlerFunc(ex.serve))
+	t.Cleanup(ex.srv.Close)
+
+	cl, err := NewClient(&Environment{Address: ex.srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ex, cl
+}
+
+// open return the copy of open orders ordered by ID.
+func (ex *testExchange) open() (orders []Trade) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+	for _, order := range ex.orders {
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(x, y int) bool {
+		return orders[x].ID < orders[y].ID
+	})
+	return orders
+}
+
+// close remove the open order and return it with the status, as if it
+// filled or cancelled in the market.
+func (ex *testExchange) close(id int64, status string) (order *Trade) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+	order = ex.orders[id]
+	if order == nil {
+		return &Trade{ID: id, Status: status}
+	}
+	delete(ex.orders, id)
+	order.Status = status
+	if status == TradeStatusFilled {
+		order.CoinFilled = big.NewRat(order.CoinAmount)
+		order.CoinRemain = big.NewRat(0)
+	}
+	return order
+}
+
+// fill partially fill the open order with amount and return its copy.
+func (ex *testExchange) fill(id int64, amount interface{}) (order Trade) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+	stored := ex.orders[id]
+	if stored == nil {
+		ex.t.Fatalf("testExchange: fill: order %d not found", id)
+	}
+	stored.CoinFilled = big.AddRat(stored.CoinFilled, amount)
+	stored.CoinRemain = big.SubRat(stored.CoinRemain, amount)
+	return *stored
+}
+
+// count return the number of requests to the path.
+func (ex *testExchange) count(path string) (n int) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+	for _, p := range ex.paths {
+		if p == path {
+			n++
+		}
+	}
+	return n
+}
+
+func (ex *testExchange) serve(w http.ResponseWriter, req *http.Request) {
+	ex.locker.Lock()
+	ex.paths = append(ex.paths, req.URL.Path)
+	ex.locker.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if ex.handle != nil && ex.handle(w, req) {
+		return
+	}
+
+	if req.URL.Path == APITradeBulk {
+		ex.serveBulk(w, req)
+		return
+	}
+
+	err := req.ParseForm()
+	if err != nil {
+		ex.t.Error(err)
+	}
+
+	switch req.URL.Path {
+	case APITradeAsk, APITradeBid:
+		tradeType := TradeTypeAsk
+		if req.URL.Path == APITradeBid {
+			tradeType = TradeTypeBid
+		}
+		order := ex.place(tradeType, Pair(req.Form.Get(ParamNamePair)),
+			big.NewRat(req.Form.Get(ParamNameAmount)),
+			big.NewRat(req.Form.Get(ParamNamePrice)))
+		ex.write(w, http.StatusOK, &TradeResponse{Order: order})
+
+	case APITradeCancelAsk, APITradeCancelBid:
+		id, _ := strconv.ParseInt(req.Form.Get(ParamNameTradeID), 10, 64)
+		order := ex.cancel(id)
+		if order == nil {
+			ex.writeNotFound(w)
+			return
+		}
+		ex.write(w, http.StatusOK, &TradeResponse{Order: order})
+
+	case APITradeCancelAll:
+		var cancelled []Trade
+		for _, order := range ex.open() {
+			cancelled = append(cancelled, *ex.cancel(order.ID))
+		}
+		ex.write(w, http.StatusOK, cancelled)
+
+	case APIUserOrderInfo:
+		id, _ := strconv.ParseInt(req.Form.Get(ParamNameTradeID), 10, 64)
+		ex.locker.Lock()
+		order := ex.orders[id]
+		ex.locker.Unlock()
+		if order == nil {
+			ex.writeNotFound(w)
+			return
+		}
+		ex.write(w, http.StatusOK, order)
+
+	case APIUserOrdersOpen:
+		// The server group the open orders by pair and type, without
+		// setting the pair and type on each of them.
+		pto := make(PairTradesOpen)
+		for _, order := range ex.open() {
+			pair, tradeType := order.Pair, order.Type
+			order.Pair, order.Type = "", ""
+			tradesOpen := pto[pair]
+			if tradeType == TradeTypeAsk {
+				tradesOpen.Asks = append(tradesOpen.Asks, order)
+			} else {
+				tradesOpen.Bids = append(tradesOpen.Bids, order)
+			}
+			pto[pair] = tradesOpen
+		}
+		ex.write(w, http.StatusOK, pto)
+
+	default:
+		ex.t.Errorf("testExchange: unexpected request %s %s", req.Method, req.URL)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (ex *testExchange) serveBulk(w http.ResponseWriter, req *http.Request) {
+	var tbReq TradeBulk
+
+	err := json.NewDecoder(req.Body).Decode(&tbReq)
+	if err != nil {
+		ex.t.Error(err)
+	}
+
+	tbRes := &TradeBulk{
+		Pair:      tbReq.Pair,
+		Timestamp: tbReq.Timestamp,
+	}
+	for _, item := range tbReq.Cancel {
+		resItem := &BulkOrderItem{ID: item.ID, RefID: item.RefID}
+		resItem.Code = http.StatusOK
+		if ex.cancel(item.ID) == nil {
+			resItem.Code = http.StatusNotFound
+			resItem.Message = "order not found"
+		}
+		tbRes.Cancel = append(tbRes.Cancel, resItem)
+	}
+	for _, item := range tbReq.Orders {
+		pair := item.Pair
+		if pair.IsEmpty() {
+			pair = tbReq.Pair
+		}
+		order := ex.place(item.Type, pair, item.Amount, item.Price)
+		resItem := &BulkOrderItem{ID: order.ID, RefID: item.RefID}
+		resItem.Code = http.StatusOK
+		tbRes.Orders = append(tbRes.Orders, resItem)
+	}
+	ex.write(w, http.StatusOK, tbRes)
+}
+
+// place create new open limit order or filled market order.
+func (ex *testExchange) place(tradeType string, pair Pair, amount, price *big.Rat) (
+	order *Trade,
+) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+
+	ex.lastID++
+	order = &Trade{
+		Price:      price,
+		CoinAmount: big.NewRat(amount),
+		CoinFilled: big.NewRat(0),
+		CoinRemain: big.NewRat(amount),
+		Pair:       pair.String(),
+		Type:       tradeType,
+		ID:         ex.lastID,
+		SubmitTime: timestamp(),
+	}
+	if price == nil || !price.IsGreaterThanZero() {
+		// The market order is filled immediately.
+		order.Method = TradeMethodMarket
+		order.Price = nil
+		order.CoinFilled = big.NewRat(amount)
+		order.CoinRemain = big.NewRat(0)
+		order.Status = TradeStatusFilled
+		order.FinishTime = order.SubmitTime
+		return order
+	}
+	order.Method = TradeMethodLimit
+	stored := *order
+	ex.orders[order.ID] = &stored
+	return order
+}
+
+// cancel remove the open order and return it as cancelled, or nil if the
+// order is not found.
+func (ex *testExchange) cancel(id int64) (order *Trade) {
+	ex.locker.Lock()
+	defer ex.locker.Unlock()
+	order = ex.orders[id]
+	if order == nil {
+		return nil
+	}
+	delete(ex.orders, id)
+	order.Status = TradeStatusCancelled
+	order.FinishTime = timestamp()
+	return order
+}
+
+func (ex *testExchange) write(w http.ResponseWriter, code int, data interface{}) {
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(&Response{Data: data})
+	if err != nil {
+		ex.t.Error(err)
+	}
+}
+
+func (ex *testExchange) writeNotFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusNotFound)
+	_, _ = w.Write([]byte(`{"code":404,"message":"order not found","name":"ERR_ORDER_NOT_FOUND"}`))
+}
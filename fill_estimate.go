@@ -0,0 +1,203 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"sort"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// FillEstimate contains the expected result of order executed against the
+// market depths.
+type FillEstimate struct {
+	// AveragePrice contains the average price of consumed levels, or
+	// nil if nothing consumed.
+	AveragePrice *big.Rat
+
+	// WorstPrice contains the price of the last level consumed, or nil
+	// if nothing consumed.
+	WorstPrice *big.Rat
+
+	// Coin contains the total coin amount that can be traded.
+	Coin *big.Rat
+
+	// Base contains the total base amount that can be traded.
+	Base *big.Rat
+
+	// Levels contains the number of price levels consumed, including
+	// the partially consumed level.
+	Levels int
+
+	// IsFilled is true if the depths has enough amount to fill all of
+	// the requested amount.
+	IsFilled bool
+}
+
+// EstimateByCoin estimate the fill of "buy" or "sell" order with specific
+// coin amount.
+// The "buy" order consume the Asks, and the "sell" order consume the Bids,
+// from the best price.
+func (depths *MarketDepths) EstimateByCoin(tradeType string, amount *big.Rat) (
+	est *FillEstimate,
+) {
+	return walkDepths(depths.levelsFor(tradeType), amount, nil)
+}
+
+// EstimateByBase estimate the fill of "buy" order that spend the base
+// budget, or "sell" order that receive the base budget.
+func (depths *MarketDepths) EstimateByBase(tradeType string, budget *big.Rat) (
+	est *FillEstimate,
+) {
+	return walkDepths(depths.levelsFor(tradeType), nil, budget)
+}
+
+// MaxAmountWithinImpact return the maximum amount that can be executed with
+// the worst price within the tolerance from the best price.
+// The tolerance is in ratio of the best price, for example 0.01 for 1%:
+// for "buy", only the asks with price less or equal to best ask × 1.01
+// are consumed; for "sell", only the bids with price greater or equal to
+// best bid × 0.99.
+func (depths *MarketDepths) MaxAmountWithinImpact(tradeType string, tolerance *big.Rat) (
+	est *FillEstimate,
+) {
+	levels := depths.levelsFor(tradeType)
+	est = &FillEstimate{
+		Coin: big.NewRat(0),
+		Base: big.NewRat(0),
+	}
+	if len(levels) == 0 {
+		return est
+	}
+
+	limit := big.MulRat(levels[0].Price, tolerance)
+	if tradeType == TradeTypeBid {
+		limit.Add(levels[0].Price)
+	} else {
+		limit = big.SubRat(levels[0].Price, limit)
+	}
+
+	for _, level := range levels {
+		if tradeType == TradeTypeBid && level.Price.IsGreater(limit) {
+			break
+		}
+		if tradeType != TradeTypeBid && level.Price.IsLess(limit) {
+			break
+		}
+		coin, base := depthTotal(level)
+		est.Coin.Add(coin)
+		est.Base.Add(base)
+		est.WorstPrice = big.NewRat(level.Price)
+		est.Levels++
+	}
+	est.setAveragePrice()
+	est.IsFilled = est.Levels > 0
+	return est
+}
+
+// levelsFor return the valid price levels consumed by order type, ordered
+// from the best price.
+func (depths *MarketDepths) levelsFor(tradeType string) (levels []*Depth) {
+	isBuy := tradeType == TradeTypeBid
+	src := depths.Bids
+	if isBuy {
+		src = depths.Asks
+	}
+	for _, level := range src {
+		if level == nil || level.Price == nil || !level.Price.IsGreaterThanZero() {
+			continue
+		}
+		coin, _ := depthTotal(level)
+		if !coin.IsGreaterThanZero() {
+			continue
+		}
+		levels = append(levels, level)
+	}
+	sort.SliceStable(levels, func(x, y int) bool {
+		if isBuy {
+			return levels[x].Price.IsLess(levels[y].Price)
+		}
+		return levels[x].Price.IsGreater(levels[y].Price)
+	})
+	return levels
+}
+
+// depthTotal return the total coin and base on price level.
+// If the TotalCoin is empty, the deprecated Amount is used; if the
+// TotalBase is empty, its computed from the coin and price.
+func depthTotal(level *Depth) (coin, base *big.Rat) {
+	coin = level.TotalCoin
+	if coin == nil {
+		coin = level.Amount
+	}
+	if coin == nil {
+		return big.NewRat(0), big.NewRat(0)
+	}
+	base = level.TotalBase
+	if base == nil || !base.IsGreaterThanZero() {
+		base = big.MulRat(coin, level.Price)
+	}
+	return coin, base
+}
+
+// walkDepths consume the price levels, ordered from the best price, until
+// the coin amount or the base budget reached.
+// Only one of coin or budget should be set.
+func walkDepths(levels []*Depth, coin, budget *big.Rat) (est *FillEstimate) {
+	est = &FillEstimate{
+		Coin: big.NewRat(0),
+		Base: big.NewRat(0),
+	}
+	if (coin == nil || !coin.IsGreaterThanZero()) &&
+		(budget == nil || !budget.IsGreaterThanZero()) {
+		return est
+	}
+
+	for _, level := range levels {
+		levelCoin, levelBase := depthTotal(level)
+
+		var need *big.Rat
+		if coin != nil {
+			need = big.SubRat(coin, est.Coin)
+			if !need.IsLess(levelCoin) {
+				need = nil
+			}
+		} else {
+			need = big.SubRat(budget, est.Base)
+			if !need.IsLess(levelBase) {
+				need = nil
+			} else {
+				need.Quo(level.Price)
+			}
+		}
+		if need == nil {
+			// Consume all of the level.
+			est.Coin.Add(levelCoin)
+			est.Base.Add(levelBase)
+		} else {
+			est.Coin.Add(need)
+			est.Base.Add(big.MulRat(need, level.Price))
+		}
+		est.WorstPrice = big.NewRat(level.Price)
+		est.Levels++
+
+		if coin != nil && !est.Coin.IsLess(coin) {
+			est.IsFilled = true
+			break
+		}
+		if budget != nil && !est.Base.IsLess(budget) {
+			est.IsFilled = true
+			break
+		}
+	}
+	est.setAveragePrice()
+	return est
+}
+
+func (est *FillEstimate) setAveragePrice() {
+	if est.Coin.IsGreaterThanZero() {
+		est.AveragePrice = big.QuoRat(est.Base, est.Coin)
+	}
+}
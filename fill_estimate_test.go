@@ -0,0 +1,75 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestMarketDepths_Estimate(t *testing.T) {
+	depths := &MarketDepths{
+		Asks: []*Depth{
+			{Price: big.NewRat(102), TotalCoin: big.NewRat(3), TotalBase: big.NewRat(306)},
+			{Price: big.NewRat(100), TotalCoin: big.NewRat(1), TotalBase: big.NewRat(100)},
+			{Price: big.NewRat(101), TotalCoin: big.NewRat(2), TotalBase: big.NewRat(202)},
+		},
+		Bids: []*Depth{
+			{Price: big.NewRat(99), TotalCoin: big.NewRat(2)},
+			{Price: big.NewRat(97), Amount: big.NewRat(5)},
+		},
+	}
+
+	type result struct {
+		AveragePrice string
+		WorstPrice   string
+		Coin         string
+		Base         string
+		Levels       int
+		IsFilled     bool
+	}
+	toResult := func(est *FillEstimate) result {
+		return result{
+			AveragePrice: est.AveragePrice.String(),
+			WorstPrice:   est.WorstPrice.String(),
+			Coin:         est.Coin.String(),
+			Base:         est.Base.String(),
+			Levels:       est.Levels,
+			IsFilled:     est.IsFilled,
+		}
+	}
+
+	cases := []struct {
+		desc string
+		est  *FillEstimate
+		exp  result
+	}{{
+		desc: "buy by coin",
+		est:  depths.EstimateByCoin(TradeTypeBid, big.NewRat(4)),
+		exp:  result{"101", "102", "4", "404", 3, true},
+	}, {
+		desc: "buy by base",
+		est:  depths.EstimateByBase(TradeTypeBid, big.NewRat(201)),
+		exp:  result{"100.5", "101", "2", "201", 2, true},
+	}, {
+		desc: "sell by coin not filled",
+		est:  depths.EstimateByCoin(TradeTypeAsk, big.NewRat(10)),
+		exp:  result{"97.57142857", "97", "7", "683", 2, false},
+	}, {
+		desc: "buy within 1%",
+		est:  depths.MaxAmountWithinImpact(TradeTypeBid, big.NewRat("0.01")),
+		exp:  result{"100.66666666", "101", "3", "302", 2, true},
+	}, {
+		desc: "sell within 1%",
+		est:  depths.MaxAmountWithinImpact(TradeTypeAsk, big.NewRat("0.01")),
+		exp:  result{"99", "99", "2", "198", 1, true},
+	}}
+
+	for _, c := range cases {
+		test.Assert(t, c.desc, c.exp, toResult(c.est))
+	}
+}
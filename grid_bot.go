@@ -0,0 +1,710 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// GridConfig define the parameters of grid.
+type GridConfig struct {
+	// LowerPrice, required, is the price of the lowest level.
+	LowerPrice *big.Rat `json:"lower_price"`
+
+	// UpperPrice, required, is the price of the highest level.
+	UpperPrice *big.Rat `json:"upper_price"`
+
+	// Amount, required, is the amount of coin on each order.
+	Amount *big.Rat `json:"amount"`
+
+	// StartPrice, optional, is the price used to split the initial
+	// buy and sell orders.
+	// If its nil, the last price from MarketTicker is used.
+	StartPrice *big.Rat `json:"start_price,omitempty"`
+
+	Pair Pair `json:"pair"`
+
+	// Levels, required, define the number of price levels between
+	// LowerPrice and UpperPrice, inclusive.
+	// Its must be at least 3.
+	Levels int `json:"levels"`
+
+	IsPostOnly bool `json:"post_only,omitempty"`
+}
+
+// GridLevel contains the price and the order on single level of grid.
+type GridLevel struct {
+	Price *big.Rat `json:"price"`
+
+	// Type of order on this level, its either "buy", "sell", or empty
+	// if the level does not have order.
+	Type string `json:"type,omitempty"`
+
+	// OrderID contains the ID of open order on this level, or zero if
+	// the order has not been placed yet.
+	OrderID int64 `json:"order_id,omitempty"`
+
+	// IsPaired is true if the order placed as the opposite of filled
+	// order on neighbour level, which complete one round trip when
+	// filled.
+	IsPaired bool `json:"paired,omitempty"`
+}
+
+// GridSummary contains the summary of grid trading.
+type GridSummary struct {
+	// Profit contains the total profit, in base asset and excluding
+	// the fee, from completed round trips.
+	Profit *big.Rat `json:"profit"`
+
+	// Buys and Sells contains the number of filled orders.
+	Buys  int `json:"buys"`
+	Sells int `json:"sells"`
+
+	// RoundTrips contains the number of filled paired orders.
+	RoundTrips int `json:"round_trips"`
+}
+
+// GridBotOptions define the options for GridBot.
+type GridBotOptions struct {
+	// Client, required, is the REST client used to place and cancel the
+	// orders.
+	// If the Client Markets is set, the Config is validated against
+	// the MarketInfo of pair.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The handler is registered using AddOrdersClosedHandler.
+	// If its nil, the HandleOrdersClosed must be called manually.
+	WebSocket *WebSocketPrivate
+
+	// Path, optional, is the file where the grid state is saved, so the
+	// grid continue after restart.
+	Path string
+
+	Config GridConfig
+}
+
+// gridState contains the grid state that is saved into file.
+type gridState struct {
+	Summary GridSummary  `json:"summary"`
+	Config  GridConfig   `json:"config"`
+	Levels  []*GridLevel `json:"levels"`
+}
+
+// GridBot place a ladder of buy and sell limit orders between the lower
+// and upper price.
+//
+// Initially, the levels below the start price have buy orders, the levels
+// above it have sell orders, and the level nearest to it is left empty.
+// When the buy order filled, the sell order is placed one level above it;
+// when the sell order filled, the buy order is placed one level below it.
+// The opposite orders are placed in the background, after Start and until
+// Stop.
+type GridBot struct {
+	opts  GridBotOptions
+	state gridState
+
+	// early contains the closed orders received while the orders being
+	// placed, before their ID known.
+	early map[int64]*Trade
+
+	// next signal the worker to place the pending orders.
+	next chan struct{}
+	done chan struct{}
+
+	locker sync.Mutex
+
+	// placeLocker serialize the orders placement and Stop.
+	placeLocker sync.Mutex
+
+	isPlacing bool
+	isRunning bool
+}
+
+// NewGridBot create and validate new GridBot.
+// If the Path options is set and the file exist, the grid state is
+// restored from it, and its Config must be equal with the options.
+func NewGridBot(opts GridBotOptions) (grid *GridBot, err error) {
+	logp := "NewGridBot"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+
+	grid = &GridBot{
+		opts:  opts,
+		early: make(map[int64]*Trade),
+		next:  make(chan struct{}, 1),
+	}
+	err = grid.validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	err = grid.load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if grid.state.Summary.Profit == nil {
+		grid.state.Summary.Profit = big.NewRat(0)
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(grid.HandleOrdersClosed)
+	}
+	return grid, nil
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate, and queue the opposite order if the order on grid
+// filled.
+// If the NewGridBot is created with WebSocket options, this method is
+// registered automatically.
+func (grid *GridBot) HandleOrdersClosed(trade *Trade) {
+	if trade == nil || trade.ID == 0 {
+		return
+	}
+
+	grid.locker.Lock()
+	defer grid.locker.Unlock()
+
+	idx := -1
+	for x, level := range grid.state.Levels {
+		if level.OrderID == trade.ID {
+			idx = x
+			break
+		}
+	}
+	if idx < 0 {
+		if grid.isPlacing {
+			// The order may be closed before its placement
+			// response received.
+			grid.early[trade.ID] = trade
+		}
+		return
+	}
+
+	level := grid.state.Levels[idx]
+	if trade.Status != TradeStatusFilled {
+		// The order cancelled outside of grid, re-place it on the
+		// next call to Start.
+		level.OrderID = 0
+		err := grid.save()
+		if err != nil {
+			log.Printf("GridBot: %s", err)
+		}
+		return
+	}
+
+	summary := &grid.state.Summary
+	next := idx + 1
+	if level.Type == TradeTypeBid {
+		summary.Buys++
+	} else {
+		summary.Sells++
+		next = idx - 1
+	}
+	if next < 0 || next >= len(grid.state.Levels) {
+		// Should not happen, the level on edge of grid never
+		// have order toward outside of grid.
+		level.Type = ""
+		level.OrderID = 0
+		err := grid.save()
+		if err != nil {
+			log.Printf("GridBot: %s", err)
+		}
+		return
+	}
+
+	opposite := grid.state.Levels[next]
+	if level.IsPaired {
+		gap := big.SubRat(opposite.Price, level.Price)
+		if level.Type == TradeTypeAsk {
+			gap = big.SubRat(level.Price, opposite.Price)
+		}
+		gap.Mul(grid.opts.Config.Amount)
+		summary.Profit.Add(gap)
+		summary.RoundTrips++
+	}
+
+	if level.Type == TradeTypeBid {
+		opposite.Type = TradeTypeAsk
+	} else {
+		opposite.Type = TradeTypeBid
+	}
+	opposite.OrderID = 0
+	opposite.IsPaired = true
+
+	level.Type = ""
+	level.OrderID = 0
+	level.IsPaired = false
+
+	err := grid.save()
+	if err != nil {
+		log.Printf("GridBot: %s", err)
+	}
+	grid.signal()
+}
+
+// Levels return the copy of grid levels, ordered from the lowest price.
+func (grid *GridBot) Levels() (levels []GridLevel) {
+	grid.locker.Lock()
+	defer grid.locker.Unlock()
+
+	for _, level := range grid.state.Levels {
+		levels = append(levels, *level)
+	}
+	return levels
+}
+
+// Start place the orders on grid that has not been placed yet, and
+// start placing the opposite orders in the background.
+//
+// On the first call, the grid levels are created from the StartPrice.
+// On the next call, or after the state restored from file, the orders on
+// grid are reconciled with server first, so the orders that are filled or
+// cancelled while the grid is not running are handled, and then only the
+// orders that are cancelled or failed to be placed are re-placed.
+func (grid *GridBot) Start() (err error) {
+	logp := "Start"
+
+	grid.locker.Lock()
+	if len(grid.state.Levels) == 0 {
+		err = grid.initLevels()
+		if err != nil {
+			grid.locker.Unlock()
+			return fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+	var ids []int64
+	for _, level := range grid.state.Levels {
+		if level.OrderID != 0 {
+			ids = append(ids, level.OrderID)
+		}
+	}
+	grid.locker.Unlock()
+
+	err = grid.reconcile(ids)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	grid.locker.Lock()
+	if !grid.isRunning {
+		grid.isRunning = true
+		grid.done = make(chan struct{})
+		go grid.work(grid.done)
+	}
+	grid.locker.Unlock()
+
+	err = grid.placePending()
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// Stop cancel all open orders on grid and stop placing the opposite
+// orders.
+// The grid state is kept, so the orders are re-placed on the next Start.
+func (grid *GridBot) Stop() (err error) {
+	grid.locker.Lock()
+	if grid.isRunning {
+		grid.isRunning = false
+		close(grid.done)
+	}
+	grid.locker.Unlock()
+
+	// Wait for the orders being placed.
+	grid.placeLocker.Lock()
+	defer grid.placeLocker.Unlock()
+
+	grid.locker.Lock()
+	bc := NewBulkComposer(0)
+	levels := make(map[int64]*GridLevel)
+	for _, level := range grid.state.Levels {
+		if level.OrderID == 0 {
+			continue
+		}
+		refID := bc.AddCancel(&Trade{
+			Pair: grid.opts.Config.Pair.String(),
+			Type: level.Type,
+			ID:   level.OrderID,
+		})
+		levels[refID] = level
+	}
+	grid.locker.Unlock()
+	if bc.Len() == 0 {
+		return nil
+	}
+
+	result, err := grid.opts.Client.TradeBulkBatch(bc)
+
+	grid.locker.Lock()
+	if result != nil {
+		for _, itemResult := range result.Cancel {
+			if itemResult.Err == nil {
+				levels[itemResult.Request.RefID].OrderID = 0
+			}
+		}
+	}
+	errSave := grid.save()
+	grid.locker.Unlock()
+
+	if err != nil {
+		return fmt.Errorf("Stop: %w", err)
+	}
+	if errSave != nil {
+		return fmt.Errorf("Stop: %w", errSave)
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		return fmt.Errorf("Stop: %d order(s) can not be cancelled: %w",
+			len(failed), failed[0].Err)
+	}
+	return nil
+}
+
+// Summary return the summary of grid trading.
+func (grid *GridBot) Summary() (summary GridSummary) {
+	grid.locker.Lock()
+	defer grid.locker.Unlock()
+
+	summary = grid.state.Summary
+	summary.Profit = big.NewRat(summary.Profit)
+	return summary
+}
+
+// reconcile the orders on grid, by their ids, with server.
+// The orders that are no longer open are fetched using UserOrderInfo and
+// handled by HandleOrdersClosed.
+func (grid *GridBot) reconcile(ids []int64) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pairName := grid.opts.Config.Pair.String()
+
+	pairTradesOpen, err := grid.opts.Client.UserOrdersOpen(pairName)
+	if err != nil {
+		return fmt.Errorf("reconcile: %w", err)
+	}
+	isOpen := make(map[int64]bool)
+	for _, trade := range pairTradesOpen.Trades() {
+		isOpen[trade.ID] = true
+	}
+
+	var errs []string
+	for _, id := range ids {
+		if isOpen[id] {
+			continue
+		}
+		trade, err := grid.opts.Client.UserOrderInfo(pairName, id)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		if len(trade.Status) == 0 {
+			// The order is opened after the list fetched.
+			continue
+		}
+		grid.HandleOrdersClosed(trade)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("reconcile: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// initLevels create the grid levels and set the type of initial orders
+// based on the StartPrice.
+// It must be called while holding the lock.
+func (grid *GridBot) initLevels() (err error) {
+	cfg := grid.opts.Config
+
+	startPrice := cfg.StartPrice
+	if startPrice == nil {
+		tick, err := grid.opts.Client.MarketTicker(cfg.Pair.String())
+		if err != nil {
+			return err
+		}
+		startPrice = tick.LastPrice
+	}
+	if startPrice == nil || !startPrice.IsGreaterThanZero() {
+		return errors.New("invalid start price")
+	}
+
+	var (
+		prices  = grid.prices()
+		gap     = -1
+		nearest *big.Rat
+	)
+	for x, price := range prices {
+		dist := big.SubRat(price, startPrice)
+		if dist.IsLessThanZero() {
+			dist = big.SubRat(startPrice, price)
+		}
+		if nearest == nil || dist.IsLess(nearest) {
+			nearest = dist
+			gap = x
+		}
+	}
+
+	grid.state.Config = cfg
+	grid.state.Levels = make([]*GridLevel, 0, len(prices))
+	for x, price := range prices {
+		level := &GridLevel{
+			Price: price,
+		}
+		switch {
+		case x < gap:
+			level.Type = TradeTypeBid
+		case x > gap:
+			level.Type = TradeTypeAsk
+		}
+		grid.state.Levels = append(grid.state.Levels, level)
+	}
+	return nil
+}
+
+// signal the worker to place the pending orders.
+func (grid *GridBot) signal() {
+	select {
+	case grid.next <- struct{}{}:
+	default:
+	}
+}
+
+// work place the pending orders on each signal, until the grid stopped.
+func (grid *GridBot) work(done chan struct{}) {
+	for {
+		select {
+		case <-done:
+			return
+		case <-grid.next:
+			err := grid.placePending()
+			if err != nil {
+				log.Printf("GridBot: %s", err)
+			}
+		}
+	}
+}
+
+// placePending place the orders on levels that has type but not placed
+// yet, using TradeBulk.
+// The orders are placed without holding the lock, so the closed orders
+// can be consumed meanwhile.
+func (grid *GridBot) placePending() (err error) {
+	cfg := grid.opts.Config
+
+	grid.placeLocker.Lock()
+	defer grid.placeLocker.Unlock()
+
+	grid.locker.Lock()
+	if !grid.isRunning {
+		grid.locker.Unlock()
+		return nil
+	}
+	bc := NewBulkComposer(0)
+	levels := make(map[int64]*GridLevel)
+	for _, level := range grid.state.Levels {
+		if len(level.Type) == 0 || level.OrderID != 0 {
+			continue
+		}
+		ob := NewOrderBuilder(cfg.Pair).
+			Limit(level.Price).
+			Amount(cfg.Amount)
+		if level.Type == TradeTypeAsk {
+			ob.Ask()
+		} else {
+			ob.Bid()
+		}
+		if cfg.IsPostOnly {
+			ob.PostOnly()
+		}
+		item, err := ob.BuildBulkItem()
+		if err != nil {
+			grid.locker.Unlock()
+			return err
+		}
+		levels[bc.AddOrder(item)] = level
+	}
+	if bc.Len() == 0 {
+		err = grid.save()
+		grid.locker.Unlock()
+		return err
+	}
+	grid.isPlacing = true
+	grid.locker.Unlock()
+
+	result, err := grid.opts.Client.TradeBulkBatch(bc)
+
+	var early []*Trade
+
+	grid.locker.Lock()
+	if result != nil {
+		for _, itemResult := range result.Orders {
+			if itemResult.Err != nil {
+				continue
+			}
+			levels[itemResult.Request.RefID].OrderID = itemResult.ID
+			trade := grid.early[itemResult.ID]
+			if trade != nil {
+				early = append(early, trade)
+			}
+		}
+	}
+	grid.isPlacing = false
+	grid.early = make(map[int64]*Trade)
+	errSave := grid.save()
+	grid.locker.Unlock()
+
+	for _, trade := range early {
+		grid.HandleOrdersClosed(trade)
+	}
+
+	if err != nil {
+		return err
+	}
+	if failed := result.Failed(); len(failed) > 0 {
+		var errs []string
+		for _, itemResult := range failed {
+			errs = append(errs, fmt.Sprintf("%s %s: %s",
+				itemResult.Request.Type, itemResult.Request.Price,
+				itemResult.Err))
+		}
+		return fmt.Errorf("place orders: %s", strings.Join(errs, "; "))
+	}
+	return errSave
+}
+
+// prices return the price on each level, from LowerPrice to UpperPrice.
+func (grid *GridBot) prices() (prices []*big.Rat) {
+	cfg := grid.opts.Config
+
+	var info *MarketInfo
+	if grid.opts.Client.Markets != nil {
+		info = grid.opts.Client.Markets.Get(cfg.Pair)
+	}
+
+	step := big.SubRat(cfg.UpperPrice, cfg.LowerPrice)
+	step.Quo(cfg.Levels - 1)
+	for x := 0; x < cfg.Levels; x++ {
+		price := big.MulRat(step, x)
+		price.Add(cfg.LowerPrice)
+		if info != nil {
+			price = info.RoundPrice(price)
+		}
+		prices = append(prices, price)
+	}
+	return prices
+}
+
+// validate the grid Config, against the MarketInfo if the Client Markets is
+// set.
+func (grid *GridBot) validate() (err error) {
+	cfg := grid.opts.Config
+
+	err = cfg.Pair.Validate()
+	if err != nil {
+		return err
+	}
+	if cfg.Levels < 3 {
+		return fmt.Errorf("invalid Levels %d", cfg.Levels)
+	}
+	if cfg.LowerPrice == nil || !cfg.LowerPrice.IsGreaterThanZero() {
+		return errors.New("invalid LowerPrice")
+	}
+	if cfg.UpperPrice == nil || !cfg.UpperPrice.IsGreater(cfg.LowerPrice) {
+		return errors.New("invalid UpperPrice")
+	}
+	if cfg.Amount == nil || !cfg.Amount.IsGreaterThanZero() {
+		return ErrInvalidAmount
+	}
+
+	markets := grid.opts.Client.Markets
+	if markets == nil {
+		return nil
+	}
+	err = markets.Validate(cfg.Pair)
+	if err != nil {
+		return err
+	}
+	info := markets.Get(cfg.Pair)
+	if info.PriceMinimum != nil && cfg.LowerPrice.IsLess(info.PriceMinimum) {
+		return fmt.Errorf("LowerPrice is less than minimum %s", info.PriceMinimum)
+	}
+	if info.AmountMinimum != nil && cfg.Amount.IsLess(info.AmountMinimum) {
+		return fmt.Errorf("Amount is less than minimum %s", info.AmountMinimum)
+	}
+	if !info.RoundAmount(cfg.Amount).IsEqual(cfg.Amount) {
+		return fmt.Errorf("Amount precision is more than %d",
+			info.AmountPrecision)
+	}
+
+	prices := grid.prices()
+	for x := 1; x < len(prices); x++ {
+		if !prices[x].IsGreater(prices[x-1]) {
+			return errors.New("price step is less than price precision")
+		}
+	}
+	return nil
+}
+
+// load the grid state from file.
+func (grid *GridBot) load() (err error) {
+	if len(grid.opts.Path) == 0 {
+		return nil
+	}
+
+	b, err := os.ReadFile(grid.opts.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	err = json.Unmarshal(b, &grid.state)
+	if err != nil {
+		return fmt.Errorf("%s: %w", grid.opts.Path, err)
+	}
+
+	cfg := grid.opts.Config
+	saved := grid.state.Config
+	if saved.Pair != cfg.Pair || saved.Levels != cfg.Levels ||
+		!saved.LowerPrice.IsEqual(cfg.LowerPrice) ||
+		!saved.UpperPrice.IsEqual(cfg.UpperPrice) ||
+		!saved.Amount.IsEqual(cfg.Amount) {
+		return fmt.Errorf("%s: Config does not match with saved state",
+			grid.opts.Path)
+	}
+	return nil
+}
+
+// save the grid state into file.
+// It must be called while holding the lock.
+func (grid *GridBot) save() (err error) {
+	if len(grid.opts.Path) == 0 {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(&grid.state, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(grid.opts.Path, b)
+	if err != nil {
+		log.Printf("GridBot: %s", err)
+	}
+	return err
+}
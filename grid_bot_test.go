@@ -0,0 +1,219 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestGridBot(t *testing.T) {
+	ex, cl := newTestExchange(t)
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:            PairTokenomyIdk,
+		PriceMinimum:    big.NewRat(1),
+		AmountMinimum:   big.NewRat(1),
+		AmountPrecision: 2,
+		IsActive:        true,
+	}})
+
+	opts := GridBotOptions{
+		Client: cl,
+		Path:   filepath.Join(t.TempDir(), "grid.json"),
+		Config: GridConfig{
+			LowerPrice: big.NewRat(100),
+			UpperPrice: big.NewRat(140),
+			Amount:     big.NewRat(2),
+			StartPrice: big.NewRat(121),
+			Pair:       PairTokenomyIdk,
+			Levels:     5,
+		},
+	}
+
+	opts.Config.Amount = big.NewRat("0.5")
+	_, err := NewGridBot(opts)
+	test.Assert(t, "Amount minimum", "NewGridBot: Amount is less than minimum 1", err.Error())
+	opts.Config.Amount = big.NewRat(2)
+
+	grid, err := NewGridBot(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = grid.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := func() (types []string) {
+		for _, level := range grid.Levels() {
+			types = append(types, level.Type)
+		}
+		return types
+	}
+	// placed wait until the opposite order placed by the worker.
+	placed := func() {
+		for x := 0; x < 100; x++ {
+			var isPending bool
+			for _, level := range grid.Levels() {
+				if len(level.Type) > 0 && level.OrderID == 0 {
+					isPending = true
+				}
+			}
+			if !isPending {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("the opposite order is not placed")
+	}
+	fill := func(idx int) {
+		level := grid.Levels()[idx]
+		grid.HandleOrdersClosed(ex.close(level.OrderID, TradeStatusFilled))
+		placed()
+	}
+
+	test.Assert(t, "initial", []string{"buy", "buy", "", "sell", "sell"}, types())
+
+	fill(1)
+	test.Assert(t, "buy filled", []string{"buy", "", "sell", "sell", "sell"}, types())
+	fill(2)
+	test.Assert(t, "sell filled", []string{"buy", "buy", "", "sell", "sell"}, types())
+	fill(3)
+	test.Assert(t, "unpaired sell filled", []string{"buy", "buy", "buy", "", "sell"}, types())
+
+	summary := grid.Summary()
+	test.Assert(t, "Buys", 1, summary.Buys)
+	test.Assert(t, "Sells", 2, summary.Sells)
+	test.Assert(t, "RoundTrips", 1, summary.RoundTrips)
+	test.Assert(t, "Profit", "20", summary.Profit.String())
+
+	// The state is restored from file.
+	restored, err := NewGridBot(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "restored Levels", grid.Levels(), restored.Levels())
+	test.Assert(t, "restored Summary", summary, restored.Summary())
+
+	err = grid.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, level := range grid.Levels() {
+		test.Assert(t, "OrderID after Stop", int64(0), level.OrderID)
+	}
+	test.Assert(t, "open orders after Stop", 0, len(ex.open()))
+
+	// The order filled before the TradeBulk response received.
+	var isFilled bool
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeBulk {
+			return false
+		}
+		ex.locker.Lock()
+		if isFilled {
+			ex.locker.Unlock()
+			return false
+		}
+		isFilled = true
+		ex.locker.Unlock()
+
+		rec := httptest.NewRecorder()
+		ex.serveBulk(rec, req)
+
+		ex.locker.Lock()
+		lastID := ex.lastID
+		ex.locker.Unlock()
+		grid.HandleOrdersClosed(ex.close(lastID, TradeStatusFilled))
+
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+		return true
+	}
+	err = grid.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+	placed()
+	test.Assert(t, "early sell filled", []string{"buy", "buy", "buy", "buy", ""}, types())
+	test.Assert(t, "Sells", 3, grid.Summary().Sells)
+
+	err = grid.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGridBot_reconcile(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	opts := GridBotOptions{
+		Client: cl,
+		Path:   filepath.Join(t.TempDir(), "grid.json"),
+		Config: GridConfig{
+			LowerPrice: big.NewRat(100),
+			UpperPrice: big.NewRat(140),
+			Amount:     big.NewRat(2),
+			StartPrice: big.NewRat(121),
+			Pair:       PairTokenomyIdk,
+			Levels:     5,
+		},
+	}
+
+	grid, err := NewGridBot(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = grid.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The buy order on level 1 filled while the grid is offline.
+	filled := ex.close(grid.Levels()[1].OrderID, TradeStatusFilled)
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APIUserOrderInfo {
+			return false
+		}
+		ex.write(w, http.StatusOK, filled)
+		return true
+	}
+
+	restored, err := NewGridBot(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = restored.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		types   []string
+		nOpened int
+	)
+	for _, level := range restored.Levels() {
+		types = append(types, level.Type)
+		if level.OrderID != 0 {
+			nOpened++
+		}
+	}
+	test.Assert(t, "types", []string{"buy", "", "sell", "sell", "sell"}, types)
+	test.Assert(t, "placed", 4, nOpened)
+	test.Assert(t, "Buys", 1, restored.Summary().Buys)
+	test.Assert(t, "order info", 1, ex.count(APIUserOrderInfo))
+	test.Assert(t, "open orders", 4, len(ex.open()))
+
+	err = restored.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
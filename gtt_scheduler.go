@@ -0,0 +1,288 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+)
+
+// DefaultGTTCheckInterval define the default interval where GTTScheduler
+// check the expired orders.
+const DefaultGTTCheckInterval = time.Second
+
+// GTTHandler define a callback that will be called after the expired order
+// has been cancelled, or when the cancellation failed.
+type GTTHandler func(expiry GTTExpiry, cancelled *Trade, err error)
+
+// GTTExpiry contains the order and the time when its cancelled.
+type GTTExpiry struct {
+	ExpireAt time.Time `json:"expire_at"`
+	Pair     string    `json:"pair"`
+	Type     string    `json:"type"`
+	ID       int64     `json:"id"`
+}
+
+// GTTSchedulerOptions define the options for GTTScheduler.
+type GTTSchedulerOptions struct {
+	// Client, required, is the REST client used to cancel the expired
+	// orders.
+	Client *Client
+
+	// HandleExpired, optional, is the callback that will be called
+	// after the expired order cancelled.
+	HandleExpired GTTHandler
+
+	// Path, optional, is the file where the scheduled orders are saved,
+	// so they are restored after restart.
+	Path string
+
+	// CheckInterval, optional, define the interval to check the expired
+	// orders.
+	// Default to DefaultGTTCheckInterval.
+	CheckInterval time.Duration
+}
+
+// GTTScheduler cancel the open orders when they reach their expiry time.
+//
+// The Client and WebSocketPrivate use the scheduler, set in their GTT
+// field, to emulate the TimeInForce "GTT".
+type GTTScheduler struct {
+	opts GTTSchedulerOptions
+
+	expiries map[int64]*GTTExpiry
+
+	done chan struct{}
+
+	locker sync.Mutex
+
+	isRunning bool
+}
+
+// NewGTTScheduler create and initialize new GTTScheduler.
+// If the Path options is set, the scheduled orders are loaded from it.
+// The scheduler is not running until Start is called.
+func NewGTTScheduler(opts GTTSchedulerOptions) (gtt *GTTScheduler, err error) {
+	logp := "NewGTTScheduler"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if opts.CheckInterval <= 0 {
+		opts.CheckInterval = DefaultGTTCheckInterval
+	}
+
+	gtt = &GTTScheduler{
+		opts:     opts,
+		expiries: make(map[int64]*GTTExpiry),
+	}
+
+	err = gtt.load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	return gtt, nil
+}
+
+// Pending return the list of scheduled orders, ordered by their expiry
+// time.
+func (gtt *GTTScheduler) Pending() (expiries []GTTExpiry) {
+	gtt.locker.Lock()
+	for _, expiry := range gtt.expiries {
+		expiries = append(expiries, *expiry)
+	}
+	gtt.locker.Unlock()
+
+	sort.Slice(expiries, func(x, y int) bool {
+		return expiries[x].ExpireAt.Before(expiries[y].ExpireAt)
+	})
+	return expiries
+}
+
+// Schedule the open order to be cancelled at expireAt.
+func (gtt *GTTScheduler) Schedule(trade *Trade, expireAt time.Time) (err error) {
+	logp := "Schedule"
+
+	if trade == nil || trade.ID <= 0 {
+		return fmt.Errorf("%s: %w", logp, ErrInvalidTradeID)
+	}
+
+	expiry := &GTTExpiry{
+		ExpireAt: expireAt,
+		Pair:     trade.Pair,
+		Type:     trade.Type,
+		ID:       trade.ID,
+	}
+
+	gtt.locker.Lock()
+	defer gtt.locker.Unlock()
+
+	gtt.expiries[trade.ID] = expiry
+	err = gtt.save()
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// Unschedule remove the order from scheduler, for example when the order
+// has been filled or cancelled.
+func (gtt *GTTScheduler) Unschedule(id int64) (err error) {
+	gtt.locker.Lock()
+	defer gtt.locker.Unlock()
+
+	if _, ok := gtt.expiries[id]; !ok {
+		return nil
+	}
+	delete(gtt.expiries, id)
+
+	err = gtt.save()
+	if err != nil {
+		return fmt.Errorf("Unschedule: %w", err)
+	}
+	return nil
+}
+
+// CancelExpired cancel all of the orders that has been expired at time
+// now.
+//
+// The order that can not be cancelled because of network error is kept
+// and retried on the next call; the order that rejected by server, for
+// example because its has been filled, is removed.
+func (gtt *GTTScheduler) CancelExpired(now time.Time) {
+	var expired []*GTTExpiry
+
+	gtt.locker.Lock()
+	for _, expiry := range gtt.expiries {
+		if !expiry.ExpireAt.After(now) {
+			expired = append(expired, expiry)
+		}
+	}
+	gtt.locker.Unlock()
+
+	sort.Slice(expired, func(x, y int) bool {
+		return expired[x].ExpireAt.Before(expired[y].ExpireAt)
+	})
+
+	for _, expiry := range expired {
+		trade := &Trade{
+			Pair: expiry.Pair,
+			Type: expiry.Type,
+			ID:   expiry.ID,
+		}
+		cancelled, err := gtt.opts.Client.TradeCancel(trade)
+		if err != nil {
+			log.Printf("GTTScheduler: cancel %s %d: %s", expiry.Pair,
+				expiry.ID, err)
+
+			var errServer *liberrors.E
+			if !errors.As(err, &errServer) {
+				continue
+			}
+		}
+
+		errRemove := gtt.Unschedule(expiry.ID)
+		if errRemove != nil {
+			log.Printf("GTTScheduler: %s", errRemove)
+		}
+		if gtt.opts.HandleExpired != nil {
+			gtt.opts.HandleExpired(*expiry, cancelled, err)
+		}
+	}
+}
+
+// Start checking the expired orders in the background.
+// Calling Start on running GTTScheduler has no effect.
+func (gtt *GTTScheduler) Start() {
+	gtt.locker.Lock()
+	defer gtt.locker.Unlock()
+
+	if gtt.isRunning {
+		return
+	}
+	gtt.isRunning = true
+	gtt.done = make(chan struct{})
+
+	go gtt.run(gtt.done)
+}
+
+// Stop checking the expired orders.
+// The scheduled orders are kept.
+func (gtt *GTTScheduler) Stop() {
+	gtt.locker.Lock()
+	defer gtt.locker.Unlock()
+
+	if !gtt.isRunning {
+		return
+	}
+	close(gtt.done)
+	gtt.isRunning = false
+}
+
+func (gtt *GTTScheduler) run(done chan struct{}) {
+	ticker := time.NewTicker(gtt.opts.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			gtt.CancelExpired(now)
+		}
+	}
+}
+
+// load the scheduled orders from file.
+func (gtt *GTTScheduler) load() (err error) {
+	if len(gtt.opts.Path) == 0 {
+		return nil
+	}
+
+	b, err := os.ReadFile(gtt.opts.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var expiries []*GTTExpiry
+	err = json.Unmarshal(b, &expiries)
+	if err != nil {
+		return fmt.Errorf("%s: %w", gtt.opts.Path, err)
+	}
+	for _, expiry := range expiries {
+		gtt.expiries[expiry.ID] = expiry
+	}
+	return nil
+}
+
+// save the scheduled orders into file.
+// It must be called while holding the lock.
+func (gtt *GTTScheduler) save() (err error) {
+	if len(gtt.opts.Path) == 0 {
+		return nil
+	}
+
+	expiries := make([]*GTTExpiry, 0, len(gtt.expiries))
+	for _, expiry := range gtt.expiries {
+		expiries = append(expiries, expiry)
+	}
+
+	b, err := json.MarshalIndent(expiries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(gtt.opts.Path, b)
+}
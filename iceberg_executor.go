@@ -0,0 +1,512 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// IcebergHandler define a callback that will be called after each visible
+// slice closed and when the execution stopped.
+type IcebergHandler func(progress IcebergProgress)
+
+// IcebergOrder define the parent limit order to be executed by
+// IcebergExecutor.
+type IcebergOrder struct {
+	// Amount, required, is the total amount of coin to be traded.
+	Amount *big.Rat
+
+	// Price, required, is the limit price of all slices.
+	Price *big.Rat
+
+	// VisibleAmount, required, is the amount of coin shown on the book
+	// for each slice.
+	VisibleAmount *big.Rat
+
+	// Variance, optional, randomize the size of each slice by up to
+	// the ratio of VisibleAmount, for example 0.2 to vary the size
+	// between 80% and 120% of VisibleAmount.
+	Variance *big.Rat
+
+	Pair Pair
+
+	// Type of order, its either "buy" or "sell".
+	Type string
+
+	// IsPostOnly, optional, place each slice as post-only order.
+	IsPostOnly bool
+}
+
+// IcebergProgress contains the execution progress of iceberg order.
+type IcebergProgress struct {
+	// LastErr contains the error that stop the execution, if any.
+	LastErr error
+
+	// Filled contains the total amount of coin that has been traded.
+	Filled *big.Rat
+
+	// Remaining contains the amount of coin that has not been traded.
+	Remaining *big.Rat
+
+	// Visible contains the open slice, or nil if there is none.
+	Visible *Trade
+
+	// State of execution, its either AlgoStateRunning, AlgoStateDone,
+	// or AlgoStateCancelled.
+	State string
+
+	// Slices contains the number of slices placed.
+	Slices int
+}
+
+// IcebergOptions define the options for IcebergExecutor.
+type IcebergOptions struct {
+	// Client, required, is the REST client used to place and cancel the
+	// slices.
+	// If the Client Markets is set, the slice amount is rounded to the
+	// AmountPrecision and the slices are sized so none of them, including
+	// the last one, is less than AmountMinimum.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The handler is registered using AddOrdersClosedHandler.
+	// If its nil, the HandleOrdersClosed must be called manually.
+	WebSocket *WebSocketPrivate
+
+	// HandleProgress, optional, is the callback that will be called on
+	// each progress.
+	HandleProgress IcebergHandler
+
+	// Order, required, is the parent order.
+	Order IcebergOrder
+}
+
+// IcebergExecutor execute large limit order by showing only a small slice
+// of it on the book.
+//
+// When the visible slice filled, detected from the closed orders
+// broadcast, the next slice is placed immediately at the same price until
+// the total amount has been traded.
+// If the visible slice is cancelled outside of executor or the next slice
+// can not be placed, the execution stopped with state AlgoStateCancelled.
+type IcebergExecutor struct {
+	opts IcebergOptions
+
+	filled  *big.Rat
+	visible *Trade
+	lastErr error
+
+	// early contains the closed orders received while the slice being
+	// placed, before its ID known.
+	early map[int64]*Trade
+
+	finished chan struct{}
+
+	// next signal the worker to place the next slice, so the orders
+	// are not placed inside the WebSocket handler.
+	next chan struct{}
+
+	state  string
+	slices int
+
+	locker sync.Mutex
+
+	// isCancelling is true while the visible slice is being cancelled
+	// by Cancel.
+	isCancelling bool
+
+	isPlacing bool
+}
+
+// NewIcebergExecutor create and validate new IcebergExecutor.
+// The execution is not started until Start is called.
+func NewIcebergExecutor(opts IcebergOptions) (ice *IcebergExecutor, err error) {
+	logp := "NewIcebergExecutor"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+
+	order := opts.Order
+	switch order.Type {
+	case TradeTypeAsk, TradeTypeBid:
+	default:
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeType)
+	}
+	err = order.Pair.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if order.Amount == nil || !order.Amount.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidAmount)
+	}
+	if order.Price == nil || !order.Price.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidPrice)
+	}
+	if order.VisibleAmount == nil || !order.VisibleAmount.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: invalid VisibleAmount", logp)
+	}
+	if order.Variance != nil {
+		if order.Variance.IsLessThanZero() || !order.Variance.IsLess(1) {
+			return nil, fmt.Errorf("%s: invalid Variance", logp)
+		}
+	}
+
+	if opts.Client.Markets != nil {
+		info := opts.Client.Markets.Get(order.Pair)
+		if info != nil {
+			if info.PriceMinimum != nil && order.Price.IsLess(info.PriceMinimum) {
+				return nil, fmt.Errorf("%s: Price is less than minimum %s",
+					logp, info.PriceMinimum)
+			}
+			if info.AmountMinimum != nil && order.Amount.IsLess(info.AmountMinimum) {
+				return nil, fmt.Errorf("%s: Amount is less than minimum %s",
+					logp, info.AmountMinimum)
+			}
+		}
+	}
+
+	ice = &IcebergExecutor{
+		opts:     opts,
+		filled:   big.NewRat(0),
+		early:    make(map[int64]*Trade),
+		finished: make(chan struct{}),
+		next:     make(chan struct{}, 1),
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(ice.HandleOrdersClosed)
+	}
+	return ice, nil
+}
+
+// Cancel stop the execution and cancel the visible slice.
+func (ice *IcebergExecutor) Cancel() (err error) {
+	ice.locker.Lock()
+	if ice.state != AlgoStateRunning || ice.isCancelling {
+		ice.locker.Unlock()
+		return nil
+	}
+	ice.isCancelling = true
+	visible := ice.visible
+	ice.locker.Unlock()
+
+	if visible != nil {
+		_, err = ice.opts.Client.TradeCancel(visible)
+		if err != nil {
+			ice.locker.Lock()
+			ice.isCancelling = false
+			ice.locker.Unlock()
+			ice.signal()
+			return fmt.Errorf("Cancel: %w", err)
+		}
+	}
+
+	ice.locker.Lock()
+	ice.isCancelling = false
+	if ice.state == AlgoStateRunning {
+		ice.visible = nil
+		ice.finish(AlgoStateCancelled)
+	}
+	ice.locker.Unlock()
+
+	ice.notify()
+	return nil
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate.
+// If the visible slice filled, the next slice is placed in the background.
+// If the NewIcebergExecutor is created with WebSocket options, this method
+// is registered automatically.
+func (ice *IcebergExecutor) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+
+	ice.locker.Lock()
+	if ice.state != AlgoStateRunning {
+		ice.locker.Unlock()
+		return
+	}
+	if ice.visible == nil || ice.visible.ID != trade.ID {
+		if ice.isPlacing {
+			// The slice may be closed before its placement
+			// response received.
+			ice.early[trade.ID] = trade
+		}
+		ice.locker.Unlock()
+		return
+	}
+	ice.close(trade)
+	ice.locker.Unlock()
+
+	ice.signal()
+	ice.notify()
+}
+
+// Progress return the current execution progress.
+func (ice *IcebergExecutor) Progress() IcebergProgress {
+	ice.locker.Lock()
+	defer ice.locker.Unlock()
+	return ice.progress()
+}
+
+// Start the execution by placing the first visible slice.
+// The next slices are placed in the background, after the previous slice
+// filled.
+// Calling Start more than once has no effect.
+func (ice *IcebergExecutor) Start() (err error) {
+	ice.locker.Lock()
+	if len(ice.state) > 0 {
+		ice.locker.Unlock()
+		return nil
+	}
+	ice.state = AlgoStateRunning
+	ice.locker.Unlock()
+
+	ice.placeNext()
+
+	ice.locker.Lock()
+	err = ice.lastErr
+	ice.locker.Unlock()
+
+	go ice.work()
+
+	ice.notify()
+	if err != nil {
+		return fmt.Errorf("Start: %w", err)
+	}
+	return nil
+}
+
+// Wait until the execution done or cancelled, and return the final
+// progress.
+func (ice *IcebergExecutor) Wait() IcebergProgress {
+	<-ice.finished
+	return ice.Progress()
+}
+
+// signal the worker to place the next slice.
+func (ice *IcebergExecutor) signal() {
+	select {
+	case ice.next <- struct{}{}:
+	default:
+	}
+}
+
+// work place the next slice on each signal, until the execution
+// finished.
+func (ice *IcebergExecutor) work() {
+	for {
+		select {
+		case <-ice.finished:
+			return
+		case <-ice.next:
+			ice.placeNext()
+			ice.notify()
+		}
+	}
+}
+
+// placeNext place the next visible slice, until the slice does not filled
+// immediately.
+// It is called by Start and the worker only, so there is only one slice
+// placed at a time.
+func (ice *IcebergExecutor) placeNext() {
+	order := ice.opts.Order
+
+	for {
+		ice.locker.Lock()
+		if ice.state != AlgoStateRunning || ice.isCancelling || ice.visible != nil {
+			ice.locker.Unlock()
+			return
+		}
+		remaining := big.SubRat(order.Amount, ice.filled)
+		if !remaining.IsGreaterThanZero() {
+			ice.finish(AlgoStateDone)
+			ice.locker.Unlock()
+			return
+		}
+		amount := ice.sliceAmount(remaining)
+		ice.isPlacing = true
+		ice.locker.Unlock()
+
+		ob := NewOrderBuilder(order.Pair).
+			Limit(order.Price).
+			Amount(amount)
+		if order.IsPostOnly {
+			ob.PostOnly()
+		}
+
+		var (
+			treq *TradeRequest
+			tres *TradeResponse
+			err  error
+		)
+		if order.Type == TradeTypeAsk {
+			treq, err = ob.Ask().Build()
+			if err == nil {
+				tres, err = ice.opts.Client.TradeAsk(treq)
+			}
+		} else {
+			treq, err = ob.Bid().Build()
+			if err == nil {
+				tres, err = ice.opts.Client.TradeBid(treq)
+			}
+		}
+		if err == nil && (tres == nil || tres.Order == nil) {
+			err = errors.New("empty order in response")
+		}
+
+		ice.locker.Lock()
+		ice.isPlacing = false
+		early := ice.early
+		ice.early = make(map[int64]*Trade)
+		if err != nil {
+			if ice.state == AlgoStateRunning {
+				ice.lastErr = fmt.Errorf("place slice %s: %w", amount, err)
+				ice.finish(AlgoStateCancelled)
+			}
+			ice.locker.Unlock()
+			return
+		}
+
+		ice.slices++
+		visible := tres.Order
+		if len(visible.Pair) == 0 {
+			visible.Pair = order.Pair.String()
+		}
+		if len(visible.Type) == 0 {
+			visible.Type = order.Type
+		}
+		if visible.CoinAmount == nil {
+			visible.CoinAmount = amount
+		}
+		ice.visible = visible
+
+		closed := visible
+		if len(closed.Status) == 0 && early[visible.ID] != nil {
+			closed = early[visible.ID]
+		}
+		if len(closed.Status) > 0 {
+			ice.close(closed)
+			ice.locker.Unlock()
+			continue
+		}
+		if ice.state == AlgoStateRunning && !ice.isCancelling {
+			ice.locker.Unlock()
+			return
+		}
+
+		// The execution has been cancelled while the slice being
+		// placed.
+		ice.visible = nil
+		ice.locker.Unlock()
+
+		_, err = ice.opts.Client.TradeCancel(visible)
+		if err != nil {
+			log.Printf("IcebergExecutor: cancel slice %d: %s", visible.ID, err)
+		}
+		return
+	}
+}
+
+// close record the closed visible slice.
+// It must be called while holding the lock.
+func (ice *IcebergExecutor) close(trade *Trade) {
+	filled := trade.CoinFilled
+	if trade.Status == TradeStatusFilled {
+		filled = ice.visible.CoinAmount
+		if trade.CoinAmount != nil {
+			filled = trade.CoinAmount
+		}
+	}
+	if filled != nil {
+		ice.filled.Add(filled)
+	}
+	ice.visible = nil
+
+	if trade.Status == TradeStatusCancelled && ice.state == AlgoStateRunning {
+		ice.lastErr = fmt.Errorf("slice %d cancelled", trade.ID)
+		ice.finish(AlgoStateCancelled)
+	}
+}
+
+// sliceAmount return the amount of next visible slice, randomized by
+// Variance and rounded using the market information.
+// If the amount left after the slice is less than AmountMinimum, the
+// slice take all of the remaining amount.
+// It must be called while holding the lock.
+func (ice *IcebergExecutor) sliceAmount(remaining *big.Rat) (amount *big.Rat) {
+	order := ice.opts.Order
+
+	amount = big.NewRat(order.VisibleAmount)
+	if order.Variance != nil && order.Variance.IsGreaterThanZero() {
+		// factor = 1 + Variance * r, where r is in [-1, 1].
+		r := big.NewRat(rand.Int63n(2001) - 1000)
+		r.Quo(1000)
+		factor := big.MulRat(order.Variance, r)
+		factor.Add(1)
+		amount.Mul(factor)
+	}
+
+	var minimum *big.Rat
+	if ice.opts.Client.Markets != nil {
+		info := ice.opts.Client.Markets.Get(order.Pair)
+		if info != nil {
+			amount = info.RoundAmount(amount)
+			minimum = info.AmountMinimum
+		}
+	}
+	if minimum != nil && amount.IsLess(minimum) {
+		amount = big.NewRat(minimum)
+	}
+	if !amount.IsLess(remaining) {
+		return remaining
+	}
+	if minimum != nil && big.SubRat(remaining, amount).IsLess(minimum) {
+		return remaining
+	}
+	return amount
+}
+
+// finish set the final state of execution.
+// It must be called while holding the lock.
+func (ice *IcebergExecutor) finish(state string) {
+	ice.state = state
+	close(ice.finished)
+}
+
+func (ice *IcebergExecutor) notify() {
+	if ice.opts.HandleProgress == nil {
+		return
+	}
+	ice.opts.HandleProgress(ice.Progress())
+}
+
+// progress return the current progress.
+// It must be called while holding the lock.
+func (ice *IcebergExecutor) progress() (progress IcebergProgress) {
+	progress = IcebergProgress{
+		LastErr:   ice.lastErr,
+		Filled:    big.NewRat(ice.filled),
+		Remaining: big.SubRat(ice.opts.Order.Amount, ice.filled),
+		State:     ice.state,
+		Slices:    ice.slices,
+	}
+	if ice.visible != nil {
+		visible := *ice.visible
+		progress.Visible = &visible
+	}
+	return progress
+}
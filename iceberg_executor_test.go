@@ -0,0 +1,139 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestIcebergExecutor(t *testing.T) {
+	ex, cl := newTestExchange(t)
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:            PairTokenomyIdk,
+		AmountMinimum:   big.NewRat(2),
+		AmountPrecision: 0,
+		IsActive:        true,
+	}})
+
+	ws := &WebSocketPrivate{}
+
+	// placed receive the amount of each visible slice, since the next
+	// slice is placed in the background.
+	placed := make(chan string, 10)
+	ice, err := NewIcebergExecutor(IcebergOptions{
+		Client:    cl,
+		WebSocket: ws,
+		Order: IcebergOrder{
+			Amount:        big.NewRat(11),
+			Price:         big.NewRat(100),
+			VisibleAmount: big.NewRat(4),
+			Pair:          PairTokenomyIdk,
+			Type:          TradeTypeAsk,
+		},
+		HandleProgress: func(progress IcebergProgress) {
+			if progress.Visible != nil {
+				placed <- progress.Visible.CoinAmount.String()
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ice.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	amounts := []string{<-placed}
+
+	// Unknown order is ignored.
+	ws.handleOrdersClosed(&Trade{ID: -1, Status: TradeStatusFilled})
+
+	for x := 0; x < 2; x++ {
+		visible := ice.Progress().Visible
+		ws.handleOrdersClosed(ex.close(visible.ID, TradeStatusFilled))
+		amounts = append(amounts, <-placed)
+	}
+
+	// The last slice is capped by the remaining amount.
+	progress := ice.Progress()
+	test.Assert(t, "amounts", []string{"4", "4", "3"}, amounts)
+	test.Assert(t, "Filled", "8", progress.Filled.String())
+	test.Assert(t, "Slices", 3, progress.Slices)
+
+	visible := progress.Visible
+	ws.handleOrdersClosed(&Trade{
+		ID:         visible.ID,
+		CoinFilled: big.NewRat(1),
+		Status:     TradeStatusCancelled,
+	})
+
+	progress = ice.Wait()
+	test.Assert(t, "State", AlgoStateCancelled, progress.State)
+	test.Assert(t, "Filled", "9", progress.Filled.String())
+	test.Assert(t, "Remaining", "2", progress.Remaining.String())
+}
+
+func TestIcebergExecutor_earlyClose(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var ice *IcebergExecutor
+
+	// The first slice is filled and its closed notification delivered
+	// before the placement response.
+	var isEarly bool
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeAsk || isEarly {
+			return false
+		}
+		isEarly = true
+
+		err := req.ParseForm()
+		if err != nil {
+			t.Error(err)
+		}
+		order := ex.place(TradeTypeAsk, PairTokenomyIdk,
+			big.NewRat(req.Form.Get(ParamNameAmount)),
+			big.NewRat(req.Form.Get(ParamNamePrice)))
+		ice.HandleOrdersClosed(ex.close(order.ID, TradeStatusFilled))
+		ex.write(w, http.StatusOK, &TradeResponse{Order: order})
+		return true
+	}
+
+	var err error
+	ice, err = NewIcebergExecutor(IcebergOptions{
+		Client: cl,
+		Order: IcebergOrder{
+			Amount:        big.NewRat(4),
+			Price:         big.NewRat(100),
+			VisibleAmount: big.NewRat(2),
+			Pair:          PairTokenomyIdk,
+			Type:          TradeTypeAsk,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = ice.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress := ice.Progress()
+	test.Assert(t, "Filled", "2", progress.Filled.String())
+	test.Assert(t, "Slices", 2, progress.Slices)
+
+	ice.HandleOrdersClosed(ex.close(progress.Visible.ID, TradeStatusFilled))
+
+	progress = ice.Wait()
+	test.Assert(t, "State", AlgoStateDone, progress.State)
+	test.Assert(t, "Filled", "4", progress.Filled.String())
+}
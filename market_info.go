@@ -22,3 +22,42 @@ type MarketInfo struct {
 
 	IsActive bool `json:"is_active"`
 }
+
+// RoundAmount return the copy of amount rounded down to the
+// AmountPrecision.
+// If the AmountPrecision is zero or less, the amount is not rounded.
+func (info *MarketInfo) RoundAmount(amount *big.Rat) *big.Rat {
+	if amount == nil {
+		return nil
+	}
+	amount = big.NewRat(amount)
+	if info.AmountPrecision > 0 {
+		amount.RoundToZero(info.AmountPrecision)
+	}
+	return amount
+}
+
+// RoundPrice return the copy of price rounded to the nearest
+// PricePrecision.
+// If the PricePrecision is zero or less, the price is not rounded.
+func (info *MarketInfo) RoundPrice(price *big.Rat) *big.Rat {
+	if price == nil {
+		return nil
+	}
+	price = big.NewRat(price)
+	if info.PricePrecision > 0 {
+		price.RoundToNearestAway(info.PricePrecision)
+	}
+	return price
+}
+
+// PriceTick return the smallest price increment derived from
+// PricePrecision, for example 0.01 for PricePrecision 2, or 1 if the
+// PricePrecision is zero or less.
+func (info *MarketInfo) PriceTick() *big.Rat {
+	tick := big.NewRat(1)
+	for x := 0; x < info.PricePrecision; x++ {
+		tick.Quo(10)
+	}
+	return tick
+}
@@ -0,0 +1,648 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// DefaultInventoryInterval define the default interval where MarketMaker
+// refresh the inventory from UserInfo.
+const DefaultInventoryInterval = time.Minute
+
+// MarketQuoteHandler define a callback that will be called after the
+// quotes replaced.
+// The err parameter is non-nil if one of the orders in cancel-replace
+// failed.
+type MarketQuoteHandler func(quote MarketQuote, err error)
+
+// MarketMakerConfig define the quoting parameters of MarketMaker.
+type MarketMakerConfig struct {
+	// Spread, required, is the distance between bid and ask price in
+	// ratio of mid price, for example 0.004 for 0.4%.
+	Spread *big.Rat
+
+	// Amount, required, is the amount of coin on each quote.
+	Amount *big.Rat
+
+	// RequoteThreshold, required, is the minimum change of mid price,
+	// in ratio of the last quoted mid price, that replace the quotes.
+	RequoteThreshold *big.Rat
+
+	// TargetInventory, optional, is the amount of coin that we want to
+	// hold.
+	// Default to zero.
+	TargetInventory *big.Rat
+
+	// MaxInventoryDeviation, required, is the maximum distance between
+	// the inventory and TargetInventory.
+	// The quotes are skewed proportional to the distance: when the
+	// inventory is above the target, both prices are lowered to sell
+	// more; when the inventory is below the target, both prices are
+	// raised to buy more.
+	// Once the distance reach MaxInventoryDeviation, only one side is
+	// quoted.
+	MaxInventoryDeviation *big.Rat
+
+	Pair Pair
+}
+
+// MarketQuote contains the current quotes of MarketMaker.
+type MarketQuote struct {
+	// Bid and Ask contains the open quote orders, or nil if the side is
+	// not quoted.
+	Bid *Trade
+	Ask *Trade
+
+	// Mid contains the mid price when the quotes placed.
+	Mid *big.Rat
+
+	// Inventory contains the amount of coin used to skew the quotes.
+	Inventory *big.Rat
+}
+
+// MarketMakerOptions define the options for MarketMaker.
+type MarketMakerOptions struct {
+	// Client, required, is the REST client used to fetch the book and
+	// inventory, and to place the quotes.
+	// If the Client Markets is set, the quote prices are rounded to
+	// the PricePrecision.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The handler is registered using AddOrdersClosedHandler.
+	WebSocket *WebSocketPrivate
+
+	// Public, optional, is the public WebSocket where the depths
+	// consumed using NotifyDepths, if the Start is called with nil
+	// notification channel.
+	Public *WebSocketPublic
+
+	// HandleQuote, optional, is the callback that will be called after
+	// the quotes replaced.
+	HandleQuote MarketQuoteHandler
+
+	Config MarketMakerConfig
+
+	// InventoryInterval, optional, define the interval to refresh the
+	// inventory from UserInfo.
+	// Default to DefaultInventoryInterval.
+	InventoryInterval time.Duration
+}
+
+// MarketMaker maintain the post-only bid and ask quotes around the mid
+// price of local order book.
+//
+// The quotes are replaced, by cancelling and placing them in single
+// TradeBulk request, when the mid price move beyond the RequoteThreshold,
+// when the inventory changes, or when one of the quotes closed.
+// The replaced quote that can not be cancelled is cancelled again on the
+// next replacement.
+type MarketMaker struct {
+	opts MarketMakerOptions
+	book *OrderBook
+
+	quote     MarketQuote
+	inventory *big.Rat
+
+	// early contains the closed orders received while the quotes being
+	// replaced, before their ID known.
+	early map[int64]*Trade
+
+	// pending contains the replaced quotes that can not be cancelled,
+	// to be cancelled again on the next replacement.
+	pending []*Trade
+
+	done chan struct{}
+
+	// removeNotif remove the depths channel from Public, if its used.
+	removeNotif func()
+
+	locker sync.Mutex
+
+	// quoteLocker serialize the quotes replacement.
+	quoteLocker sync.Mutex
+
+	isReplacing bool
+	isStale     bool
+	isRunning   bool
+}
+
+// NewMarketMaker create and validate new MarketMaker.
+// The quoting is not running until Start is called.
+func NewMarketMaker(opts MarketMakerOptions) (mm *MarketMaker, err error) {
+	logp := "NewMarketMaker"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+
+	cfg := opts.Config
+	err = cfg.Pair.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+	if cfg.Spread == nil || !cfg.Spread.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: invalid Spread", logp)
+	}
+	if cfg.Amount == nil || !cfg.Amount.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidAmount)
+	}
+	if cfg.RequoteThreshold == nil || cfg.RequoteThreshold.IsLessThanZero() {
+		return nil, fmt.Errorf("%s: invalid RequoteThreshold", logp)
+	}
+	if cfg.MaxInventoryDeviation == nil || !cfg.MaxInventoryDeviation.IsGreaterThanZero() {
+		return nil, fmt.Errorf("%s: invalid MaxInventoryDeviation", logp)
+	}
+	if opts.InventoryInterval <= 0 {
+		opts.InventoryInterval = DefaultInventoryInterval
+	}
+
+	mm = &MarketMaker{
+		opts:      opts,
+		book:      NewOrderBook(cfg.Pair),
+		inventory: big.NewRat(0),
+		early:     make(map[int64]*Trade),
+		isStale:   true,
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(mm.HandleOrdersClosed)
+	}
+	return mm, nil
+}
+
+// Book return the local order book.
+func (mm *MarketMaker) Book() *OrderBook {
+	return mm.book
+}
+
+// HandleDepths update the local order book with the depths broadcast and
+// replace the quotes if needed.
+// The Start method call it automatically for each depths in notification
+// channel.
+func (mm *MarketMaker) HandleDepths(depths *MarketDepths) {
+	mm.book.Update(depths)
+	err := mm.Requote(false)
+	if err != nil {
+		log.Printf("MarketMaker: %s", err)
+	}
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate.
+// If the quote filled, the inventory is updated by its amount and the
+// quotes are replaced on the next depths update.
+// If the NewMarketMaker is created with WebSocket options, this method is
+// registered automatically.
+func (mm *MarketMaker) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+
+	mm.locker.Lock()
+	defer mm.locker.Unlock()
+
+	mm.closeQuote(trade)
+}
+
+// isOrderNotFound return true if the err is the server error with status
+// code 404.
+func isOrderNotFound(err error) bool {
+	var errServer *liberrors.E
+	if !errors.As(err, &errServer) {
+		return false
+	}
+	return errServer.Code == http.StatusNotFound
+}
+
+// closeQuote update the inventory from the closed quote.
+// It must be called while holding the lock.
+func (mm *MarketMaker) closeQuote(trade *Trade) {
+	var quote *Trade
+	switch {
+	case mm.quote.Bid != nil && mm.quote.Bid.ID == trade.ID:
+		quote = mm.quote.Bid
+		mm.quote.Bid = nil
+	case mm.quote.Ask != nil && mm.quote.Ask.ID == trade.ID:
+		quote = mm.quote.Ask
+		mm.quote.Ask = nil
+	}
+	for x := 0; quote == nil && x < len(mm.pending); x++ {
+		if mm.pending[x].ID == trade.ID {
+			quote = mm.pending[x]
+			mm.pending = append(mm.pending[:x], mm.pending[x+1:]...)
+		}
+	}
+	if quote == nil {
+		if mm.isReplacing {
+			// The new quote may be closed before the TradeBulk
+			// response received.
+			mm.early[trade.ID] = trade
+		}
+		return
+	}
+
+	filled := trade.CoinFilled
+	if trade.Status == TradeStatusFilled {
+		filled = quote.CoinAmount
+	}
+	if filled != nil {
+		if quote.Type == TradeTypeBid {
+			mm.inventory.Add(filled)
+		} else {
+			mm.inventory.Sub(filled)
+		}
+	}
+	mm.isStale = true
+}
+
+// Quote return the current quotes.
+func (mm *MarketMaker) Quote() (quote MarketQuote) {
+	mm.locker.Lock()
+	defer mm.locker.Unlock()
+
+	quote = mm.quote
+	quote.Inventory = big.NewRat(mm.inventory)
+	return quote
+}
+
+// RefreshInventory set the inventory from the coin balances, including
+// the frozen balances, in UserInfo.
+// The quotes are replaced on the next depths update if the inventory
+// changes.
+func (mm *MarketMaker) RefreshInventory() (err error) {
+	user, err := mm.opts.Client.UserInfo()
+	if err != nil {
+		return fmt.Errorf("RefreshInventory: %w", err)
+	}
+	if user == nil || user.UserAssets == nil {
+		return errors.New("RefreshInventory: empty user assets")
+	}
+
+	coin := mm.opts.Config.Pair.Coin().String()
+	inventory := big.AddRat(user.Balances[coin], user.FrozenBalances[coin])
+
+	mm.locker.Lock()
+	if !inventory.IsEqual(mm.inventory) {
+		mm.inventory = inventory
+		mm.isStale = true
+	}
+	mm.locker.Unlock()
+	return nil
+}
+
+// Requote replace the quotes if the mid price move beyond the
+// RequoteThreshold since the last quotes, if the quotes are stale, or if
+// force is true.
+// It does nothing if the MarketMaker is not running.
+func (mm *MarketMaker) Requote(force bool) (err error) {
+	mid := mm.book.Mid()
+	if mid == nil {
+		return nil
+	}
+
+	mm.quoteLocker.Lock()
+	defer mm.quoteLocker.Unlock()
+
+	mm.locker.Lock()
+	if !mm.isRunning {
+		mm.locker.Unlock()
+		return nil
+	}
+	if !force && !mm.isStale && !mm.isMoved(mid) {
+		mm.locker.Unlock()
+		return nil
+	}
+	bid, ask := mm.prices(mid)
+	mm.locker.Unlock()
+
+	err = mm.replace(bid, ask)
+
+	mm.locker.Lock()
+	mm.quote.Mid = mid
+	quote := mm.quote
+	quote.Inventory = big.NewRat(mm.inventory)
+	mm.locker.Unlock()
+
+	if mm.opts.HandleQuote != nil {
+		mm.opts.HandleQuote(quote, err)
+	}
+	if err != nil {
+		return fmt.Errorf("Requote: %w", err)
+	}
+	return nil
+}
+
+// Start the quoting in the background.
+// The book is initialized from MarketDepths and the inventory from
+// UserInfo, and then the book is updated from the notif channel, usually
+// the channel from NotifyDepths of WebSocketPublic subscribed to the pair.
+// If notif is nil, the depths of pair is subscribed and consumed from the
+// Public options.
+// Calling Start on running MarketMaker has no effect.
+func (mm *MarketMaker) Start(notif <-chan MarketDepths) (err error) {
+	logp := "Start"
+
+	mm.locker.Lock()
+	isRunning := mm.isRunning
+	mm.locker.Unlock()
+	if isRunning {
+		return nil
+	}
+
+	var removeNotif func()
+	if notif == nil {
+		if mm.opts.Public == nil {
+			return fmt.Errorf("%s: empty notif and Public", logp)
+		}
+		_, err = mm.opts.Public.SubscribeDepths([]string{mm.opts.Config.Pair.String()})
+		if err != nil {
+			return fmt.Errorf("%s: %w", logp, err)
+		}
+		notif, removeNotif = mm.opts.Public.NotifyDepths()
+	}
+
+	depths, err := mm.opts.Client.MarketDepths(mm.opts.Config.Pair.String())
+	if err == nil {
+		mm.book.Reset(depths)
+		err = mm.RefreshInventory()
+	}
+	if err != nil {
+		if removeNotif != nil {
+			removeNotif()
+		}
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	mm.locker.Lock()
+	mm.isRunning = true
+	mm.done = make(chan struct{})
+	mm.removeNotif = removeNotif
+	go mm.run(mm.done, notif)
+	mm.locker.Unlock()
+
+	err = mm.Requote(true)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	return nil
+}
+
+// Stop the quoting and cancel the open quotes.
+func (mm *MarketMaker) Stop() (err error) {
+	mm.locker.Lock()
+	if mm.isRunning {
+		close(mm.done)
+		if mm.removeNotif != nil {
+			mm.removeNotif()
+			mm.removeNotif = nil
+		}
+		mm.isRunning = false
+	}
+	// Force the quotes placed on the next Start.
+	mm.quote.Mid = nil
+	mm.locker.Unlock()
+
+	// Wait for the quotes being replaced.
+	mm.quoteLocker.Lock()
+	defer mm.quoteLocker.Unlock()
+
+	err = mm.replace(nil, nil)
+	if err != nil {
+		return fmt.Errorf("Stop: %w", err)
+	}
+	return nil
+}
+
+func (mm *MarketMaker) run(done chan struct{}, notif <-chan MarketDepths) {
+	ticker := time.NewTicker(mm.opts.InventoryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case depths := <-notif:
+			mm.HandleDepths(&depths)
+		case <-ticker.C:
+			err := mm.RefreshInventory()
+			if err != nil {
+				log.Printf("MarketMaker: %s", err)
+			}
+		}
+	}
+}
+
+// isMoved return true if the mid price move beyond RequoteThreshold from
+// the last quotes.
+// It must be called while holding the lock.
+func (mm *MarketMaker) isMoved(mid *big.Rat) bool {
+	last := mm.quote.Mid
+	if last == nil || !last.IsGreaterThanZero() {
+		return true
+	}
+	diff := big.SubRat(mid, last)
+	if diff.IsLessThanZero() {
+		diff = big.SubRat(last, mid)
+	}
+	diff.Quo(last)
+	return !diff.IsLess(mm.opts.Config.RequoteThreshold)
+}
+
+// prices compute the bid and ask price around the mid, skewed by the
+// inventory.
+// The price is nil if the side should not be quoted.
+// It must be called while holding the lock.
+func (mm *MarketMaker) prices(mid *big.Rat) (bid, ask *big.Rat) {
+	cfg := mm.opts.Config
+
+	half := big.MulRat(mid, cfg.Spread)
+	half.Quo(2)
+
+	// skew = (inventory - target) / deviation, in range [-1, 1].
+	skew := big.SubRat(mm.inventory, cfg.TargetInventory)
+	skew.Quo(cfg.MaxInventoryDeviation)
+	if skew.IsGreater(1) {
+		skew = big.NewRat(1)
+	} else if skew.IsLess(-1) {
+		skew = big.NewRat(-1)
+	}
+	shift := big.MulRat(half, skew)
+
+	if skew.IsLess(1) {
+		bid = big.SubRat(mid, half)
+		bid.Sub(shift)
+	}
+	if skew.IsGreater(-1) {
+		ask = big.AddRat(mid, half)
+		ask.Sub(shift)
+	}
+
+	if mm.opts.Client.Markets != nil {
+		info := mm.opts.Client.Markets.Get(cfg.Pair)
+		if info != nil {
+			bid = info.RoundPrice(bid)
+			ask = info.RoundPrice(ask)
+		}
+	}
+	return bid, ask
+}
+
+// replace cancel the current quotes and place the new quotes in single
+// TradeBulk request.
+// The quote with nil price is not placed.
+// It must be called while holding the quoteLocker, but not the lock, so
+// the closed quotes can be consumed while the TradeBulk being sent.
+func (mm *MarketMaker) replace(bid, ask *big.Rat) (err error) {
+	cfg := mm.opts.Config
+
+	var (
+		tbReq = &TradeBulk{
+			Pair: cfg.Pair,
+		}
+		quotes []*Trade
+	)
+
+	mm.locker.Lock()
+	current := []*Trade{mm.quote.Bid, mm.quote.Ask}
+	current = append(current, mm.pending...)
+	mm.locker.Unlock()
+
+	for _, quote := range current {
+		if quote == nil {
+			continue
+		}
+		tbReq.Cancel = append(tbReq.Cancel, &BulkOrderItem{
+			TradeRequest: TradeRequest{
+				Pair: cfg.Pair,
+				Type: quote.Type,
+			},
+			ID: quote.ID,
+		})
+	}
+	for _, side := range []struct {
+		price *big.Rat
+		kind  string
+	}{{bid, TradeTypeBid}, {ask, TradeTypeAsk}} {
+		if side.price == nil || !side.price.IsGreaterThanZero() {
+			continue
+		}
+		ob := NewOrderBuilder(cfg.Pair).
+			Limit(side.price).
+			Amount(cfg.Amount).
+			PostOnly()
+		if side.kind == TradeTypeAsk {
+			ob.Ask()
+		} else {
+			ob.Bid()
+		}
+		item, err := ob.BuildBulkItem()
+		if err != nil {
+			return err
+		}
+		tbReq.Orders = append(tbReq.Orders, item)
+		quotes = append(quotes, &Trade{
+			Price:      side.price,
+			CoinAmount: cfg.Amount,
+			Pair:       cfg.Pair.String(),
+			Type:       side.kind,
+			Method:     TradeMethodLimit,
+		})
+	}
+	if len(tbReq.Orders) == 0 && len(tbReq.Cancel) == 0 {
+		return nil
+	}
+
+	mm.locker.Lock()
+	mm.isReplacing = true
+	mm.locker.Unlock()
+
+	tbRes, err := mm.opts.Client.TradeBulk(tbReq)
+	if err == nil && tbRes == nil {
+		err = errors.New("empty TradeBulk response")
+	}
+
+	mm.locker.Lock()
+	defer mm.locker.Unlock()
+
+	mm.isReplacing = false
+	early := mm.early
+	mm.early = make(map[int64]*Trade)
+
+	if err != nil {
+		// The state of quotes is unknown, keep them to be
+		// cancelled on the next call.
+		mm.isStale = true
+		return err
+	}
+
+	var (
+		errs    []string
+		pending []*Trade
+	)
+	for _, itemResult := range mapBulkItems(tbReq.Cancel, tbRes.Cancel, nil) {
+		if itemResult.Err == nil {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("cancel %d: %s",
+			itemResult.Request.ID, itemResult.Err))
+		if isOrderNotFound(itemResult.Err) {
+			// The quote has been closed; the closed broadcast,
+			// if any, is ignored since the quote is replaced.
+			continue
+		}
+		// The quote may be still open, keep it to be cancelled on
+		// the next call, and to update the inventory if its closed
+		// meanwhile.
+		for _, quote := range current {
+			if quote != nil && quote.ID == itemResult.Request.ID {
+				pending = append(pending, quote)
+				break
+			}
+		}
+	}
+	mm.pending = pending
+	mm.quote.Bid = nil
+	mm.quote.Ask = nil
+	for x, itemResult := range mapBulkItems(tbReq.Orders, tbRes.Orders, nil) {
+		quote := quotes[x]
+		if itemResult.Err != nil {
+			errs = append(errs, fmt.Sprintf("%s %s: %s", quote.Type,
+				quote.Price, itemResult.Err))
+			continue
+		}
+		quote.ID = itemResult.ID
+		if quote.Type == TradeTypeBid {
+			mm.quote.Bid = quote
+		} else {
+			mm.quote.Ask = quote
+		}
+	}
+	mm.isStale = len(errs) > 0
+	for _, quote := range []*Trade{mm.quote.Bid, mm.quote.Ask} {
+		if quote == nil {
+			continue
+		}
+		trade := early[quote.ID]
+		if trade != nil {
+			mm.closeQuote(trade)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
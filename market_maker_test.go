@@ -0,0 +1,221 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestMarketMaker(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var requotes int
+	mm, err := NewMarketMaker(MarketMakerOptions{
+		Client: cl,
+		Config: MarketMakerConfig{
+			Spread:                big.NewRat("0.04"),
+			Amount:                big.NewRat(5),
+			RequoteThreshold:      big.NewRat("0.01"),
+			MaxInventoryDeviation: big.NewRat(10),
+			Pair:                  PairTokenomyIdk,
+		},
+		HandleQuote: func(quote MarketQuote, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			requotes++
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prices := func() (bid, ask string) {
+		quote := mm.Quote()
+		if quote.Bid != nil {
+			bid = quote.Bid.Price.String()
+		}
+		if quote.Ask != nil {
+			ask = quote.Ask.Price.String()
+		}
+		return bid, ask
+	}
+
+	// The quoting is tested without Start.
+	mm.isRunning = true
+	mm.done = make(chan struct{})
+
+	mm.Book().Reset(&MarketDepths{
+		Asks: []*Depth{{Price: big.NewRat(102), TotalCoin: big.NewRat(1)}},
+		Bids: []*Depth{{Price: big.NewRat(98), TotalCoin: big.NewRat(1)}},
+	})
+	err = mm.Requote(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bid, ask := prices()
+	test.Assert(t, "bid", "98", bid)
+	test.Assert(t, "ask", "102", ask)
+
+	// The mid move less than threshold.
+	mm.HandleDepths(&MarketDepths{
+		Bids: []*Depth{{Price: big.NewRat("98.5"), TotalCoin: big.NewRat(1)}},
+	})
+	test.Assert(t, "requotes", 1, requotes)
+
+	// The bid quote filled, the quotes skewed down.
+	mm.HandleOrdersClosed(ex.close(mm.Quote().Bid.ID, TradeStatusFilled))
+	mm.HandleDepths(&MarketDepths{
+		Bids: []*Depth{
+			{Price: big.NewRat("98.5"), TotalCoin: big.NewRat(0)},
+			{Price: big.NewRat(98), TotalCoin: big.NewRat(1)},
+		},
+	})
+	test.Assert(t, "requotes", 2, requotes)
+	bid, ask = prices()
+	test.Assert(t, "skewed bid", "97", bid)
+	test.Assert(t, "skewed ask", "101", ask)
+	test.Assert(t, "Inventory", "5", mm.Quote().Inventory.String())
+
+	// The inventory reach the maximum deviation, only ask is quoted.
+	closed := ex.close(mm.Quote().Bid.ID, TradeStatusCancelled)
+	closed.CoinFilled = big.NewRat(5)
+	mm.HandleOrdersClosed(closed)
+	err = mm.Requote(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bid, ask = prices()
+	test.Assert(t, "max bid", "", bid)
+	test.Assert(t, "max ask", "100", ask)
+
+	// The ask quote filled before the TradeBulk response received.
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeBulk {
+			return false
+		}
+		rec := httptest.NewRecorder()
+		ex.serveBulk(rec, req)
+
+		ex.locker.Lock()
+		lastID := ex.lastID
+		ex.locker.Unlock()
+		mm.HandleOrdersClosed(ex.close(lastID, TradeStatusFilled))
+
+		w.WriteHeader(rec.Code)
+		_, _ = w.Write(rec.Body.Bytes())
+		return true
+	}
+	err = mm.Requote(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex.handle = nil
+	test.Assert(t, "early filled ask", true, mm.Quote().Ask == nil)
+	test.Assert(t, "Inventory", "5", mm.Quote().Inventory.String())
+
+	err = mm.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bid, ask = prices()
+	test.Assert(t, "stopped", "", bid+ask)
+	test.Assert(t, "open orders after Stop", 0, len(ex.open()))
+
+	// The stopped MarketMaker does not quote.
+	err = mm.Requote(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Requote after Stop", 0, len(ex.open()))
+	test.Assert(t, "Mid after Stop", true, mm.Quote().Mid == nil)
+}
+
+func TestMarketMaker_cancelFailed(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	mm, err := NewMarketMaker(MarketMakerOptions{
+		Client: cl,
+		Config: MarketMakerConfig{
+			Spread:                big.NewRat("0.04"),
+			Amount:                big.NewRat(5),
+			RequoteThreshold:      big.NewRat("0.01"),
+			MaxInventoryDeviation: big.NewRat(100),
+			Pair:                  PairTokenomyIdk,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The quoting is tested without Start.
+	mm.isRunning = true
+	mm.done = make(chan struct{})
+
+	mm.Book().Reset(&MarketDepths{
+		Asks: []*Depth{{Price: big.NewRat(102), TotalCoin: big.NewRat(1)}},
+		Bids: []*Depth{{Price: big.NewRat(98), TotalCoin: big.NewRat(1)}},
+	})
+	err = mm.Requote(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := mm.Quote()
+
+	// The cancel of both quotes failed, the new quotes are placed.
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeBulk {
+			return false
+		}
+		var tbReq TradeBulk
+		err := json.NewDecoder(req.Body).Decode(&tbReq)
+		if err != nil {
+			t.Error(err)
+		}
+		tbRes := &TradeBulk{Pair: tbReq.Pair}
+		for _, item := range tbReq.Cancel {
+			resItem := &BulkOrderItem{ID: item.ID, RefID: item.RefID}
+			resItem.Code = http.StatusInternalServerError
+			resItem.Message = "internal error"
+			tbRes.Cancel = append(tbRes.Cancel, resItem)
+		}
+		for _, item := range tbReq.Orders {
+			order := ex.place(item.Type, tbReq.Pair, item.Amount, item.Price)
+			resItem := &BulkOrderItem{ID: order.ID, RefID: item.RefID}
+			resItem.Code = http.StatusOK
+			tbRes.Orders = append(tbRes.Orders, resItem)
+		}
+		ex.write(w, http.StatusOK, tbRes)
+		return true
+	}
+	err = mm.Requote(true)
+	if err == nil {
+		t.Fatal("want error on failed cancel")
+	}
+	ex.handle = nil
+	test.Assert(t, "open orders", 4, len(ex.open()))
+	test.Assert(t, "pending", 2, len(mm.pending))
+
+	// The pending quote still update the inventory when filled.
+	mm.HandleOrdersClosed(ex.close(first.Bid.ID, TradeStatusFilled))
+	test.Assert(t, "pending after filled", 1, len(mm.pending))
+	test.Assert(t, "Inventory", "5", mm.Quote().Inventory.String())
+
+	// The pending quote is cancelled again on the next requote, and
+	// the quote that is not found is not kept.
+	ex.close(mm.Quote().Ask.ID, TradeStatusFilled)
+	err = mm.Requote(false)
+	if err == nil {
+		t.Fatal("want error on not found quote")
+	}
+	test.Assert(t, "pending after requote", 0, len(mm.pending))
+	test.Assert(t, "open orders after requote", 2, len(ex.open()))
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MarketRegistry contains the list of pairs and their market information,
+// usually loaded from MarketInfo.
+type MarketRegistry struct {
+	infos  map[Pair]*MarketInfo
+	locker sync.RWMutex
+}
+
+// NewMarketRegistry create new registry from list of MarketInfo.
+// The MarketInfo with invalid pair name is ignored.
+func NewMarketRegistry(marketInfos []MarketInfo) (reg *MarketRegistry) {
+	reg = &MarketRegistry{}
+	reg.Set(marketInfos)
+	return reg
+}
+
+// Get the market information by pair.
+// It will return nil if the pair is not registered.
+func (reg *MarketRegistry) Get(pair Pair) (info *MarketInfo) {
+	reg.locker.RLock()
+	info = reg.infos[pair]
+	reg.locker.RUnlock()
+	return info
+}
+
+// Pairs return the list of registered pairs, sorted by name.
+func (reg *MarketRegistry) Pairs() (pairs []Pair) {
+	reg.locker.RLock()
+	pairs = make([]Pair, 0, len(reg.infos))
+	for pair := range reg.infos {
+		pairs = append(pairs, pair)
+	}
+	reg.locker.RUnlock()
+
+	sort.Slice(pairs, func(x, y int) bool {
+		return pairs[x] < pairs[y]
+	})
+	return pairs
+}
+
+// ParsePair parse the pair name and validate it against the registry.
+func (reg *MarketRegistry) ParsePair(name string) (pair Pair, err error) {
+	pair, err = ParsePair(name)
+	if err != nil {
+		return "", err
+	}
+	err = reg.Validate(pair)
+	if err != nil {
+		return "", err
+	}
+	return pair, nil
+}
+
+// Set replace the registry content with new list of MarketInfo.
+func (reg *MarketRegistry) Set(marketInfos []MarketInfo) {
+	infos := make(map[Pair]*MarketInfo, len(marketInfos))
+	for x := range marketInfos {
+		info := marketInfos[x]
+		name := info.Pair
+		if len(name) == 0 {
+			name = info.Symbol
+		}
+		pair, err := ParsePair(name)
+		if err != nil {
+			continue
+		}
+		infos[pair] = &info
+	}
+
+	reg.locker.Lock()
+	reg.infos = infos
+	reg.locker.Unlock()
+}
+
+// Validate the pair format and check that the pair is registered and active.
+func (reg *MarketRegistry) Validate(pair Pair) (err error) {
+	err = pair.Validate()
+	if err != nil {
+		return err
+	}
+	info := reg.Get(pair)
+	if info == nil {
+		return fmt.Errorf("%w: unknown pair %q", ErrInvalidPair, pair)
+	}
+	if !info.IsActive {
+		return fmt.Errorf("%w: inactive pair %q", ErrInvalidPair, pair)
+	}
+	return nil
+}
@@ -0,0 +1,708 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of OCOGroup states.
+//
+// The OCOStateStopping is set when the stop triggered and the take-profit
+// order is being cancelled.
+// The group stay in this state, with its stop re-armed, if the stop order
+// can not be placed.
+const (
+	OCOStateActive     = "active"
+	OCOStateStopping   = "stopping"
+	OCOStateTakeProfit = "take-profit"
+	OCOStateStop       = "stop"
+	OCOStateCancelled  = "cancelled"
+)
+
+// DefaultOCOPollInterval define the default interval where OCOManager poll
+// the take-profit order.
+const DefaultOCOPollInterval = 5 * time.Second
+
+// OCOHandler define a callback that will be called when the OCO group
+// completed, either by take-profit, by stop, or cancelled.
+// The err parameter is non-nil if the other leg can not be cancelled.
+type OCOHandler func(group OCOGroup, err error)
+
+// OCOOrder define the parameters to place new one-cancels-other group.
+type OCOOrder struct {
+	// Amount, required, is the amount of coin to be traded.
+	Amount *big.Rat `json:"amount"`
+
+	// TakeProfitPrice, required, is the price of take-profit limit
+	// order.
+	TakeProfitPrice *big.Rat `json:"take_profit_price"`
+
+	// StopPrice, required, is the price that trigger the stop order.
+	StopPrice *big.Rat `json:"stop_price"`
+
+	// StopLimitPrice, optional, is the price of stop order.
+	// If its nil, the stop order is placed as market order.
+	StopLimitPrice *big.Rat `json:"stop_limit_price,omitempty"`
+
+	// ID, optional, is the ID of group.
+	// If its empty, it will be generated.
+	ID string `json:"id"`
+
+	Pair Pair `json:"pair"`
+
+	// Type of both orders, its either "sell" to close the long position
+	// or "buy" to close the short position.
+	Type string `json:"type"`
+}
+
+// OCOGroup contains the state of one-cancels-other group.
+type OCOGroup struct {
+	// Filled contains the amount of take-profit order that has been
+	// filled.
+	Filled *big.Rat `json:"filled"`
+
+	// Order contains the parameters of group.
+	Order OCOOrder `json:"order"`
+
+	// State of group, one of the OCOState constants.
+	State string `json:"state"`
+
+	// StopID contains the ID of StopTrigger in TriggerEngine.
+	StopID string `json:"stop_id"`
+
+	// TakeProfitID contains the ID of take-profit order.
+	TakeProfitID int64 `json:"take_profit_id"`
+
+	// StopOrderID contains the ID of stop order after its triggered.
+	StopOrderID int64 `json:"stop_order_id,omitempty"`
+}
+
+// OCOManagerOptions define the options for OCOManager.
+type OCOManagerOptions struct {
+	// Client, required, is the REST client used to place and cancel the
+	// take-profit order.
+	Client *Client
+
+	// Triggers, required, is the engine where the stop leg is armed.
+	Triggers *TriggerEngine
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The handler is registered using AddOrdersClosedHandler.
+	WebSocket *WebSocketPrivate
+
+	// HandleDone, optional, is the callback that will be called when
+	// the group completed.
+	HandleDone OCOHandler
+
+	// Path, optional, is the file where the active groups are saved,
+	// so they are restored after restart.
+	// It should be set if the Triggers Path is set, otherwise the stop
+	// restored by TriggerEngine is never fired.
+	Path string
+
+	// PollInterval, optional, define the interval to poll the
+	// take-profit order using UserOrderInfo, to detect partial fills.
+	// Default to DefaultOCOPollInterval.
+	PollInterval time.Duration
+}
+
+// OCOManager manage the one-cancels-other groups.
+//
+// Each group contains a take-profit limit order placed on the market and a
+// client-side stop armed in TriggerEngine.
+// When the take-profit order filled, the stop is disarmed.
+// When the stop triggered, the take-profit order is cancelled first, and
+// then the stop order is placed for the amount that is not filled by the
+// take-profit, or skipped if nothing left.
+// The partial fills on take-profit order, detected by polling
+// UserOrderInfo, resize the stop order to the remaining amount.
+type OCOManager struct {
+	opts OCOManagerOptions
+
+	groups map[string]*OCOGroup
+	byTP   map[int64]*OCOGroup
+
+	// unwatch contains the function to unregister the stop
+	// interceptor and subscriber, by group ID.
+	unwatch map[string]func()
+
+	done chan struct{}
+
+	locker sync.Mutex
+
+	isRunning bool
+}
+
+// NewOCOManager create and initialize new OCOManager.
+// If the Path options is set, the active groups are loaded from it.
+// The polling is not running until Start is called.
+func NewOCOManager(opts OCOManagerOptions) (ocom *OCOManager, err error) {
+	logp := "NewOCOManager"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if opts.Triggers == nil {
+		return nil, fmt.Errorf("%s: empty Triggers", logp)
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultOCOPollInterval
+	}
+
+	ocom = &OCOManager{
+		opts:    opts,
+		groups:  make(map[string]*OCOGroup),
+		byTP:    make(map[int64]*OCOGroup),
+		unwatch: make(map[string]func()),
+	}
+
+	err = ocom.load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(ocom.HandleOrdersClosed)
+	}
+	return ocom, nil
+}
+
+// Place new OCO group: place the take-profit limit order and arm the stop
+// for the remaining amount.
+//
+// If the stop can not be armed, the take-profit order is cancelled.
+func (ocom *OCOManager) Place(order OCOOrder) (group OCOGroup, err error) {
+	logp := "Place"
+
+	if order.Amount == nil || !order.Amount.IsGreaterThanZero() {
+		return group, fmt.Errorf("%s: %w", logp, ErrInvalidAmount)
+	}
+	if len(order.ID) == 0 {
+		order.ID = NewClientOrderID()
+	}
+
+	ob := NewOrderBuilder(order.Pair).
+		Limit(order.TakeProfitPrice).
+		Amount(order.Amount).
+		ClientOrderID(order.ID + "-tp")
+	if order.Type == TradeTypeAsk {
+		ob.Ask()
+	} else {
+		ob.Bid()
+	}
+	treqTP, err := ob.Build()
+	if err != nil {
+		return group, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	stop := StopTrigger{
+		StopPrice: order.StopPrice,
+		Request: TradeRequest{
+			Pair:   order.Pair,
+			Type:   order.Type,
+			Method: TradeMethodMarket,
+		},
+		ID:   order.ID + "-stop",
+		Kind: StopKindStopLoss,
+	}
+	if order.StopLimitPrice != nil {
+		stop.Request.Method = TradeMethodLimit
+		stop.Request.Price = order.StopLimitPrice
+	}
+
+	var tres *TradeResponse
+	if order.Type == TradeTypeAsk {
+		tres, err = ocom.opts.Client.TradeAsk(treqTP)
+	} else {
+		tres, err = ocom.opts.Client.TradeBid(treqTP)
+	}
+	if err != nil {
+		return group, fmt.Errorf("%s: take-profit: %w", logp, err)
+	}
+	if tres == nil || tres.Order == nil {
+		return group, fmt.Errorf("%s: take-profit: empty order in response", logp)
+	}
+
+	p := &OCOGroup{
+		Filled:       big.NewRat(0),
+		Order:        order,
+		State:        OCOStateActive,
+		StopID:       stop.ID,
+		TakeProfitID: tres.Order.ID,
+	}
+	if tres.Order.CoinFilled != nil {
+		p.Filled = big.NewRat(tres.Order.CoinFilled)
+	}
+	if tres.Order.Status == TradeStatusFilled || !p.Filled.IsLess(order.Amount) {
+		p.State = OCOStateTakeProfit
+		return *p, nil
+	}
+
+	ocom.locker.Lock()
+	ocom.groups[order.ID] = p
+	ocom.byTP[p.TakeProfitID] = p
+	err = ocom.save()
+	ocom.locker.Unlock()
+	if err != nil {
+		return group, ocom.rollback(logp, p, err)
+	}
+
+	ocom.watch(p)
+
+	stop.Request.Amount = big.SubRat(order.Amount, p.Filled)
+	stop.IsIntercepted = true
+	_, err = ocom.opts.Triggers.Arm(stop)
+	if err != nil {
+		return group, ocom.rollback(logp, p, fmt.Errorf("stop: %w", err))
+	}
+
+	ocom.locker.Lock()
+	group = *p
+	ocom.locker.Unlock()
+	return group, nil
+}
+
+// rollback remove the group that can not be placed and cancel its
+// take-profit order.
+func (ocom *OCOManager) rollback(logp string, p *OCOGroup, err error) error {
+	ocom.remove(p)
+	_, errCancel := ocom.opts.Client.TradeCancel(ocom.takeProfit(p))
+	if errCancel != nil {
+		return fmt.Errorf("%s: %s; cancel take-profit: %w", logp, err,
+			errCancel)
+	}
+	return fmt.Errorf("%s: %w", logp, err)
+}
+
+// watch register the interceptor and subscriber of the group stop in
+// TriggerEngine.
+// They are unregistered when the group removed.
+func (ocom *OCOManager) watch(p *OCOGroup) {
+	id := p.Order.ID
+
+	removeInterceptor := ocom.opts.Triggers.Intercept(p.StopID,
+		func(trigger StopTrigger) (*TradeRequest, error) {
+			return ocom.beforeStop(id, trigger)
+		})
+	unsubscribe := ocom.opts.Triggers.Subscribe(p.StopID,
+		func(trigger StopTrigger, tres *TradeResponse, err error) {
+			ocom.handleStop(id, tres, err)
+		})
+
+	ocom.locker.Lock()
+	ocom.unwatch[id] = func() {
+		removeInterceptor()
+		unsubscribe()
+	}
+	ocom.locker.Unlock()
+}
+
+// Cancel the OCO group, by cancelling the take-profit order and disarming
+// the stop.
+//
+// The stop is disarmed first, so it can not fire while the take-profit
+// is being cancelled.
+// If the take-profit can not be cancelled, the stop is armed back and the
+// group is kept active.
+// If the stop has been fired, Cancel return an error.
+func (ocom *OCOManager) Cancel(id string) (err error) {
+	logp := "Cancel"
+
+	ocom.locker.Lock()
+	p := ocom.groups[id]
+	ocom.locker.Unlock()
+	if p == nil {
+		return fmt.Errorf("%s: unknown group %q", logp, id)
+	}
+
+	var (
+		stop    StopTrigger
+		isArmed bool
+	)
+	for _, trigger := range ocom.opts.Triggers.Triggers() {
+		if trigger.ID == p.StopID {
+			stop = trigger
+			isArmed = true
+			break
+		}
+	}
+	if isArmed {
+		isArmed, err = ocom.opts.Triggers.Disarm(p.StopID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", logp, err)
+		}
+	}
+	if !isArmed {
+		return fmt.Errorf("%s: %s: stop has been triggered", logp, id)
+	}
+
+	_, err = ocom.opts.Client.TradeCancel(ocom.takeProfit(p))
+	if err != nil {
+		_, errArm := ocom.opts.Triggers.Arm(stop)
+		if errArm != nil {
+			return fmt.Errorf("%s: %w; re-arm stop: %s", logp, err, errArm)
+		}
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	ocom.complete(p, OCOStateCancelled, nil)
+	return nil
+}
+
+// Get the OCO group by ID.
+func (ocom *OCOManager) Get(id string) (group OCOGroup, ok bool) {
+	ocom.locker.Lock()
+	defer ocom.locker.Unlock()
+
+	p := ocom.groups[id]
+	if p == nil {
+		return group, false
+	}
+	return *p, true
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate.
+// If the NewOCOManager is created with WebSocket options, this method is
+// registered automatically.
+func (ocom *OCOManager) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+	ocom.locker.Lock()
+	p := ocom.byTP[trade.ID]
+	ocom.locker.Unlock()
+	if p == nil {
+		return
+	}
+	ocom.updateTakeProfit(p, trade)
+}
+
+// Poll fetch the state of take-profit order on each active group using
+// UserOrderInfo.
+func (ocom *OCOManager) Poll() (err error) {
+	var (
+		groups []*OCOGroup
+		errs   []string
+	)
+
+	ocom.locker.Lock()
+	for _, p := range ocom.groups {
+		groups = append(groups, p)
+	}
+	ocom.locker.Unlock()
+
+	for _, p := range groups {
+		trade, err := ocom.opts.Client.UserOrderInfo(p.Order.Pair.String(),
+			p.TakeProfitID)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", p.Order.ID, err))
+			continue
+		}
+		ocom.updateTakeProfit(p, trade)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("Poll: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Start polling the take-profit orders in the background.
+// Calling Start on running OCOManager has no effect.
+func (ocom *OCOManager) Start() {
+	ocom.locker.Lock()
+	defer ocom.locker.Unlock()
+
+	if ocom.isRunning {
+		return
+	}
+	ocom.isRunning = true
+	ocom.done = make(chan struct{})
+
+	go ocom.run(ocom.done)
+}
+
+// Stop the polling.
+func (ocom *OCOManager) Stop() {
+	ocom.locker.Lock()
+	defer ocom.locker.Unlock()
+
+	if !ocom.isRunning {
+		return
+	}
+	close(ocom.done)
+	ocom.isRunning = false
+}
+
+func (ocom *OCOManager) run(done chan struct{}) {
+	ticker := time.NewTicker(ocom.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := ocom.Poll()
+			if err != nil {
+				log.Printf("OCOManager: %s", err)
+			}
+		}
+	}
+}
+
+// updateTakeProfit update the group based on the latest state of
+// take-profit order.
+func (ocom *OCOManager) updateTakeProfit(p *OCOGroup, trade *Trade) {
+	ocom.locker.Lock()
+	filled := ocom.updateFilled(p, trade)
+	if p.State != OCOStateActive {
+		// The take-profit is being cancelled by stop, the filled
+		// amount is used to size the stop order.
+		ocom.locker.Unlock()
+		return
+	}
+	ocom.locker.Unlock()
+
+	isFilled := trade.Status == TradeStatusFilled ||
+		!filled.IsLess(p.Order.Amount)
+
+	switch {
+	case isFilled:
+		_, err := ocom.opts.Triggers.Disarm(p.StopID)
+		ocom.complete(p, OCOStateTakeProfit, err)
+
+	case trade.Status == TradeStatusCancelled:
+		// The take-profit order cancelled outside of OCOManager.
+		_, err := ocom.opts.Triggers.Disarm(p.StopID)
+		ocom.complete(p, OCOStateCancelled, err)
+
+	case filled.IsGreaterThanZero():
+		remain := big.SubRat(p.Order.Amount, filled)
+		_, err := ocom.opts.Triggers.SetAmount(p.StopID, remain)
+		if err != nil {
+			log.Printf("OCOManager: %s: resize stop: %s", p.Order.ID, err)
+		}
+	}
+}
+
+// updateFilled update the filled amount of group from the take-profit
+// order and save it.
+// It must be called while holding the lock.
+func (ocom *OCOManager) updateFilled(p *OCOGroup, trade *Trade) (filled *big.Rat) {
+	if p.State != OCOStateActive && p.State != OCOStateStopping {
+		return p.Filled
+	}
+	if trade.CoinFilled == nil || !trade.CoinFilled.IsGreater(p.Filled) {
+		return p.Filled
+	}
+	p.Filled = big.NewRat(trade.CoinFilled)
+	err := ocom.save()
+	if err != nil {
+		log.Printf("OCOManager: %s", err)
+	}
+	return p.Filled
+}
+
+// beforeStop cancel the take-profit order before the stop order placed,
+// and return the stop request for the amount that is not filled by the
+// take-profit.
+// It return nil request if the take-profit has been filled, so the stop
+// order is skipped.
+// It return an error if the take-profit can not be cancelled, so the stop
+// is re-armed.
+func (ocom *OCOManager) beforeStop(id string, trigger StopTrigger) (
+	treq *TradeRequest, err error,
+) {
+	ocom.locker.Lock()
+	p := ocom.groups[id]
+	if p == nil {
+		ocom.locker.Unlock()
+		return nil, nil
+	}
+	isCancelled := p.State == OCOStateStopping
+	p.State = OCOStateStopping
+	ocom.locker.Unlock()
+
+	tp, err := ocom.opts.Client.TradeCancel(ocom.takeProfit(p))
+	if err != nil {
+		// The take-profit may have been closed, cancelled in the
+		// previous attempt, or filled.
+		tp, err = ocom.opts.Client.UserOrderInfo(p.Order.Pair.String(),
+			p.TakeProfitID)
+		if err == nil && len(tp.Status) == 0 {
+			err = errors.New("take-profit is still open")
+		}
+		if err != nil {
+			ocom.locker.Lock()
+			if !isCancelled {
+				p.State = OCOStateActive
+			}
+			ocom.locker.Unlock()
+			return nil, fmt.Errorf("cancel take-profit: %w", err)
+		}
+	}
+
+	ocom.locker.Lock()
+	filled := ocom.updateFilled(p, tp)
+	err = ocom.save()
+	ocom.locker.Unlock()
+	if err != nil {
+		log.Printf("OCOManager: %s", err)
+	}
+
+	remain := big.SubRat(p.Order.Amount, filled)
+	if !remain.IsGreaterThanZero() {
+		return nil, nil
+	}
+
+	treq = &TradeRequest{}
+	*treq = trigger.Request
+	treq.Amount = remain
+	return treq, nil
+}
+
+// handleStop complete the group after the stop order placed or skipped.
+// If the stop order can not be placed the group is kept, since the stop
+// has been re-armed by TriggerEngine.
+func (ocom *OCOManager) handleStop(id string, tres *TradeResponse, errStop error) {
+	ocom.locker.Lock()
+	p := ocom.groups[id]
+	ocom.locker.Unlock()
+	if p == nil {
+		return
+	}
+
+	if errStop != nil {
+		log.Printf("OCOManager: %s: stop: %s", id, errStop)
+		return
+	}
+	if tres == nil {
+		// The take-profit has been filled before it is cancelled.
+		ocom.complete(p, OCOStateTakeProfit, nil)
+		return
+	}
+
+	ocom.locker.Lock()
+	p.StopOrderID = tres.Order.ID
+	ocom.locker.Unlock()
+
+	ocom.complete(p, OCOStateStop, nil)
+}
+
+// complete set the final state of group, remove it, and call the
+// HandleDone callback.
+func (ocom *OCOManager) complete(p *OCOGroup, state string, err error) {
+	ocom.locker.Lock()
+	if p.State != OCOStateActive && p.State != OCOStateStopping {
+		ocom.locker.Unlock()
+		return
+	}
+	p.State = state
+	group := *p
+	ocom.locker.Unlock()
+
+	ocom.remove(p)
+
+	if err != nil {
+		log.Printf("OCOManager: %s: %s", group.Order.ID, err)
+	}
+	if ocom.opts.HandleDone != nil {
+		ocom.opts.HandleDone(group, err)
+	}
+}
+
+func (ocom *OCOManager) remove(p *OCOGroup) {
+	ocom.locker.Lock()
+	delete(ocom.groups, p.Order.ID)
+	delete(ocom.byTP, p.TakeProfitID)
+	unwatch := ocom.unwatch[p.Order.ID]
+	delete(ocom.unwatch, p.Order.ID)
+	err := ocom.save()
+	ocom.locker.Unlock()
+	if unwatch != nil {
+		unwatch()
+	}
+	if err != nil {
+		log.Printf("OCOManager: %s", err)
+	}
+}
+
+// load the active groups from file and watch their stop.
+func (ocom *OCOManager) load() (err error) {
+	if len(ocom.opts.Path) == 0 {
+		return nil
+	}
+
+	b, err := os.ReadFile(ocom.opts.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var groups []*OCOGroup
+	err = json.Unmarshal(b, &groups)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ocom.opts.Path, err)
+	}
+	for _, p := range groups {
+		if p.Filled == nil {
+			p.Filled = big.NewRat(0)
+		}
+		ocom.groups[p.Order.ID] = p
+		ocom.byTP[p.TakeProfitID] = p
+		ocom.watch(p)
+	}
+	return nil
+}
+
+// save the active groups into file.
+// It must be called while holding the lock.
+func (ocom *OCOManager) save() (err error) {
+	if len(ocom.opts.Path) == 0 {
+		return nil
+	}
+
+	groups := make([]*OCOGroup, 0, len(ocom.groups))
+	for _, p := range ocom.groups {
+		groups = append(groups, p)
+	}
+	sort.Slice(groups, func(x, y int) bool {
+		return groups[x].Order.ID < groups[y].Order.ID
+	})
+
+	b, err := json.MarshalIndent(groups, "", "\t")
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	err = writeFileAtomic(ocom.opts.Path, b)
+	if err != nil {
+		return fmt.Errorf("save: %w", err)
+	}
+	return nil
+}
+
+// takeProfit return the take-profit order of group for cancellation.
+func (ocom *OCOManager) takeProfit(p *OCOGroup) *Trade {
+	return &Trade{
+		Pair: p.Order.Pair.String(),
+		Type: p.Order.Type,
+		ID:   p.TakeProfitID,
+	}
+}
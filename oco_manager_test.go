@@ -0,0 +1,240 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestOCOManager(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	te, err := NewTriggerEngine(TriggerEngineOptions{Client: cl})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var done []string
+
+	ocom, err := NewOCOManager(OCOManagerOptions{
+		Client:   cl,
+		Triggers: te,
+		HandleDone: func(group OCOGroup, err error) {
+			if err != nil {
+				t.Error(err)
+			}
+			done = append(done, group.Order.ID+" "+group.State)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order := OCOOrder{
+		Amount:          big.NewRat(3),
+		TakeProfitPrice: big.NewRat(120),
+		StopPrice:       big.NewRat(90),
+		Pair:            PairBitcoinIdk,
+		Type:            TradeTypeAsk,
+	}
+
+	order.ID = "a"
+	groupA, err := ocom.Place(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order.ID = "b"
+	groupB, err := ocom.Place(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "armed stops", 2, len(te.Triggers()))
+
+	// Partial fill on take-profit of "a" resize its stop.
+	tp := ex.fill(groupA.TakeProfitID, big.NewRat(1))
+	ocom.updateTakeProfit(ocom.groups["a"], &tp)
+	for _, trigger := range te.Triggers() {
+		if trigger.ID == groupA.StopID {
+			test.Assert(t, "resized stop", "2", trigger.Request.Amount.String())
+		}
+	}
+
+	// Take-profit of "b" filled, its stop disarmed.
+	ocom.HandleOrdersClosed(ex.close(groupB.TakeProfitID, TradeStatusFilled))
+	test.Assert(t, "armed stops", 1, len(te.Triggers()))
+
+	// The take-profit of "a" filled without notification, the stop
+	// is sized from the cancelled take-profit.
+	ex.fill(groupA.TakeProfitID, big.NewRat("0.5"))
+
+	var stopAmount string
+	te.Subscribe(groupA.StopID, func(_ StopTrigger, tres *TradeResponse, err error) {
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		stopAmount = tres.Order.CoinAmount.String()
+	})
+
+	// Stop of "a" triggered, its take-profit cancelled first.
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	test.Assert(t, "stop amount", "1.5", stopAmount)
+	test.Assert(t, "done", []string{"b take-profit", "a stop"}, done)
+	test.Assert(t, "armed stops", 0, len(te.Triggers()))
+	test.Assert(t, "open orders", 0, len(ex.open()))
+
+	_, ok := ocom.Get("a")
+	test.Assert(t, "Get completed group", false, ok)
+
+	// The take-profit of "c" filled fully before the stop triggered,
+	// the stop order is skipped.
+	order.ID = "c"
+	groupC, err := ocom.Place(order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex.fill(groupC.TakeProfitID, big.NewRat(3))
+
+	nTrade := ex.count(APITradeAsk)
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(90))
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	test.Assert(t, "stop skipped", nTrade, ex.count(APITradeAsk))
+	test.Assert(t, "done", []string{"b take-profit", "a stop", "c take-profit"}, done)
+}
+
+func TestOCOManager_load(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		dir    = t.TempDir()
+
+		teOpts = TriggerEngineOptions{
+			Client: cl,
+			Path:   filepath.Join(dir, "triggers.json"),
+		}
+		ocomOpts = OCOManagerOptions{
+			Client: cl,
+			Path:   filepath.Join(dir, "oco.json"),
+		}
+	)
+
+	te, err := NewTriggerEngine(teOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ocomOpts.Triggers = te
+	ocom, err := NewOCOManager(ocomOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	group, err := ocom.Place(OCOOrder{
+		Amount:          big.NewRat(2),
+		TakeProfitPrice: big.NewRat(120),
+		StopPrice:       big.NewRat(90),
+		ID:              "a",
+		Pair:            PairBitcoinIdk,
+		Type:            TradeTypeAsk,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart both engines.
+	te, err = NewTriggerEngine(teOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var done []string
+	ocomOpts.Triggers = te
+	ocomOpts.HandleDone = func(group OCOGroup, err error) {
+		done = append(done, group.Order.ID+" "+group.State)
+	}
+	ocom, err = NewOCOManager(ocomOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, ok := ocom.Get("a")
+	test.Assert(t, "Get restored group", true, ok)
+	test.Assert(t, "TakeProfitID", group.TakeProfitID, got.TakeProfitID)
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	test.Assert(t, "done", []string{"a stop"}, done)
+	test.Assert(t, "open orders", 0, len(ex.open()))
+
+	_, ok = ocom.Get("a")
+	test.Assert(t, "Get completed group", false, ok)
+}
+
+func TestOCOManager_Cancel(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	te, err := NewTriggerEngine(TriggerEngineOptions{Client: cl})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var done []string
+
+	ocom, err := NewOCOManager(OCOManagerOptions{
+		Client:   cl,
+		Triggers: te,
+		HandleDone: func(group OCOGroup, err error) {
+			done = append(done, group.Order.ID+" "+group.State)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ocom.Place(OCOOrder{
+		Amount:          big.NewRat(2),
+		TakeProfitPrice: big.NewRat(120),
+		StopPrice:       big.NewRat(90),
+		ID:              "a",
+		Pair:            PairBitcoinIdk,
+		Type:            TradeTypeAsk,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The take-profit can not be cancelled, the stop is armed back.
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeCancelAsk {
+			return false
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"code":503,"message":"maintenance","name":"ERR_MAINTENANCE"}`))
+		return true
+	}
+	err = ocom.Cancel("a")
+	if err == nil {
+		t.Fatal("want error on failed take-profit cancel")
+	}
+	test.Assert(t, "armed stops after error", 1, len(te.Triggers()))
+	test.Assert(t, "open orders after error", 1, len(ex.open()))
+
+	got, ok := ocom.Get("a")
+	test.Assert(t, "Get after error", true, ok)
+	test.Assert(t, "state after error", OCOStateActive, got.State)
+
+	ex.handle = nil
+	err = ocom.Cancel("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "armed stops", 0, len(te.Triggers()))
+	test.Assert(t, "open orders", 0, len(ex.open()))
+	test.Assert(t, "done", []string{"a cancelled"}, done)
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	test.Assert(t, "done after price", []string{"a cancelled"}, done)
+}
@@ -0,0 +1,159 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// OrderBook maintain the local copy of market depths on single pair, from
+// the MarketDepths snapshot and the NotifDepths broadcast of
+// WebSocketPublic.
+type OrderBook struct {
+	asks map[string]*Depth
+	bids map[string]*Depth
+
+	Pair Pair
+
+	locker sync.RWMutex
+}
+
+// NewOrderBook create new empty OrderBook for pair.
+func NewOrderBook(pair Pair) (book *OrderBook) {
+	book = &OrderBook{
+		asks: make(map[string]*Depth),
+		bids: make(map[string]*Depth),
+		Pair: pair,
+	}
+	return book
+}
+
+// BestAsk return the copy of lowest ask, or nil if there is no ask.
+func (book *OrderBook) BestAsk() *Depth {
+	book.locker.RLock()
+	defer book.locker.RUnlock()
+	return bestDepth(book.asks, true)
+}
+
+// BestBid return the copy of highest bid, or nil if there is no bid.
+func (book *OrderBook) BestBid() *Depth {
+	book.locker.RLock()
+	defer book.locker.RUnlock()
+	return bestDepth(book.bids, false)
+}
+
+// Mid return the middle price between the best ask and the best bid, or
+// nil if one of them is empty.
+func (book *OrderBook) Mid() *big.Rat {
+	book.locker.RLock()
+	defer book.locker.RUnlock()
+
+	ask := bestDepth(book.asks, true)
+	bid := bestDepth(book.bids, false)
+	if ask == nil || bid == nil {
+		return nil
+	}
+	mid := big.AddRat(ask.Price, bid.Price)
+	return mid.Quo(2)
+}
+
+// Reset replace the content of book with the depths snapshot, usually
+// from MarketDepths.
+func (book *OrderBook) Reset(depths *MarketDepths) {
+	book.locker.Lock()
+	defer book.locker.Unlock()
+
+	book.asks = make(map[string]*Depth, len(depths.Asks))
+	book.bids = make(map[string]*Depth, len(depths.Bids))
+	setDepths(book.asks, depths.Asks)
+	setDepths(book.bids, depths.Bids)
+}
+
+// Snapshot return the copy of book, with asks ordered from the lowest
+// price and bids ordered from the highest price.
+func (book *OrderBook) Snapshot() (depths *MarketDepths) {
+	book.locker.RLock()
+	defer book.locker.RUnlock()
+
+	depths = &MarketDepths{
+		Pair: book.Pair.String(),
+		Asks: sortedDepths(book.asks, true),
+		Bids: sortedDepths(book.bids, false),
+	}
+	return depths
+}
+
+// Update the price levels in book with the depths broadcast, usually
+// from NotifDepths.
+// The depths on different pair is ignored.
+// The level with zero or empty TotalCoin is removed from book.
+func (book *OrderBook) Update(depths *MarketDepths) {
+	if depths == nil || (len(depths.Pair) > 0 && Pair(depths.Pair) != book.Pair) {
+		return
+	}
+
+	book.locker.Lock()
+	defer book.locker.Unlock()
+
+	setDepths(book.asks, depths.Asks)
+	setDepths(book.bids, depths.Bids)
+}
+
+// bestDepth return the copy of depth with lowest price, if isLowest is
+// true, or the highest price.
+func bestDepth(levels map[string]*Depth, isLowest bool) (best *Depth) {
+	for _, depth := range levels {
+		if best == nil || (isLowest && depth.Price.IsLess(best.Price)) ||
+			(!isLowest && depth.Price.IsGreater(best.Price)) {
+			best = depth
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return copyDepth(best)
+}
+
+func copyDepth(depth *Depth) *Depth {
+	return &Depth{
+		Amount:    copyRat(depth.Amount),
+		Price:     copyRat(depth.Price),
+		TotalBase: copyRat(depth.TotalBase),
+		TotalCoin: copyRat(depth.TotalCoin),
+	}
+}
+
+// setDepths set or remove the price levels.
+func setDepths(levels map[string]*Depth, depths []*Depth) {
+	for _, depth := range depths {
+		if depth == nil || depth.Price == nil {
+			continue
+		}
+		key := depth.Price.String()
+		if depth.TotalCoin == nil || !depth.TotalCoin.IsGreaterThanZero() {
+			delete(levels, key)
+			continue
+		}
+		levels[key] = copyDepth(depth)
+	}
+}
+
+// sortedDepths return the copy of price levels ordered by price.
+func sortedDepths(levels map[string]*Depth, isAscending bool) (depths []*Depth) {
+	depths = make([]*Depth, 0, len(levels))
+	for _, depth := range levels {
+		depths = append(depths, copyDepth(depth))
+	}
+	sort.Slice(depths, func(x, y int) bool {
+		if isAscending {
+			return depths[x].Price.IsLess(depths[y].Price)
+		}
+		return depths[x].Price.IsGreater(depths[y].Price)
+	})
+	return depths
+}
@@ -0,0 +1,240 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// OrderBuilder build a TradeRequest or BulkOrderItem using chained method
+// calls, for example
+//
+//	treq, err := NewOrderBuilder(PairBitcoinIdk).
+//		Bid().
+//		Limit("500_000_000").
+//		Amount("0.01").
+//		PostOnly().
+//		Build()
+//
+// The first invalid value is reported by Build or BuildBulkItem.
+type OrderBuilder struct {
+	err   error
+	treq  TradeRequest
+	refID int64
+}
+
+// NewOrderBuilder create new OrderBuilder for specific pair.
+// By default the order method is "limit".
+func NewOrderBuilder(pair Pair) (ob *OrderBuilder) {
+	ob = &OrderBuilder{
+		treq: TradeRequest{
+			Pair:   pair,
+			Method: TradeMethodLimit,
+		},
+	}
+	return ob
+}
+
+// Amount set the amount of coin to be traded.
+// The v parameter can be any value that can be converted to big.Rat, for
+// example string "0.01", float64, or *big.Rat.
+func (ob *OrderBuilder) Amount(v interface{}) *OrderBuilder {
+	ob.treq.Amount = ob.toRat(v, ErrInvalidAmount)
+	return ob
+}
+
+// Ask set the order type to "sell".
+func (ob *OrderBuilder) Ask() *OrderBuilder {
+	ob.treq.Type = TradeTypeAsk
+	return ob
+}
+
+// Bid set the order type to "buy".
+func (ob *OrderBuilder) Bid() *OrderBuilder {
+	ob.treq.Type = TradeTypeBid
+	return ob
+}
+
+// ClientOrderID set the local ID of order.
+// If id is empty, new random ID is generated using NewClientOrderID.
+func (ob *OrderBuilder) ClientOrderID(id string) *OrderBuilder {
+	if len(id) == 0 {
+		id = NewClientOrderID()
+	}
+	ob.treq.ClientOrderID = id
+	return ob
+}
+
+// FillOrKill set the TimeInForce to "FOK".
+func (ob *OrderBuilder) FillOrKill() *OrderBuilder {
+	return ob.TimeInForce(TimeInForceFOK)
+}
+
+// GoodTillTime set the TimeInForce to "GTT" with the expiry time.
+func (ob *OrderBuilder) GoodTillTime(expireAt time.Time) *OrderBuilder {
+	ob.treq.ExpireAt = expireAt
+	return ob.TimeInForce(TimeInForceGTT)
+}
+
+// ImmediateOrCancel set the TimeInForce to "IOC".
+func (ob *OrderBuilder) ImmediateOrCancel() *OrderBuilder {
+	return ob.TimeInForce(TimeInForceIOC)
+}
+
+// Limit set the order method to "limit" with specific price.
+func (ob *OrderBuilder) Limit(price interface{}) *OrderBuilder {
+	ob.treq.Method = TradeMethodLimit
+	ob.treq.Price = ob.toRat(price, ErrInvalidPrice)
+	return ob
+}
+
+// Market set the order method to "market".
+func (ob *OrderBuilder) Market() *OrderBuilder {
+	ob.treq.Method = TradeMethodMarket
+	ob.treq.Price = nil
+	return ob
+}
+
+// PostOnly set the order as post-only.
+func (ob *OrderBuilder) PostOnly() *OrderBuilder {
+	ob.treq.IsPostOnly = true
+	return ob
+}
+
+// RefID set the reference ID for BulkOrderItem.
+func (ob *OrderBuilder) RefID(id int64) *OrderBuilder {
+	ob.refID = id
+	return ob
+}
+
+// TimeInForce set the TimeInForce of order.
+func (ob *OrderBuilder) TimeInForce(tif string) *OrderBuilder {
+	ob.treq.TimeInForce = tif
+	return ob
+}
+
+// Build validate and return new TradeRequest.
+func (ob *OrderBuilder) Build() (treq *TradeRequest, err error) {
+	if ob.err != nil {
+		return nil, fmt.Errorf("OrderBuilder: %w", ob.err)
+	}
+
+	treq = &TradeRequest{}
+	*treq = ob.treq
+	treq.Price = copyRat(ob.treq.Price)
+	treq.Amount = copyRat(ob.treq.Amount)
+
+	switch treq.Type {
+	case TradeTypeAsk, TradeTypeBid:
+	default:
+		return nil, fmt.Errorf("OrderBuilder: %w", ErrInvalidTradeType)
+	}
+
+	_, _, err = treq.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("OrderBuilder: %w", err)
+	}
+	return treq, nil
+}
+
+// BuildBulkItem validate and return new BulkOrderItem, to be used in
+// TradeBulk Orders.
+// The TimeInForce IOC and GTT are not supported in bulk order.
+func (ob *OrderBuilder) BuildBulkItem() (item *BulkOrderItem, err error) {
+	treq, err := ob.Build()
+	if err != nil {
+		return nil, err
+	}
+	err = checkBulkTimeInForce(treq)
+	if err != nil {
+		return nil, fmt.Errorf("OrderBuilder: %w", err)
+	}
+	item = &BulkOrderItem{
+		TradeRequest: *treq,
+		RefID:        ob.refID,
+	}
+	return item, nil
+}
+
+func (ob *OrderBuilder) toRat(v interface{}, errInvalid error) (r *big.Rat) {
+	r = big.NewRat(v)
+	if r == nil && ob.err == nil {
+		ob.err = errInvalid
+	}
+	return r
+}
+
+// OrderLadder define parameters to build list of limit orders with the same
+// amount on multiple price levels.
+//
+// For ask, the price on each level is increased by PriceStep starting from
+// StartPrice; for bid, the price is decreased by PriceStep.
+type OrderLadder struct {
+	StartPrice *big.Rat
+	PriceStep  *big.Rat
+	Amount     *big.Rat
+
+	Pair Pair
+
+	// Type of order, its either "buy" or "sell".
+	Type string
+
+	// Levels define the number of orders in the ladder.
+	Levels int
+
+	IsPostOnly bool
+}
+
+// Build the list of TradeRequest in the ladder, ordered from the nearest to
+// the farthest price from StartPrice.
+func (ladder OrderLadder) Build() (treqs []*TradeRequest, err error) {
+	logp := "OrderLadder"
+
+	if ladder.Levels <= 0 {
+		return nil, fmt.Errorf("%s: invalid Levels %d", logp, ladder.Levels)
+	}
+	if ladder.PriceStep == nil || ladder.PriceStep.IsLessThanZero() {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidPrice)
+	}
+
+	price := copyRat(ladder.StartPrice)
+	for x := 0; x < ladder.Levels; x++ {
+		ob := NewOrderBuilder(ladder.Pair).
+			Limit(price).
+			Amount(ladder.Amount)
+		if ladder.Type == TradeTypeAsk {
+			ob.Ask()
+		} else {
+			ob.Bid()
+		}
+		if ladder.IsPostOnly {
+			ob.PostOnly()
+		}
+
+		treq, err := ob.Build()
+		if err != nil {
+			return nil, fmt.Errorf("%s: level %d: %w", logp, x, err)
+		}
+		treqs = append(treqs, treq)
+
+		if ladder.Type == TradeTypeAsk {
+			price = big.AddRat(price, ladder.PriceStep)
+		} else {
+			price = big.SubRat(price, ladder.PriceStep)
+		}
+	}
+	return treqs, nil
+}
+
+// copyRat return the copy of r, or nil if r is nil.
+func copyRat(r *big.Rat) *big.Rat {
+	if r == nil {
+		return nil
+	}
+	return big.NewRat(r)
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// OrderFilter define the criteria to select open orders.
+// The zero value of each field means no filter on that field, so an empty
+// OrderFilter match all orders.
+type OrderFilter struct {
+	// MinPrice, optional, select only orders with price greater or
+	// equal to MinPrice.
+	MinPrice *big.Rat
+
+	// MaxPrice, optional, select only orders with price less or equal
+	// to MaxPrice.
+	MaxPrice *big.Rat
+
+	// TagOf, optional, return the local tag of the order.
+	// It is required if Tag is set.
+	TagOf func(trade *Trade) string
+
+	// Match, optional, custom function to select the order.
+	// It is called after all other criteria are matched.
+	Match func(trade *Trade) bool
+
+	// Type, optional, select only orders with specific type, its either
+	// "buy" or "sell".
+	Type string
+
+	// Tag, optional, select only orders with the local tag, as returned
+	// by TagOf.
+	Tag string
+
+	// Pairs, optional, select only orders on the list of pairs.
+	Pairs []Pair
+
+	// OlderThan, optional, select only orders that has been submitted
+	// more than OlderThan duration ago.
+	OlderThan time.Duration
+}
+
+// IsMatch return true if the trade match all criteria in filter, relative
+// to the time now.
+func (filter *OrderFilter) IsMatch(trade *Trade, now time.Time) bool {
+	if trade == nil {
+		return false
+	}
+	if len(filter.Pairs) > 0 && !filter.hasPair(Pair(trade.Pair)) {
+		return false
+	}
+	if len(filter.Type) > 0 && trade.Type != filter.Type {
+		return false
+	}
+	if filter.MinPrice != nil {
+		if trade.Price == nil || trade.Price.IsLess(filter.MinPrice) {
+			return false
+		}
+	}
+	if filter.MaxPrice != nil {
+		if trade.Price == nil || trade.Price.IsGreater(filter.MaxPrice) {
+			return false
+		}
+	}
+	if filter.OlderThan > 0 {
+		submitTime := time.Unix(trade.SubmitTime, 0)
+		if now.Sub(submitTime) < filter.OlderThan {
+			return false
+		}
+	}
+	if len(filter.Tag) > 0 {
+		if filter.TagOf == nil || filter.TagOf(trade) != filter.Tag {
+			return false
+		}
+	}
+	if filter.Match != nil && !filter.Match(trade) {
+		return false
+	}
+	return true
+}
+
+func (filter *OrderFilter) hasPair(pair Pair) bool {
+	for _, p := range filter.Pairs {
+		if p == pair {
+			return true
+		}
+	}
+	return false
+}
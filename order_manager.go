@@ -0,0 +1,598 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of order states in OrderManager.
+const (
+	OrderStateSubmitted = "submitted"
+	OrderStateOpen      = "open"
+	OrderStatePartial   = "partial"
+	OrderStateFilled    = "filled"
+	OrderStateCancelled = "cancelled"
+	OrderStateRejected  = "rejected"
+)
+
+// DefaultReconcileInterval define the default interval where OrderManager
+// and BalanceTracker reconcile the local state with server.
+const DefaultReconcileInterval = time.Minute
+
+// DefaultOrderRetention define the default duration where the final
+// orders are kept in OrderManager.
+const DefaultOrderRetention = time.Hour
+
+// OrderHandler define a callback that will be called when the state of
+// managed order changes.
+type OrderHandler func(order ManagedOrder)
+
+// ManagedOrder contains the local state of order in OrderManager.
+type ManagedOrder struct {
+	// UpdatedAt contains the last time the order state changes.
+	UpdatedAt time.Time
+
+	// Err contains the error when the order is rejected.
+	Err error
+
+	// Request contains the original request, if the order is placed
+	// using OrderManager.Submit or OrderManager.Track.
+	Request *TradeRequest
+
+	// Tag contains the local tag of order.
+	Tag string
+
+	// State contains the current state of order, one of the
+	// OrderState constants.
+	State string
+
+	// Trade contains the latest order information from server.
+	Trade Trade
+}
+
+// IsFinal return true if the order will not change its state anymore.
+func (order *ManagedOrder) IsFinal() bool {
+	switch order.State {
+	case OrderStateFilled, OrderStateCancelled, OrderStateRejected:
+		return true
+	}
+	return false
+}
+
+// OrderManagerOptions define the options for OrderManager.
+type OrderManagerOptions struct {
+	// Client, required, is the REST client used to place the orders and
+	// to reconcile the orders state.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed.
+	// The OrderManager is registered using AddOrdersClosedHandler.
+	WebSocket *WebSocketPrivate
+
+	// HandleChange, optional, is the callback that will be called on
+	// every order state changes.
+	HandleChange OrderHandler
+
+	// ReconcileInterval, optional, define the interval to reconcile the
+	// orders with server.
+	// Default to DefaultReconcileInterval.
+	ReconcileInterval time.Duration
+
+	// Retention, optional, define how long the orders in final state,
+	// including the rejected one, are kept after their last change.
+	// The expired orders are removed on Reconcile.
+	// Default to DefaultOrderRetention.
+	Retention time.Duration
+}
+
+// OrderManager track the state of orders placed by application.
+//
+// The order state is updated from TradeResponse, from the closed orders
+// broadcast in WebSocketPrivate, and periodically reconciled with
+// UserOrdersOpen and UserOrderInfo to catch the missed events.
+//
+// The state of each order moves from "submitted" to "open" or "rejected",
+// then to "partial", and finally to "filled" or "cancelled".
+// The "submitted" state is recorded by Submit before the order is sent;
+// the order recorded by Track start from the state in its response.
+type OrderManager struct {
+	opts OrderManagerOptions
+
+	orders map[int64]*ManagedOrder
+	tags   map[string][]*ManagedOrder
+	subs   map[int64][]*orderSubscriber
+
+	// early contains the closed orders broadcast that received before
+	// the order is recorded.
+	early map[int64]Trade
+
+	done chan struct{}
+
+	locker sync.Mutex
+
+	isRunning bool
+}
+
+type orderSubscriber struct {
+	handler OrderHandler
+}
+
+// NewOrderManager create and initialize new OrderManager.
+// The periodic reconciliation is not running until Start is called.
+func NewOrderManager(opts OrderManagerOptions) (om *OrderManager, err error) {
+	if opts.Client == nil {
+		return nil, errors.New("NewOrderManager: empty Client")
+	}
+	if opts.ReconcileInterval <= 0 {
+		opts.ReconcileInterval = DefaultReconcileInterval
+	}
+	if opts.Retention <= 0 {
+		opts.Retention = DefaultOrderRetention
+	}
+
+	om = &OrderManager{
+		opts:   opts,
+		orders: make(map[int64]*ManagedOrder),
+		tags:   make(map[string][]*ManagedOrder),
+		subs:   make(map[int64][]*orderSubscriber),
+		early:  make(map[int64]Trade),
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(om.HandleOrdersClosed)
+	}
+	return om, nil
+}
+
+// ByTag return the list of orders with specific tag, ordered by the time
+// they are recorded.
+func (om *OrderManager) ByTag(tag string) (orders []ManagedOrder) {
+	om.locker.Lock()
+	for _, order := range om.tags[tag] {
+		orders = append(orders, *order)
+	}
+	om.locker.Unlock()
+	return orders
+}
+
+// Get the order by its ID.
+func (om *OrderManager) Get(id int64) (order ManagedOrder, ok bool) {
+	om.locker.Lock()
+	defer om.locker.Unlock()
+
+	p := om.orders[id]
+	if p == nil {
+		return order, false
+	}
+	return *p, true
+}
+
+// Open return the list of orders that are not final yet.
+func (om *OrderManager) Open() (orders []ManagedOrder) {
+	om.locker.Lock()
+	for _, order := range om.orders {
+		if !order.IsFinal() {
+			orders = append(orders, *order)
+		}
+	}
+	om.locker.Unlock()
+	return orders
+}
+
+// Remove the order from OrderManager, including its subscribers.
+func (om *OrderManager) Remove(id int64) {
+	om.locker.Lock()
+	defer om.locker.Unlock()
+
+	order := om.orders[id]
+	if order == nil {
+		return
+	}
+	delete(om.orders, id)
+	delete(om.subs, id)
+
+	list := om.tags[order.Tag]
+	for x, p := range list {
+		if p == order {
+			list = append(list[:x], list[x+1:]...)
+			break
+		}
+	}
+	if len(list) == 0 {
+		delete(om.tags, order.Tag)
+	} else {
+		om.tags[order.Tag] = list
+	}
+}
+
+// Submit place new order using Client.TradeAsk or Client.TradeBid, based
+// on the treq.Type, and record it with the tag.
+// The order is recorded with state "submitted" before its sent, and then
+// updated from the response.
+//
+// If the order rejected by server, the order is recorded with state
+// "rejected" and the error is returned.
+func (om *OrderManager) Submit(tag string, treq *TradeRequest) (
+	order ManagedOrder, err error,
+) {
+	if treq == nil {
+		return order, fmt.Errorf("Submit: %w", ErrInvalidTradeType)
+	}
+
+	p := newManagedOrder(tag, treq)
+
+	var tres *TradeResponse
+
+	switch treq.Type {
+	case TradeTypeAsk, TradeTypeBid:
+		om.locker.Lock()
+		om.tags[tag] = append(om.tags[tag], p)
+		order = *p
+		om.locker.Unlock()
+
+		om.notify(order, nil)
+
+		if treq.Type == TradeTypeAsk {
+			tres, err = om.opts.Client.TradeAsk(treq)
+		} else {
+			tres, err = om.opts.Client.TradeBid(treq)
+		}
+	default:
+		err = ErrInvalidTradeType
+
+		om.locker.Lock()
+		om.tags[tag] = append(om.tags[tag], p)
+		om.locker.Unlock()
+	}
+
+	order = om.record(p, tres, err)
+	if err != nil {
+		return order, fmt.Errorf("Submit: %w", err)
+	}
+	return order, nil
+}
+
+// Subscribe register the handler that will be called when the state of
+// order with specific ID changes.
+// It return a function to unsubscribe the handler.
+func (om *OrderManager) Subscribe(id int64, handler OrderHandler) (
+	unsubscribe func(),
+) {
+	sub := &orderSubscriber{
+		handler: handler,
+	}
+
+	om.locker.Lock()
+	om.subs[id] = append(om.subs[id], sub)
+	om.locker.Unlock()
+
+	return func() {
+		om.locker.Lock()
+		defer om.locker.Unlock()
+
+		list := om.subs[id]
+		for x, p := range list {
+			if p == sub {
+				om.subs[id] = append(list[:x], list[x+1:]...)
+				break
+			}
+		}
+		if len(om.subs[id]) == 0 {
+			delete(om.subs, id)
+		}
+	}
+}
+
+// Track record the result of order that has been placed outside of
+// OrderManager, for example using WebSocketPrivate or TradeBulk.
+//
+// If err is not nil or the tres does not contains order, the order is
+// recorded with state "rejected".
+func (om *OrderManager) Track(
+	tag string, treq *TradeRequest, tres *TradeResponse, err error,
+) (order ManagedOrder) {
+	p := newManagedOrder(tag, treq)
+
+	om.locker.Lock()
+	om.tags[tag] = append(om.tags[tag], p)
+	om.locker.Unlock()
+
+	return om.record(p, tres, err)
+}
+
+// record update the order p, that has been added into tags, with the
+// result of request.
+func (om *OrderManager) record(p *ManagedOrder, tres *TradeResponse, err error) (
+	order ManagedOrder,
+) {
+	if err == nil && (tres == nil || tres.Order == nil) {
+		err = errors.New("empty order in response")
+	}
+	if err != nil {
+		om.locker.Lock()
+		p.State = OrderStateRejected
+		p.Err = err
+		p.UpdatedAt = time.Now()
+		order = *p
+		om.locker.Unlock()
+
+		om.notify(order, nil)
+		return order
+	}
+
+	om.locker.Lock()
+	om.orders[tres.Order.ID] = p
+	om.update(p, tres.Order)
+	if trade, ok := om.early[tres.Order.ID]; ok {
+		delete(om.early, tres.Order.ID)
+		om.update(p, &trade)
+	}
+	order = *p
+	subs := om.subscribers(p.Trade.ID)
+	om.locker.Unlock()
+
+	om.notify(order, subs)
+	return order
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate.
+// If the NewOrderManager is created with WebSocket options, this method is
+// registered automatically.
+func (om *OrderManager) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+	om.apply(trade, true)
+}
+
+// Reconcile the state of orders that are not final with server.
+//
+// For each pair, the open orders are fetched using UserOrdersOpen.
+// The order that is no longer open is fetched using UserOrderInfo to get
+// its final state.
+// The final orders that are older than Retention are removed.
+func (om *OrderManager) Reconcile() (err error) {
+	var (
+		logp   = "Reconcile"
+		byPair = make(map[string][]int64)
+		errs   []string
+	)
+
+	om.locker.Lock()
+	om.prune(time.Now())
+	for id, order := range om.orders {
+		if !order.IsFinal() {
+			byPair[order.Trade.Pair] = append(byPair[order.Trade.Pair], id)
+		}
+	}
+	// Any closed orders broadcast that does not have their response
+	// until now are not ours.
+	om.early = make(map[int64]Trade)
+	om.locker.Unlock()
+
+	for pairName, ids := range byPair {
+		pairTradesOpen, err := om.opts.Client.UserOrdersOpen(pairName)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		openTrades := pairTradesOpen.Trades()
+		isOpen := make(map[int64]bool, len(openTrades))
+		for x := range openTrades {
+			isOpen[openTrades[x].ID] = true
+			om.apply(&openTrades[x], false)
+		}
+
+		for _, id := range ids {
+			if isOpen[id] {
+				continue
+			}
+			trade, err := om.opts.Client.UserOrderInfo(pairName, id)
+			if err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+			if len(trade.Pair) == 0 {
+				trade.Pair = pairName
+			}
+			om.apply(trade, false)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s: %s", logp, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Start reconciling the orders periodically in the background.
+// Calling Start on running OrderManager has no effect.
+func (om *OrderManager) Start() {
+	om.locker.Lock()
+	defer om.locker.Unlock()
+
+	if om.isRunning {
+		return
+	}
+	om.isRunning = true
+	om.done = make(chan struct{})
+
+	go om.run(om.done)
+}
+
+// Stop the periodic reconciliation.
+func (om *OrderManager) Stop() {
+	om.locker.Lock()
+	defer om.locker.Unlock()
+
+	if !om.isRunning {
+		return
+	}
+	close(om.done)
+	om.isRunning = false
+}
+
+func (om *OrderManager) run(done chan struct{}) {
+	ticker := time.NewTicker(om.opts.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			err := om.Reconcile()
+			if err != nil {
+				log.Printf("OrderManager: %s", err)
+			}
+		}
+	}
+}
+
+// apply the trade information into the recorded order.
+// If isBroadcast is true and the order is not recorded yet, the trade is
+// kept until the order is recorded.
+func (om *OrderManager) apply(trade *Trade, isBroadcast bool) {
+	om.locker.Lock()
+	p := om.orders[trade.ID]
+	if p == nil {
+		if isBroadcast {
+			om.early[trade.ID] = *trade
+		}
+		om.locker.Unlock()
+		return
+	}
+	isChanged := om.update(p, trade)
+	order := *p
+	subs := om.subscribers(trade.ID)
+	om.locker.Unlock()
+
+	if isChanged {
+		om.notify(order, subs)
+	}
+}
+
+// update the order state based on the trade information.
+// It return true if the state or the filled amount changes.
+func (om *OrderManager) update(p *ManagedOrder, trade *Trade) (isChanged bool) {
+	if p.IsFinal() {
+		return false
+	}
+
+	state := orderStateOf(trade)
+	if state == OrderStateOpen && p.State == OrderStatePartial {
+		// Ignore outdated information.
+		return false
+	}
+
+	isFillChanged := !isRatEqual(p.Trade.CoinFilled, trade.CoinFilled)
+	if state == p.State && !isFillChanged {
+		return false
+	}
+
+	next := *trade
+	if len(next.Pair) == 0 {
+		next.Pair = p.Trade.Pair
+	}
+	if len(next.Type) == 0 {
+		next.Type = p.Trade.Type
+	}
+	p.Trade = next
+	p.State = state
+	p.UpdatedAt = time.Now()
+	return true
+}
+
+// prune remove the final orders that does not change since Retention.
+// It must be called while holding the lock.
+func (om *OrderManager) prune(now time.Time) {
+	for tag, list := range om.tags {
+		kept := list[:0]
+		for _, p := range list {
+			if !p.IsFinal() || now.Sub(p.UpdatedAt) < om.opts.Retention {
+				kept = append(kept, p)
+				continue
+			}
+			if om.orders[p.Trade.ID] == p {
+				delete(om.orders, p.Trade.ID)
+				delete(om.subs, p.Trade.ID)
+			}
+		}
+		if len(kept) == 0 {
+			delete(om.tags, tag)
+		} else {
+			om.tags[tag] = kept
+		}
+	}
+}
+
+// subscribers return the copy of subscribers on order ID.
+// It must be called while holding the lock.
+func (om *OrderManager) subscribers(id int64) (subs []*orderSubscriber) {
+	subs = make([]*orderSubscriber, len(om.subs[id]))
+	copy(subs, om.subs[id])
+	return subs
+}
+
+func (om *OrderManager) notify(order ManagedOrder, subs []*orderSubscriber) {
+	if om.opts.HandleChange != nil {
+		om.opts.HandleChange(order)
+	}
+	for _, sub := range subs {
+		sub.handler(order)
+	}
+}
+
+// newManagedOrder create new order with state "submitted".
+func newManagedOrder(tag string, treq *TradeRequest) (p *ManagedOrder) {
+	p = &ManagedOrder{
+		UpdatedAt: time.Now(),
+		Request:   treq,
+		Tag:       tag,
+		State:     OrderStateSubmitted,
+	}
+	if treq != nil {
+		p.Trade.Pair = treq.Pair.String()
+		p.Trade.Type = treq.Type
+	}
+	return p
+}
+
+// orderStateOf return the order state based on the trade status and its
+// filled amount.
+func orderStateOf(trade *Trade) string {
+	switch trade.Status {
+	case TradeStatusFilled:
+		return OrderStateFilled
+	case TradeStatusCancelled:
+		return OrderStateCancelled
+	}
+	if trade.CoinRemain != nil && trade.CoinAmount != nil &&
+		trade.CoinRemain.IsZero() && trade.CoinAmount.IsGreaterThanZero() {
+		return OrderStateFilled
+	}
+	if trade.CoinFilled != nil && trade.CoinFilled.IsGreaterThanZero() {
+		return OrderStatePartial
+	}
+	return OrderStateOpen
+}
+
+// isRatEqual return true if both a and b are nil or have the same value.
+func isRatEqual(a, b *big.Rat) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.IsEqual(b)
+}
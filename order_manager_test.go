@@ -0,0 +1,84 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestOrderManager(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var changes []string
+
+	om, err := NewOrderManager(OrderManagerOptions{
+		Client: cl,
+		HandleChange: func(order ManagedOrder) {
+			changes = append(changes, order.State)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treq, err := NewOrderBuilder(PairBitcoinIdk).Bid().Limit(100).Amount(2).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := om.Submit("grid", treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Submit state", OrderStateOpen, order.State)
+
+	var subStates []string
+	om.Subscribe(order.Trade.ID, func(order ManagedOrder) {
+		subStates = append(subStates, order.State)
+	})
+
+	// Partial fill from reconciliation, followed by outdated open
+	// information.
+	om.apply(&Trade{ID: order.Trade.ID, CoinFilled: big.NewRat(1)}, false)
+	om.apply(&Trade{ID: order.Trade.ID, CoinFilled: big.NewRat(0)}, false)
+
+	om.HandleOrdersClosed(ex.close(order.Trade.ID, TradeStatusFilled))
+	// Broadcast after final state is ignored.
+	om.HandleOrdersClosed(&Trade{ID: order.Trade.ID, Status: TradeStatusCancelled})
+
+	_, err = om.Submit("grid", &TradeRequest{Pair: PairBitcoinIdk})
+	if err == nil {
+		t.Fatal("want error on invalid type")
+	}
+
+	test.Assert(t, "changes", []string{
+		OrderStateSubmitted, OrderStateOpen, OrderStatePartial,
+		OrderStateFilled, OrderStateRejected,
+	}, changes)
+	test.Assert(t, "subscriber", []string{OrderStatePartial, OrderStateFilled}, subStates)
+
+	var tagStates []string
+	for _, order := range om.ByTag("grid") {
+		tagStates = append(tagStates, order.State)
+	}
+	test.Assert(t, "ByTag", []string{OrderStateFilled, OrderStateRejected}, tagStates)
+	test.Assert(t, "Open", 0, len(om.Open()))
+
+	// The final orders are kept until the retention passed.
+	om.locker.Lock()
+	om.prune(time.Now())
+	om.locker.Unlock()
+	test.Assert(t, "ByTag after prune", 2, len(om.ByTag("grid")))
+
+	om.locker.Lock()
+	om.prune(time.Now().Add(DefaultOrderRetention))
+	om.locker.Unlock()
+	test.Assert(t, "ByTag after retention", 0, len(om.ByTag("grid")))
+	test.Assert(t, "orders after retention", 0, len(om.orders))
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pairSeparator is the separator between coin and base asset in pair name.
+const pairSeparator = "_"
+
+// Pair define the name of market where the coin asset is traded using the
+// base asset, in the following format: "coin_base".
+// For example, in pair "btc_idk", the "btc" is the coin and "idk" is the
+// base.
+type Pair string
+
+// NewPair create new Pair from coin and base asset names.
+func NewPair(coin, base AssetName) Pair {
+	return Pair(coin + pairSeparator + base)
+}
+
+// ParsePair parse and validate the pair name from string.
+// The name is converted to lower case before validated.
+// It will return ErrInvalidPair if the name is not in "coin_base" format.
+func ParsePair(name string) (pair Pair, err error) {
+	pair = Pair(strings.ToLower(strings.TrimSpace(name)))
+	err = pair.Validate()
+	if err != nil {
+		return "", err
+	}
+	return pair, nil
+}
+
+// Assets return the coin and base asset of pair.
+func (pair Pair) Assets() (coin, base AssetName) {
+	c, b, _ := strings.Cut(string(pair), pairSeparator)
+	return AssetName(c), AssetName(b)
+}
+
+// Base return the base asset name of pair.
+func (pair Pair) Base() AssetName {
+	_, base := pair.Assets()
+	return base
+}
+
+// Coin return the coin asset name of pair.
+func (pair Pair) Coin() AssetName {
+	coin, _ := pair.Assets()
+	return coin
+}
+
+// Inverse return the pair with coin and base asset swapped.
+// For example, the inverse of "btc_idk" is "idk_btc".
+func (pair Pair) Inverse() Pair {
+	return NewPair(pair.Base(), pair.Coin())
+}
+
+// IsEmpty return true if the pair is not set.
+func (pair Pair) IsEmpty() bool {
+	return len(pair) == 0
+}
+
+// MarshalText implement the encoding.TextMarshaler interface.
+func (pair Pair) MarshalText() ([]byte, error) {
+	return []byte(pair), nil
+}
+
+// String return the pair name.
+func (pair Pair) String() string {
+	return string(pair)
+}
+
+// UnmarshalText implement the encoding.TextUnmarshaler interface.
+// Empty text is allowed, otherwise it must be a valid pair name.
+func (pair *Pair) UnmarshalText(text []byte) (err error) {
+	if len(text) == 0 {
+		*pair = ""
+		return nil
+	}
+	*pair, err = ParsePair(string(text))
+	if err != nil {
+		return fmt.Errorf("Pair.UnmarshalText: %q: %w", text, err)
+	}
+	return nil
+}
+
+// Validate the pair format.
+// A valid pair contains coin and base asset names separated by underscore,
+// and each name contains only lower case letters and digits.
+func (pair Pair) Validate() error {
+	coin, base, found := strings.Cut(string(pair), pairSeparator)
+	if !found {
+		return ErrInvalidPair
+	}
+	if AssetName(coin).Validate() != nil || AssetName(base).Validate() != nil {
+		return ErrInvalidPair
+	}
+	if coin == base {
+		return ErrInvalidPair
+	}
+	return nil
+}
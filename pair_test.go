@@ -0,0 +1,126 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestParsePair(t *testing.T) {
+	cases := []struct {
+		name    string
+		exp     Pair
+		expCoin AssetName
+		expBase AssetName
+		expErr  error
+	}{{
+		name:    "btc_usdt",
+		exp:     PairBitcoinTether,
+		expCoin: AssetNameBitcoin,
+		expBase: AssetNameTether,
+	}, {
+		name:    " BTC_IDK ",
+		exp:     PairBitcoinIdk,
+		expCoin: AssetNameBitcoin,
+		expBase: AssetNameIdk,
+	}, {
+		name:   "btc-usdt",
+		expErr: ErrInvalidPair,
+	}, {
+		name:   "btc_",
+		expErr: ErrInvalidPair,
+	}, {
+		name:   "btc_usdt_idk",
+		expErr: ErrInvalidPair,
+	}, {
+		name:   "btc_btc",
+		expErr: ErrInvalidPair,
+	}}
+
+	for _, c := range cases {
+		got, err := ParsePair(c.name)
+		if c.expErr != nil {
+			if !errors.Is(err, c.expErr) {
+				t.Fatalf("%q: want error %v, got %v", c.name, c.expErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.Assert(t, c.name, c.exp, got)
+		test.Assert(t, c.name+": Coin", c.expCoin, got.Coin())
+		test.Assert(t, c.name+": Base", c.expBase, got.Base())
+		test.Assert(t, c.name+": Inverse", NewPair(c.expBase, c.expCoin), got.Inverse())
+
+		coin, base := got.Assets()
+		test.Assert(t, c.name+": Assets", []AssetName{c.expCoin, c.expBase},
+			[]AssetName{coin, base})
+	}
+}
+
+func TestParseAssetName(t *testing.T) {
+	got, err := ParseAssetName(" BTC ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "ParseAssetName", AssetName(AssetNameBitcoin), got)
+
+	for _, name := range []string{"", "btc_idk", "b-tc"} {
+		_, err = ParseAssetName(name)
+		if !errors.Is(err, ErrInvalidAsset) {
+			t.Fatalf("%q: want ErrInvalidAsset, got %v", name, err)
+		}
+	}
+}
+
+func TestPair_UnmarshalJSON(t *testing.T) {
+	var treq TradeRequest
+
+	err := json.Unmarshal([]byte(`{"pair":"ten_idk"}`), &treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "Pair", Pair(PairTokenomyIdk), treq.Pair)
+
+	err = json.Unmarshal([]byte(`{"pair":"ten-idk"}`), &treq)
+	if !errors.Is(err, ErrInvalidPair) {
+		t.Fatalf("want ErrInvalidPair, got %v", err)
+	}
+}
+
+func TestMarketRegistry_Validate(t *testing.T) {
+	reg := NewMarketRegistry([]MarketInfo{{
+		Pair:     PairBitcoinIdk,
+		IsActive: true,
+	}, {
+		Pair: PairTokenomyIdk,
+	}})
+
+	test.Assert(t, "Pairs", []Pair{PairBitcoinIdk, PairTokenomyIdk}, reg.Pairs())
+
+	err := reg.Validate(PairBitcoinIdk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pair := range []Pair{PairTokenomyIdk, PairBitcoinTether, "btc-idk"} {
+		err = reg.Validate(pair)
+		if !errors.Is(err, ErrInvalidPair) {
+			t.Fatalf("%s: want ErrInvalidPair, got %v", pair, err)
+		}
+	}
+
+	ex, cl := newTestExchange(t)
+	cl.Markets = reg
+	_, err = cl.TradeCancelBid(PairTokenomyIdk, 1)
+	if !errors.Is(err, ErrInvalidPair) {
+		t.Fatalf("TradeCancelBid: want ErrInvalidPair, got %v", err)
+	}
+	test.Assert(t, "cancel request", 0, ex.count(APITradeCancelBid))
+}
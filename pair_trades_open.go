@@ -6,3 +6,27 @@ package tokenomy
 
 // PairTradesOpen contains mapping of pair and their open trades information.
 type PairTradesOpen map[string]TradesOpen
+
+// Trades return all open asks and bids as a single list.
+// The Pair field on each trade is set to its pair's name, if its empty, and
+// the Type field is set to "sell" for asks or "buy" for bids, if its empty.
+func (pto PairTradesOpen) Trades() (trades []Trade) {
+	for pairName, tradesOpen := range pto {
+		trades = appendTradesOpen(trades, pairName, TradeTypeAsk, tradesOpen.Asks)
+		trades = appendTradesOpen(trades, pairName, TradeTypeBid, tradesOpen.Bids)
+	}
+	return trades
+}
+
+func appendTradesOpen(trades []Trade, pairName, tradeType string, list []Trade) []Trade {
+	for _, trade := range list {
+		if len(trade.Pair) == 0 {
+			trade.Pair = pairName
+		}
+		if len(trade.Type) == 0 {
+			trade.Type = tradeType
+		}
+		trades = append(trades, trade)
+	}
+	return trades
+}
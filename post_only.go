@@ -0,0 +1,180 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// DefaultPostOnlyRetry define the default number of retries in
+// TradePostOnly.
+const DefaultPostOnlyRetry = 3
+
+// TradePostOnly place the limit order as post-only and, if its rejected
+// because it would cross the book, reprice it one tick away from the best
+// opposite price and retry, up to maxRetry times.
+//
+// The best opposite price is read from MarketDepths, or from MarketTicker
+// if the depths on that side is empty.
+// The tick is derived from the PricePrecision of market information in
+// Client Markets, or from MarketInfo if the Markets is not set.
+//
+// The price is only repriced away from the book: the buy price is never
+// raised and the sell price is never lowered.
+// If maxRetry is zero or less, it will set to DefaultPostOnlyRetry.
+// The treq is not modified.
+func (cl *Client) TradePostOnly(treq *TradeRequest, maxRetry int) (
+	tres *TradeResponse, err error,
+) {
+	logp := "TradePostOnly"
+
+	if treq == nil {
+		return nil, fmt.Errorf("%s: empty TradeRequest", logp)
+	}
+	if treq.Method != TradeMethodLimit {
+		return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeMethod)
+	}
+	if maxRetry <= 0 {
+		maxRetry = DefaultPostOnlyRetry
+	}
+
+	req := *treq
+	req.IsPostOnly = true
+
+	var tick *big.Rat
+	for x := 0; ; x++ {
+		switch req.Type {
+		case TradeTypeAsk:
+			tres, err = cl.TradeAsk(&req)
+		case TradeTypeBid:
+			tres, err = cl.TradeBid(&req)
+		default:
+			return nil, fmt.Errorf("%s: %w", logp, ErrInvalidTradeType)
+		}
+		if err == nil || !isPostOnlyRejected(err) {
+			break
+		}
+		if x == maxRetry {
+			return nil, fmt.Errorf("%s: after %d retries: %w",
+				logp, maxRetry, err)
+		}
+
+		if tick == nil {
+			tick, err = cl.priceTick(req.Pair)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", logp, err)
+			}
+		}
+		price, err := cl.bestOppositePrice(req.Pair, req.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", logp, err)
+		}
+
+		if req.Type == TradeTypeBid {
+			price.Sub(tick)
+			if price.IsLess(req.Price) {
+				req.Price = price
+			} else {
+				req.Price = big.SubRat(req.Price, tick)
+			}
+		} else {
+			price.Add(tick)
+			if price.IsGreater(req.Price) {
+				req.Price = price
+			} else {
+				req.Price = big.AddRat(req.Price, tick)
+			}
+		}
+		if !req.Price.IsGreaterThanZero() {
+			return nil, fmt.Errorf("%s: %w", logp, ErrInvalidPrice)
+		}
+	}
+	if err != nil {
+		return tres, fmt.Errorf("%s: %w", logp, err)
+	}
+	return tres, nil
+}
+
+// bestOppositePrice return the lowest ask price for buy, or the highest
+// bid price for sell.
+func (cl *Client) bestOppositePrice(pair Pair, tradeType string) (
+	price *big.Rat, err error,
+) {
+	depths, err := cl.MarketDepths(pair.String())
+	if err != nil {
+		return nil, err
+	}
+	levels := depths.Bids
+	if tradeType == TradeTypeBid {
+		levels = depths.Asks
+	}
+	if len(levels) > 0 && levels[0].Price != nil {
+		return big.NewRat(levels[0].Price), nil
+	}
+
+	tick, err := cl.MarketTicker(pair.String())
+	if err != nil {
+		return nil, err
+	}
+	price = tick.HighestBidPrice
+	if tradeType == TradeTypeBid {
+		price = tick.LowestAskPrice
+	}
+	if price == nil || !price.IsGreaterThanZero() {
+		return nil, fmt.Errorf("empty opposite price on %s", pair)
+	}
+	return big.NewRat(price), nil
+}
+
+// priceTick return the price tick of pair from Client Markets, or from
+// MarketInfo if the Markets is not set.
+func (cl *Client) priceTick(pair Pair) (tick *big.Rat, err error) {
+	if cl.Markets != nil {
+		info := cl.Markets.Get(pair)
+		if info == nil {
+			return nil, fmt.Errorf("%s: %w", pair, ErrInvalidPair)
+		}
+		return info.PriceTick(), nil
+	}
+
+	marketInfos, err := cl.MarketInfo()
+	if err != nil {
+		return nil, err
+	}
+	info := NewMarketRegistry(marketInfos).Get(pair)
+	if info == nil {
+		return nil, fmt.Errorf("%s: %w", pair, ErrInvalidPair)
+	}
+	return info.PriceTick(), nil
+}
+
+// isPostOnlyRejected return true if the err is the rejection of post-only
+// order from server.
+//
+// The API documentation does not define the error for rejected post-only
+// order, so the error is matched by the Name of ErrTradePostOnly, or by
+// the Name or Message that mention "post" and "only" in any case and
+// separator, for example "post-only", "POST_ONLY", or "post only".
+func isPostOnlyRejected(err error) bool {
+	var errServer *liberrors.E
+	if !errors.As(err, &errServer) {
+		return false
+	}
+	if errServer.Name == ErrTradePostOnly.Name {
+		return true
+	}
+	for _, v := range []string{errServer.Name, errServer.Message} {
+		v = strings.ToLower(v)
+		if strings.Contains(v, "post") && strings.Contains(v, "only") {
+			return true
+		}
+	}
+	return false
+}
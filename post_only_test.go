@@ -0,0 +1,105 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_TradePostOnly(t *testing.T) {
+	var prices []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APITradeBid:
+			err := req.ParseForm()
+			if err != nil {
+				t.Error(err)
+			}
+			prices = append(prices, req.PostForm.Get(ParamNamePrice))
+			switch len(prices) {
+			case 1:
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				_, _ = w.Write([]byte(`{"name":"ERR_TRADE_POST_ONLY"}`))
+				return
+			case 2:
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"name":"ERR_BAD_REQUEST","message":"Post only order would match"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":1}}}`))
+		case APIMarketDepths:
+			_, _ = w.Write([]byte(`{"data":{"asks":[{"price":"100"}],"bids":[]}}`))
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:           PairTokenomyIdk,
+		PricePrecision: 1,
+		IsActive:       true,
+	}})
+
+	treq := &TradeRequest{
+		Price:  big.NewRat(101),
+		Amount: big.NewRat(1),
+		Pair:   PairTokenomyIdk,
+		Type:   TradeTypeBid,
+		Method: TradeMethodLimit,
+	}
+
+	_, err = cl.TradePostOnly(treq, 1)
+	test.Assert(t, "max retry", true, err != nil && isPostOnlyRejected(err))
+
+	prices = nil
+	tres, err := cl.TradePostOnly(treq, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "order ID", int64(1), tres.Order.ID)
+	test.Assert(t, "prices", []string{"101", "99.9", "99.8"}, prices)
+	test.Assert(t, "treq.IsPostOnly", false, treq.IsPostOnly)
+}
+
+func TestIsPostOnlyRejected(t *testing.T) {
+	cases := []struct {
+		err error
+		exp bool
+	}{{
+		err: ErrTradePostOnly,
+		exp: true,
+	}, {
+		err: fmt.Errorf("TradeBid: %w", &liberrors.E{Name: "ERR_POST_ONLY"}),
+		exp: true,
+	}, {
+		err: &liberrors.E{Message: "order is post-only and would be matched"},
+		exp: true,
+	}, {
+		err: &liberrors.E{Message: "invalid price"},
+	}, {
+		err: ErrInvalidPrice,
+	}, {
+		err: errors.New("post-only"),
+	}}
+
+	for _, c := range cases {
+		test.Assert(t, c.err.Error(), c.exp, isPostOnlyRejected(c.err))
+	}
+}
@@ -0,0 +1,605 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of pre-trade risk checks.
+const (
+	RiskCheckNotional  = "max-order-notional"
+	RiskCheckPosition  = "max-position"
+	RiskCheckOpen      = "max-open-orders"
+	RiskCheckDailyLoss = "max-daily-loss"
+	RiskCheckPriceBand = "price-band"
+	RiskCheckFatFinger = "fat-finger"
+	RiskCheckBalance   = "available-balance"
+)
+
+const (
+	// fatFingerHistory define the number of last accepted order amounts
+	// on each pair used to detect fat-finger order.
+	fatFingerHistory = 20
+
+	// fatFingerMinHistory define the minimum number of accepted orders
+	// on pair before the fat-finger check is applied.
+	fatFingerMinHistory = 5
+)
+
+// ErrRiskLimit is the error wrapped by all RiskError, so the violation can
+// be checked using errors.Is.
+var ErrRiskLimit = errors.New("risk limit exceeded")
+
+// RiskError define the violation of pre-trade risk check.
+type RiskError struct {
+	// Limit contains the configured limit, and Value contains the
+	// value of order that exceed the limit.
+	Limit *big.Rat
+	Value *big.Rat
+
+	// Check contains the name of violated check, one of the RiskCheck
+	// constants.
+	Check string
+
+	Pair  Pair
+	Asset string
+}
+
+// Error return the description of violation.
+func (rerr *RiskError) Error() string {
+	subject := rerr.Pair.String()
+	if len(rerr.Asset) > 0 {
+		subject = rerr.Asset
+	}
+	return fmt.Sprintf("%s: %s: %s %s exceed limit %s", ErrRiskLimit,
+		rerr.Check, subject, rerr.Value, rerr.Limit)
+}
+
+// Unwrap return ErrRiskLimit.
+func (rerr *RiskError) Unwrap() error {
+	return ErrRiskLimit
+}
+
+// RiskLimits define the limits of pre-trade risk checks.
+// The check with empty limit is not applied.
+type RiskLimits struct {
+	// MaxOrderNotional define the maximum price × amount, in base
+	// asset, of single order per pair.
+	MaxOrderNotional map[Pair]*big.Rat
+
+	// MaxPosition define the maximum balance per asset after the order
+	// filled: the coin for "buy", or the base for "sell".
+	MaxPosition map[string]*big.Rat
+
+	// MaxDailyLoss define the maximum loss, recorded by RecordPnL, since
+	// the start of the day in UTC.
+	// Once reached, all new orders are rejected until the next day.
+	//
+	// The RiskEngine does not compute the profit and loss from the
+	// fills, since it does not know the cost of position.
+	// The application must call RecordPnL with the realized profit and
+	// loss, otherwise this limit is never reached.
+	MaxDailyLoss *big.Rat
+
+	// PriceBand define the maximum distance between the order price and
+	// the last price, in percentage of the last price, for example 5 for
+	// 5%.
+	PriceBand *big.Rat
+
+	// FatFingerMultiple define the maximum ratio between the order
+	// amount and the median amount of the last accepted orders on the
+	// same pair, for example 10.
+	FatFingerMultiple *big.Rat
+
+	// MaxOpenOrders define the maximum number of open orders.
+	// The open orders are set by Refresh, added from the placed
+	// orders that rest on the book, and removed by the cancel and the
+	// closed orders broadcast.
+	MaxOpenOrders int
+
+	// IsCheckBalance check that the available balance, the Balances
+	// minus FrozenBalances, is enough to place the order: the base for
+	// "buy", or the coin for "sell".
+	// The balances are set by Refresh, SetAssets, or from the User in
+	// TradeResponse.
+	IsCheckBalance bool
+}
+
+// RiskOptions define the options for RiskEngine.
+type RiskOptions struct {
+	// Client, optional, is the REST client used by Refresh to fetch the
+	// balances and open orders, and to fetch the last price of pair
+	// that is not known yet.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed, to remove the closed orders from
+	// the open orders.
+	// The handler is registered using AddOrdersClosedHandler.
+	// If its nil, the HandleOrdersClosed must be called manually.
+	WebSocket *WebSocketPrivate
+
+	Limits RiskLimits
+}
+
+// RiskEngine check each TradeRequest against the configured limits before
+// its sent to server.
+//
+// The engine is enforced on Client, for TradeAsk, TradeBid and TradeBulk,
+// and on WebSocketPrivate, for TradeAsk and TradeBid, by setting it on
+// their Risk field.
+// The violation is returned as *RiskError.
+type RiskEngine struct {
+	opts RiskOptions
+
+	assets     *UserAssets
+	lastPrices map[Pair]*big.Rat
+	amounts    map[Pair][]*big.Rat
+	dailyPnL   *big.Rat
+	pnlDay     string
+
+	// open contains the ID of open orders.
+	open map[int64]struct{}
+
+	locker sync.Mutex
+}
+
+// NewRiskEngine create new RiskEngine with the limits.
+func NewRiskEngine(opts RiskOptions) (risk *RiskEngine, err error) {
+	limits := opts.Limits
+	if limits.MaxOpenOrders < 0 {
+		return nil, errors.New("NewRiskEngine: invalid MaxOpenOrders")
+	}
+
+	risk = &RiskEngine{
+		opts:       opts,
+		lastPrices: make(map[Pair]*big.Rat),
+		amounts:    make(map[Pair][]*big.Rat),
+		dailyPnL:   big.NewRat(0),
+		open:       make(map[int64]struct{}),
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(risk.HandleOrdersClosed)
+	}
+	return risk, nil
+}
+
+// Check the order with type "buy" or "sell" against the limits.
+func (risk *RiskEngine) Check(tradeType string, treq *TradeRequest) (err error) {
+	return risk.checkOrders([]riskOrder{{tradeType, treq}}, 0)
+}
+
+// CheckBulk check all of the orders in TradeBulk against the limits, as if
+// they are placed together after the cancel items.
+func (risk *RiskEngine) CheckBulk(tbReq *TradeBulk) (err error) {
+	orders := make([]riskOrder, 0, len(tbReq.Orders))
+	for _, item := range tbReq.Orders {
+		treq := item.TradeRequest
+		if treq.Pair.IsEmpty() {
+			treq.Pair = tbReq.Pair
+		}
+		orders = append(orders, riskOrder{treq.Type, &treq})
+	}
+	return risk.checkOrders(orders, len(tbReq.Cancel))
+}
+
+// DailyPnL return the profit and loss recorded since the start of the day.
+func (risk *RiskEngine) DailyPnL() *big.Rat {
+	risk.locker.Lock()
+	defer risk.locker.Unlock()
+	risk.rollDay(time.Now())
+	return big.NewRat(risk.dailyPnL)
+}
+
+// Forget remove the orders from the open orders, for example after its
+// cancelled.
+// The Client and WebSocketPrivate call it automatically on cancel.
+func (risk *RiskEngine) Forget(ids ...int64) {
+	risk.locker.Lock()
+	for _, id := range ids {
+		delete(risk.open, id)
+	}
+	risk.locker.Unlock()
+}
+
+// HandleOrdersClosed remove the closed order from the open orders.
+// If the NewRiskEngine is created with WebSocket options, this method is
+// registered automatically.
+func (risk *RiskEngine) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+	risk.Forget(trade.ID)
+}
+
+// HandleTrade set the last price of pair from the public trade, usually
+// from the NotifTrades of WebSocketPublic.
+func (risk *RiskEngine) HandleTrade(trade *Trade) {
+	if trade == nil || trade.Price == nil || publicTradeVolume(trade) == nil {
+		return
+	}
+	risk.SetLastPrice(Pair(trade.Pair), trade.Price)
+}
+
+// RecordPnL add the realized profit, or loss if its negative, to the daily
+// profit and loss.
+func (risk *RiskEngine) RecordPnL(pnl *big.Rat) {
+	risk.locker.Lock()
+	risk.rollDay(time.Now())
+	risk.dailyPnL.Add(pnl)
+	risk.locker.Unlock()
+}
+
+// Refresh fetch the balances using UserInfo and the number of open orders
+// using UserOrdersOpen.
+func (risk *RiskEngine) Refresh() (err error) {
+	logp := "Refresh"
+
+	if risk.opts.Client == nil {
+		return fmt.Errorf("%s: empty Client", logp)
+	}
+	user, err := risk.opts.Client.UserInfo()
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+	pto, err := risk.opts.Client.UserOrdersOpen("")
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	risk.locker.Lock()
+	if user != nil && user.UserAssets != nil {
+		risk.assets = user.UserAssets.Copy()
+	}
+	risk.open = make(map[int64]struct{})
+	for _, trade := range pto.Trades() {
+		risk.open[trade.ID] = struct{}{}
+	}
+	risk.locker.Unlock()
+	return nil
+}
+
+// SetAssets set the balances used by the position and balance checks.
+func (risk *RiskEngine) SetAssets(assets *UserAssets) {
+	if assets == nil {
+		return
+	}
+	risk.locker.Lock()
+	risk.assets = assets.Copy()
+	risk.locker.Unlock()
+}
+
+// SetLastPrice set the last price of pair used by the price band check and
+// to compute the notional of "market" order.
+func (risk *RiskEngine) SetLastPrice(pair Pair, price *big.Rat) {
+	risk.locker.Lock()
+	risk.lastPrices[pair] = big.NewRat(price)
+	risk.locker.Unlock()
+}
+
+// riskOrder contains the order to be checked.
+type riskOrder struct {
+	tradeType string
+	treq      *TradeRequest
+}
+
+// checkOrders check the list of orders together, after the number of
+// cancel items.
+func (risk *RiskEngine) checkOrders(orders []riskOrder, cancels int) (err error) {
+	limits := risk.opts.Limits
+
+	prices := make([]*big.Rat, len(orders))
+	for x, order := range orders {
+		prices[x], err = risk.orderPrice(order.treq)
+		if err != nil {
+			return err
+		}
+	}
+
+	risk.locker.Lock()
+	defer risk.locker.Unlock()
+
+	if limits.MaxDailyLoss != nil {
+		risk.rollDay(time.Now())
+		loss := big.MulRat(risk.dailyPnL, -1)
+		if !loss.IsLess(limits.MaxDailyLoss) {
+			return &RiskError{
+				Limit: limits.MaxDailyLoss,
+				Value: loss,
+				Check: RiskCheckDailyLoss,
+			}
+		}
+	}
+	if limits.MaxOpenOrders > 0 {
+		open := len(risk.open) - cancels
+		if open < 0 {
+			open = 0
+		}
+		open += len(orders)
+		if open > limits.MaxOpenOrders {
+			return &RiskError{
+				Limit: big.NewRat(limits.MaxOpenOrders),
+				Value: big.NewRat(open),
+				Check: RiskCheckOpen,
+			}
+		}
+	}
+
+	var (
+		received = make(map[string]*big.Rat)
+		spent    = make(map[string]*big.Rat)
+	)
+	for x, order := range orders {
+		treq := order.treq
+		price := prices[x]
+		notional := big.MulRat(price, treq.Amount)
+
+		err = risk.checkOrder(order, price, notional)
+		if err != nil {
+			return err
+		}
+
+		coin, base := treq.Pair.Coin().String(), treq.Pair.Base().String()
+		if order.tradeType == TradeTypeBid {
+			received[coin] = big.AddRat(received[coin], treq.Amount)
+			spent[base] = big.AddRat(spent[base], notional)
+		} else {
+			received[base] = big.AddRat(received[base], notional)
+			spent[coin] = big.AddRat(spent[coin], treq.Amount)
+		}
+	}
+
+	for _, asset := range sortedAssets(received) {
+		limit := limits.MaxPosition[asset]
+		if limit == nil {
+			continue
+		}
+		position := big.AddRat(risk.balance(asset), received[asset])
+		if position.IsGreater(limit) {
+			return &RiskError{
+				Limit: limit,
+				Value: position,
+				Check: RiskCheckPosition,
+				Asset: asset,
+			}
+		}
+	}
+	if limits.IsCheckBalance {
+		for _, asset := range sortedAssets(spent) {
+			available := risk.available(asset)
+			if spent[asset].IsGreater(available) {
+				return &RiskError{
+					Limit: available,
+					Value: spent[asset],
+					Check: RiskCheckBalance,
+					Asset: asset,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkOrder check the limits of single order.
+// It must be called while holding the lock.
+func (risk *RiskEngine) checkOrder(order riskOrder, price, notional *big.Rat) error {
+	limits := risk.opts.Limits
+	treq := order.treq
+
+	limit := limits.MaxOrderNotional[treq.Pair]
+	if limit != nil && notional.IsGreater(limit) {
+		return &RiskError{
+			Limit: limit,
+			Value: notional,
+			Check: RiskCheckNotional,
+			Pair:  treq.Pair,
+		}
+	}
+
+	last := risk.lastPrices[treq.Pair]
+	if limits.PriceBand != nil && last != nil && treq.Price != nil {
+		distance := big.SubRat(price, last)
+		if distance.IsLessThanZero() {
+			distance = big.SubRat(last, price)
+		}
+		distance.Mul(100).Quo(last)
+		if distance.IsGreater(limits.PriceBand) {
+			return &RiskError{
+				Limit: limits.PriceBand,
+				Value: distance,
+				Check: RiskCheckPriceBand,
+				Pair:  treq.Pair,
+			}
+		}
+	}
+
+	amounts := risk.amounts[treq.Pair]
+	if limits.FatFingerMultiple != nil && len(amounts) >= fatFingerMinHistory {
+		sorted := make([]*big.Rat, len(amounts))
+		copy(sorted, amounts)
+		sort.Slice(sorted, func(x, y int) bool {
+			return sorted[x].IsLess(sorted[y])
+		})
+		limit := big.MulRat(sorted[len(sorted)/2], limits.FatFingerMultiple)
+		if treq.Amount.IsGreater(limit) {
+			return &RiskError{
+				Limit: limit,
+				Value: treq.Amount,
+				Check: RiskCheckFatFinger,
+				Pair:  treq.Pair,
+			}
+		}
+	}
+	return nil
+}
+
+// orderPrice return the order price, or the last price for order without
+// price.
+// The last price is fetched using MarketTicker if its not known yet and
+// the Client is set.
+func (risk *RiskEngine) orderPrice(treq *TradeRequest) (price *big.Rat, err error) {
+	limits := risk.opts.Limits
+
+	risk.locker.Lock()
+	last := risk.lastPrices[treq.Pair]
+	risk.locker.Unlock()
+
+	needLast := limits.PriceBand != nil ||
+		(treq.Price == nil && (len(limits.MaxOrderNotional) > 0 ||
+			len(limits.MaxPosition) > 0 || limits.IsCheckBalance))
+	if last == nil && needLast && risk.opts.Client != nil {
+		tick, err := risk.opts.Client.MarketTicker(treq.Pair.String())
+		if err != nil {
+			return nil, fmt.Errorf("RiskEngine: %s: %w", treq.Pair, err)
+		}
+		if tick.LastPrice != nil {
+			last = big.NewRat(tick.LastPrice)
+			risk.SetLastPrice(treq.Pair, last)
+		}
+	}
+
+	if treq.Price != nil {
+		return treq.Price, nil
+	}
+	if last == nil && needLast {
+		return nil, fmt.Errorf("RiskEngine: %s: unknown last price", treq.Pair)
+	}
+	return last, nil
+}
+
+// record the accepted orders, the ID of orders that rest on the book, and
+// the ID of cancelled orders.
+func (risk *RiskEngine) record(treqs []*TradeRequest, opened, cancelled []int64, user *UserAssets) {
+	risk.locker.Lock()
+	defer risk.locker.Unlock()
+
+	for _, id := range opened {
+		risk.open[id] = struct{}{}
+	}
+	for _, id := range cancelled {
+		delete(risk.open, id)
+	}
+	for _, treq := range treqs {
+		amounts := append(risk.amounts[treq.Pair], big.NewRat(treq.Amount))
+		if len(amounts) > fatFingerHistory {
+			amounts = amounts[len(amounts)-fatFingerHistory:]
+		}
+		risk.amounts[treq.Pair] = amounts
+	}
+	if user != nil && len(user.Balances) > 0 {
+		risk.assets = user.Copy()
+	}
+}
+
+// available return the Balances minus FrozenBalances of asset.
+// It must be called while holding the lock.
+func (risk *RiskEngine) available(asset string) *big.Rat {
+	if risk.assets == nil {
+		return big.NewRat(0)
+	}
+	return big.SubRat(risk.assets.Balances[asset], risk.assets.FrozenBalances[asset])
+}
+
+// balance return the Balances of asset.
+// It must be called while holding the lock.
+func (risk *RiskEngine) balance(asset string) *big.Rat {
+	if risk.assets == nil {
+		return big.NewRat(0)
+	}
+	return big.NewRat(risk.assets.Balances[asset])
+}
+
+// rollDay reset the daily profit and loss when the day changes.
+// It must be called while holding the lock.
+func (risk *RiskEngine) rollDay(now time.Time) {
+	day := now.UTC().Format("2006-01-02")
+	if day != risk.pnlDay {
+		risk.pnlDay = day
+		risk.dailyPnL = big.NewRat(0)
+	}
+}
+
+// checkRisk check the order using the risk engine, if its set.
+func checkRisk(risk *RiskEngine, api string, treq *TradeRequest) error {
+	if risk == nil {
+		return nil
+	}
+	tradeType := TradeTypeAsk
+	if api == APITradeBid {
+		tradeType = TradeTypeBid
+	}
+	return risk.Check(tradeType, treq)
+}
+
+// recordRisk record the placed order into risk engine, if its set.
+// The order is counted as open only if its rest on the book: it has no
+// status and has remaining amount.
+func recordRisk(risk *RiskEngine, treq *TradeRequest, tres *TradeResponse) {
+	if risk == nil || tres == nil {
+		return
+	}
+	var opened []int64
+	order := tres.Order
+	if order != nil && len(order.Status) == 0 &&
+		order.CoinRemain != nil && order.CoinRemain.IsGreaterThanZero() {
+		opened = append(opened, order.ID)
+	}
+	risk.record([]*TradeRequest{treq}, opened, nil, tres.User.UserAssets)
+}
+
+// forgetRisk remove the cancelled orders from risk engine, if its set.
+func forgetRisk(risk *RiskEngine, ids ...int64) {
+	if risk == nil {
+		return
+	}
+	risk.Forget(ids...)
+}
+
+// recordRiskBulk record the orders and cancel items in TradeBulk into risk
+// engine, if its set.
+// Since the bulk response does not contains the order status, the limit
+// orders are counted as open until they are closed.
+func recordRiskBulk(risk *RiskEngine, tbReq, tbRes *TradeBulk) {
+	if risk == nil || tbRes == nil {
+		return
+	}
+	var (
+		treqs     []*TradeRequest
+		opened    []int64
+		cancelled []int64
+	)
+	for _, itemResult := range mapBulkItems(tbReq.Orders, tbRes.Orders, nil) {
+		if itemResult.Err != nil {
+			continue
+		}
+		treqs = append(treqs, &itemResult.Request.TradeRequest)
+		if itemResult.Request.Method != TradeMethodMarket {
+			opened = append(opened, itemResult.ID)
+		}
+	}
+	for _, itemResult := range mapBulkItems(tbReq.Cancel, tbRes.Cancel, nil) {
+		if itemResult.Err == nil {
+			cancelled = append(cancelled, itemResult.Request.ID)
+		}
+	}
+	risk.record(treqs, opened, cancelled, nil)
+}
+
+// sortedAssets return the keys of map ordered by name.
+func sortedAssets(m map[string]*big.Rat) (assets []string) {
+	for asset := range m {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+	return assets
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestRiskEngine(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		err    error
+	)
+
+	cl.Risk, err = NewRiskEngine(RiskOptions{
+		Limits: RiskLimits{
+			MaxOrderNotional: map[Pair]*big.Rat{
+				PairTokenomyIdk: big.NewRat(1000),
+			},
+			MaxPosition: map[string]*big.Rat{
+				"ten": big.NewRat(17),
+			},
+			MaxDailyLoss:      big.NewRat(50),
+			PriceBand:         big.NewRat(5),
+			FatFingerMultiple: big.NewRat(3),
+			MaxOpenOrders:     8,
+			IsCheckBalance:    true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assets := NewUserAssets()
+	assets.Balances["idk"] = big.NewRat(2000)
+	assets.FrozenBalances["idk"] = big.NewRat(1500)
+	assets.Balances["ten"] = big.NewRat(10)
+	cl.Risk.SetAssets(assets)
+	cl.Risk.SetLastPrice(PairTokenomyIdk, big.NewRat(100))
+
+	newBid := func(amount, price int64) *TradeRequest {
+		return &TradeRequest{
+			Amount: big.NewRat(amount),
+			Price:  big.NewRat(price),
+			Pair:   PairTokenomyIdk,
+			Method: TradeMethodLimit,
+		}
+	}
+
+	cases := []struct {
+		treq     *TradeRequest
+		expCheck string
+	}{{
+		treq:     newBid(11, 100),
+		expCheck: RiskCheckNotional,
+	}, {
+		treq:     newBid(1, 106),
+		expCheck: RiskCheckPriceBand,
+	}, {
+		treq:     newBid(6, 100),
+		expCheck: RiskCheckBalance,
+	}, {
+		treq: newBid(1, 100),
+	}}
+
+	for _, c := range cases {
+		_, err = cl.TradeBid(c.treq)
+		var rerr *RiskError
+		if !errors.As(err, &rerr) {
+			test.Assert(t, "error", c.expCheck, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		test.Assert(t, "error", c.expCheck, rerr.Check)
+		test.Assert(t, "errors.Is", true, errors.Is(err, ErrRiskLimit))
+	}
+
+	// Fill the fat-finger history.
+	assets.FrozenBalances["idk"] = big.NewRat(0)
+	cl.Risk.SetAssets(assets)
+	for x := 0; x < fatFingerMinHistory-1; x++ {
+		_, err = cl.TradeBid(newBid(1, 100))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = cl.TradeBid(newBid(4, 100))
+	test.Assert(t, "fat-finger", RiskCheckFatFinger, riskCheckOf(err))
+
+	// There are 5 open orders now.
+	tbReq := &TradeBulk{
+		Pair: PairTokenomyIdk,
+	}
+	for x := 0; x < 4; x++ {
+		tbReq.Orders = append(tbReq.Orders, &BulkOrderItem{
+			TradeRequest: TradeRequest{
+				Type:   TradeTypeBid,
+				Amount: big.NewRat(3),
+				Price:  big.NewRat(100),
+				Method: TradeMethodLimit,
+			},
+		})
+	}
+	_, err = cl.TradeBulk(tbReq)
+	test.Assert(t, "bulk open orders", RiskCheckOpen, riskCheckOf(err))
+
+	// The position on "ten" is 10 + 3*3.
+	tbReq.Orders = tbReq.Orders[:3]
+	_, err = cl.TradeBulk(tbReq)
+	test.Assert(t, "bulk position", RiskCheckPosition, riskCheckOf(err))
+
+	tbReq.Orders = tbReq.Orders[:2]
+	_, err = cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeBulk(tbReq)
+	test.Assert(t, "bulk open orders", RiskCheckOpen, riskCheckOf(err))
+
+	// The closed order that is not ours does not change the open
+	// orders.
+	cl.Risk.HandleOrdersClosed(&Trade{ID: -1, Status: TradeStatusFilled})
+	_, err = cl.TradeBulk(tbReq)
+	test.Assert(t, "closed unknown order", RiskCheckOpen, riskCheckOf(err))
+
+	cl.Risk.HandleOrdersClosed(ex.close(ex.open()[0].ID, TradeStatusFilled))
+	_, err = cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The cancelled order is removed from the open orders.
+	_, err = cl.TradeBulk(tbReq)
+	test.Assert(t, "bulk open orders", RiskCheckOpen, riskCheckOf(err))
+	for _, order := range ex.open()[:2] {
+		_, err = cl.TradeCancel(&order)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cl.Risk.RecordPnL(big.NewRat(-50))
+	_, err = cl.TradeAsk(newBid(1, 100))
+	test.Assert(t, "daily loss", RiskCheckDailyLoss, riskCheckOf(err))
+}
+
+func riskCheckOf(err error) string {
+	var rerr *RiskError
+	if errors.As(err, &rerr) {
+		return rerr.Check
+	}
+	return ""
+}
@@ -0,0 +1,53 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"io"
+)
+
+// secretRedacted is the text printed in place of Secret value.
+const secretRedacted = "[REDACTED]"
+
+// Secret contains the private part of API key.
+//
+// The Secret value is never printed by the fmt package, log package, or
+// encoding/json; all of them will print "[REDACTED]" instead.
+// To get the actual value, convert it to string explicitly,
+//
+//	s := string(secret)
+type Secret string
+
+// Format implement the fmt.Formatter interface.
+// It will always print "[REDACTED]" for any verb.
+func (secret Secret) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, secret.String())
+}
+
+// GoString implement the fmt.GoStringer interface.
+func (secret Secret) GoString() string {
+	return secret.String()
+}
+
+// IsEmpty return true if the secret is not set.
+func (secret Secret) IsEmpty() bool {
+	return len(secret) == 0
+}
+
+// MarshalJSON implement the json.Marshaler interface.
+// It will always encode the secret as "[REDACTED]".
+func (secret Secret) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + secret.String() + `"`), nil
+}
+
+// String return "[REDACTED]" if the secret is not empty, otherwise it will
+// return empty string.
+func (secret Secret) String() string {
+	if len(secret) == 0 {
+		return ""
+	}
+	return secretRedacted
+}
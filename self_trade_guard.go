@@ -0,0 +1,493 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of self-trade prevention policies.
+const (
+	// SelfTradeReject reject the new order with ErrSelfTrade.
+	SelfTradeReject = "reject"
+
+	// SelfTradeCancelResting cancel our open orders that would be
+	// matched by the new order, before the new order is placed.
+	SelfTradeCancelResting = "cancel-resting"
+
+	// SelfTradeAdjustPrice move the price of new order one tick away
+	// from our nearest open order on the opposite side, so it does not
+	// cross them.
+	// The "market" order can not be adjusted and is rejected.
+	SelfTradeAdjustPrice = "adjust-price"
+)
+
+// SelfTradeOptions define the options for SelfTradeGuard.
+type SelfTradeOptions struct {
+	// Client, required, is the REST client used to fetch the open
+	// orders, to cancel the resting orders, and to fetch the price tick.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed, to remove the closed orders from
+	// the tracked open orders.
+	// The handler is registered using AddOrdersClosedHandler.
+	// If its nil, the HandleOrdersClosed must be called manually.
+	WebSocket *WebSocketPrivate
+
+	// Policies, optional, define the policy per pair.
+	Policies map[Pair]string
+
+	// DefaultPolicy, optional, define the policy for pair that is not
+	// in Policies.
+	// Default to SelfTradeReject.
+	DefaultPolicy string
+}
+
+// SelfTradeGuard prevent the new orders from being matched with our own
+// open orders on the same account.
+//
+// The open orders are loaded by Refresh using UserOrdersOpen, and then
+// tracked from the orders placed, cancelled, and closed.
+// The guard is enforced on Client, for TradeAsk, TradeBid and TradeBulk,
+// and on WebSocketPrivate, for TradeAsk and TradeBid, by setting it on
+// their SelfTrade field.
+// The guard is run last, after the order has been validated, so the
+// resting orders are not cancelled for order that would be rejected.
+type SelfTradeGuard struct {
+	opts   SelfTradeOptions
+	orders map[int64]*Trade
+
+	locker sync.Mutex
+}
+
+// NewSelfTradeGuard create new SelfTradeGuard.
+func NewSelfTradeGuard(opts SelfTradeOptions) (guard *SelfTradeGuard, err error) {
+	logp := "NewSelfTradeGuard"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if len(opts.DefaultPolicy) == 0 {
+		opts.DefaultPolicy = SelfTradeReject
+	}
+	if !isSelfTradePolicy(opts.DefaultPolicy) {
+		return nil, fmt.Errorf("%s: invalid policy %q", logp, opts.DefaultPolicy)
+	}
+	for pair, policy := range opts.Policies {
+		if !isSelfTradePolicy(policy) {
+			return nil, fmt.Errorf("%s: %s: invalid policy %q", logp, pair, policy)
+		}
+	}
+
+	guard = &SelfTradeGuard{
+		opts:   opts,
+		orders: make(map[int64]*Trade),
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(guard.HandleOrdersClosed)
+	}
+	return guard, nil
+}
+
+// Check the new order with type "buy" or "sell" against our open orders
+// and apply the policy of its pair.
+// It return the TradeRequest to be placed, which is the copy of treq with
+// new price if the policy is SelfTradeAdjustPrice.
+func (guard *SelfTradeGuard) Check(tradeType string, treq *TradeRequest) (
+	out *TradeRequest, err error,
+) {
+	return guard.check(tradeType, treq, false)
+}
+
+// check the new order against our open orders.
+// On dry-run, the crossed orders on SelfTradeCancelResting are logged
+// instead of cancelled, so the dry-run order does not change the live
+// orders and the tracked open orders.
+func (guard *SelfTradeGuard) check(tradeType string, treq *TradeRequest, isDryRun bool) (
+	out *TradeRequest, err error,
+) {
+	crossed := guard.crossed(tradeType, treq, nil)
+	if len(crossed) == 0 {
+		return treq, nil
+	}
+
+	switch guard.Policy(treq.Pair) {
+	case SelfTradeCancelResting:
+		for _, order := range crossed {
+			if isDryRun {
+				log.Printf("DRY-RUN: SelfTradeGuard: cancel %s %s order %d",
+					order.Pair, order.Type, order.ID)
+				continue
+			}
+			_, err = guard.opts.Client.TradeCancel(order)
+			if err != nil {
+				return nil, fmt.Errorf("SelfTradeGuard: cancel %d: %w", order.ID, err)
+			}
+			guard.Forget(order.ID)
+		}
+		return treq, nil
+
+	case SelfTradeAdjustPrice:
+		price, err := guard.adjustPrice(tradeType, treq, crossed)
+		if err != nil {
+			return nil, err
+		}
+		if price == nil {
+			break
+		}
+		out = &TradeRequest{}
+		*out = *treq
+		out.Price = price
+		return out, nil
+	}
+
+	return nil, errSelfTrade(treq, crossed)
+}
+
+// CheckBulk check the orders in TradeBulk against our open orders, except
+// the one that is cancelled in the same request, and apply the policy of
+// its pair.
+// Unlike Check, the crossed orders on SelfTradeCancelResting are not
+// cancelled directly but added into the Cancel items, so the check does
+// not have any side effect.
+// It return the TradeBulk to be placed, which is the copy of tbReq if
+// any of its items is changed.
+func (guard *SelfTradeGuard) CheckBulk(tbReq *TradeBulk) (out *TradeBulk, err error) {
+	cancelled := make(map[int64]struct{}, len(tbReq.Cancel))
+	for _, item := range tbReq.Cancel {
+		cancelled[item.ID] = struct{}{}
+	}
+
+	out = tbReq
+	for x, item := range tbReq.Orders {
+		treq := item.TradeRequest
+		if treq.Pair.IsEmpty() {
+			treq.Pair = tbReq.Pair
+		}
+		crossed := guard.crossed(treq.Type, &treq, cancelled)
+		if len(crossed) == 0 {
+			continue
+		}
+		if out == tbReq {
+			out = &TradeBulk{}
+			*out = *tbReq
+			out.Orders = append([]*BulkOrderItem(nil), tbReq.Orders...)
+			out.Cancel = append([]*BulkOrderItem(nil), tbReq.Cancel...)
+		}
+
+		switch guard.Policy(treq.Pair) {
+		case SelfTradeCancelResting:
+			for _, order := range crossed {
+				out.Cancel = append(out.Cancel, &BulkOrderItem{
+					TradeRequest: TradeRequest{
+						Pair: Pair(order.Pair),
+						Type: order.Type,
+					},
+					ID: order.ID,
+				})
+				cancelled[order.ID] = struct{}{}
+			}
+			continue
+
+		case SelfTradeAdjustPrice:
+			price, err := guard.adjustPrice(treq.Type, &treq, crossed)
+			if err != nil {
+				return nil, err
+			}
+			if price == nil {
+				break
+			}
+			adjusted := &BulkOrderItem{}
+			*adjusted = *item
+			adjusted.Price = price
+			out.Orders[x] = adjusted
+			continue
+		}
+
+		return nil, errSelfTrade(&treq, crossed)
+	}
+	return out, nil
+}
+
+// Forget remove the orders from tracked open orders.
+// The Client and WebSocketPrivate call it automatically on cancel.
+func (guard *SelfTradeGuard) Forget(ids ...int64) {
+	guard.locker.Lock()
+	for _, id := range ids {
+		delete(guard.orders, id)
+	}
+	guard.locker.Unlock()
+}
+
+// HandleOrdersClosed remove the closed order from tracked open orders.
+// If the NewSelfTradeGuard is created with WebSocket options, this method
+// is registered automatically.
+func (guard *SelfTradeGuard) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+	guard.Forget(trade.ID)
+}
+
+// Orders return the copy of tracked open orders ordered by ID.
+func (guard *SelfTradeGuard) Orders() (orders []*Trade) {
+	guard.locker.Lock()
+	for _, order := range guard.orders {
+		order := *order
+		orders = append(orders, &order)
+	}
+	guard.locker.Unlock()
+
+	sort.Slice(orders, func(x, y int) bool {
+		return orders[x].ID < orders[y].ID
+	})
+	return orders
+}
+
+// Policy return the policy for pair.
+func (guard *SelfTradeGuard) Policy(pair Pair) string {
+	policy := guard.opts.Policies[pair]
+	if len(policy) == 0 {
+		return guard.opts.DefaultPolicy
+	}
+	return policy
+}
+
+// Record add the open order into tracked open orders.
+// The order that has been closed or does not have price is ignored.
+func (guard *SelfTradeGuard) Record(order *Trade) {
+	if order == nil || order.ID <= 0 || order.Price == nil || len(order.Status) > 0 {
+		return
+	}
+	if order.CoinRemain != nil && !order.CoinRemain.IsGreaterThanZero() {
+		return
+	}
+	order = &Trade{
+		Price:  big.NewRat(order.Price),
+		Pair:   order.Pair,
+		Type:   order.Type,
+		Method: order.Method,
+		ID:     order.ID,
+	}
+
+	guard.locker.Lock()
+	guard.orders[order.ID] = order
+	guard.locker.Unlock()
+}
+
+// Refresh replace the tracked open orders with the open orders from server
+// using UserOrdersOpen.
+func (guard *SelfTradeGuard) Refresh() (err error) {
+	pto, err := guard.opts.Client.UserOrdersOpen("")
+	if err != nil {
+		return fmt.Errorf("Refresh: %w", err)
+	}
+
+	orders := make(map[int64]*Trade)
+	for pair, tradesOpen := range pto {
+		for _, order := range tradesOpen.Asks {
+			order := order
+			order.Pair, order.Type = pair, TradeTypeAsk
+			orders[order.ID] = &order
+		}
+		for _, order := range tradesOpen.Bids {
+			order := order
+			order.Pair, order.Type = pair, TradeTypeBid
+			orders[order.ID] = &order
+		}
+	}
+
+	guard.locker.Lock()
+	guard.orders = orders
+	guard.locker.Unlock()
+	return nil
+}
+
+// adjustPrice return the price of new order one tick away from the
+// nearest crossed order, or nil if the order can not be adjusted.
+func (guard *SelfTradeGuard) adjustPrice(
+	tradeType string, treq *TradeRequest, crossed []*Trade,
+) (price *big.Rat, err error) {
+	if treq.Price == nil {
+		return nil, nil
+	}
+	tick, err := guard.opts.Client.priceTick(treq.Pair)
+	if err != nil {
+		return nil, fmt.Errorf("SelfTradeGuard: %w", err)
+	}
+	// The crossed orders is sorted from the nearest price.
+	price = big.NewRat(crossed[0].Price)
+	if tradeType == TradeTypeBid {
+		price.Sub(tick)
+	} else {
+		price.Add(tick)
+	}
+	if !price.IsGreaterThanZero() {
+		return nil, nil
+	}
+	return price, nil
+}
+
+// crossed return our open orders on the opposite side that would be
+// matched by the new order, sorted from the nearest price.
+// The orders in skip are excluded.
+func (guard *SelfTradeGuard) crossed(
+	tradeType string, treq *TradeRequest, skip map[int64]struct{},
+) (crossed []*Trade) {
+	guard.locker.Lock()
+	for _, order := range guard.orders {
+		if Pair(order.Pair) != treq.Pair || order.Type == tradeType {
+			continue
+		}
+		if _, ok := skip[order.ID]; ok {
+			continue
+		}
+		if treq.Price != nil {
+			if tradeType == TradeTypeBid && order.Price.IsGreater(treq.Price) {
+				continue
+			}
+			if tradeType == TradeTypeAsk && order.Price.IsLess(treq.Price) {
+				continue
+			}
+		}
+		order := *order
+		crossed = append(crossed, &order)
+	}
+	guard.locker.Unlock()
+
+	sort.Slice(crossed, func(x, y int) bool {
+		if tradeType == TradeTypeBid {
+			return crossed[x].Price.IsLess(crossed[y].Price)
+		}
+		return crossed[x].Price.IsGreater(crossed[y].Price)
+	})
+	return crossed
+}
+
+func errSelfTrade(treq *TradeRequest, crossed []*Trade) error {
+	return fmt.Errorf("%w: %s %s order %d at %s", ErrSelfTrade,
+		treq.Pair, crossed[0].Type, crossed[0].ID, crossed[0].Price)
+}
+
+func isSelfTradePolicy(policy string) bool {
+	switch policy {
+	case SelfTradeReject, SelfTradeCancelResting, SelfTradeAdjustPrice:
+		return true
+	}
+	return false
+}
+
+// checkSelfTrade check the order using the self-trade guard, if its set.
+// The order with adjusted price is checked again by the risk engine.
+func checkSelfTrade(
+	guard *SelfTradeGuard, risk *RiskEngine, api string, treq *TradeRequest, isDryRun bool,
+) (out *TradeRequest, err error) {
+	if guard == nil {
+		return treq, nil
+	}
+	tradeType := TradeTypeAsk
+	if api == APITradeBid {
+		tradeType = TradeTypeBid
+	}
+	out, err = guard.check(tradeType, treq, isDryRun)
+	if err != nil {
+		return nil, err
+	}
+	if out != treq {
+		err = checkRisk(risk, api, out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// checkSelfTradeBulk check the orders in TradeBulk using the self-trade
+// guard, if its set.
+// The changed TradeBulk is checked again by the risk engine.
+func checkSelfTradeBulk(guard *SelfTradeGuard, risk *RiskEngine, tbReq *TradeBulk) (
+	out *TradeBulk, err error,
+) {
+	if guard == nil {
+		return tbReq, nil
+	}
+	out, err = guard.CheckBulk(tbReq)
+	if err != nil {
+		return nil, err
+	}
+	if out != tbReq && risk != nil {
+		err = risk.CheckBulk(out)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// forgetSelfTrade remove the orders from self-trade guard, if its set.
+func forgetSelfTrade(guard *SelfTradeGuard, ids ...int64) {
+	if guard == nil {
+		return
+	}
+	guard.Forget(ids...)
+}
+
+// recordSelfTrade record the placed order into self-trade guard, if its
+// set.
+func recordSelfTrade(guard *SelfTradeGuard, api string, treq *TradeRequest, tres *TradeResponse) {
+	if guard == nil || tres == nil || tres.Order == nil {
+		return
+	}
+	order := *tres.Order
+	if len(order.Pair) == 0 {
+		order.Pair = treq.Pair.String()
+	}
+	if order.Price == nil {
+		order.Price = treq.Price
+	}
+	if len(order.Type) == 0 {
+		order.Type = TradeTypeAsk
+		if api == APITradeBid {
+			order.Type = TradeTypeBid
+		}
+	}
+	guard.Record(&order)
+}
+
+// recordSelfTradeBulk record the placed limit orders and remove the
+// cancelled orders in TradeBulk from self-trade guard, if its set.
+func recordSelfTradeBulk(guard *SelfTradeGuard, tbReq, tbRes *TradeBulk) {
+	if guard == nil || tbRes == nil {
+		return
+	}
+	for _, itemResult := range mapBulkItems(tbReq.Orders, tbRes.Orders, nil) {
+		treq := itemResult.Request.TradeRequest
+		if itemResult.Err != nil || treq.Method == TradeMethodMarket {
+			continue
+		}
+		if treq.Pair.IsEmpty() {
+			treq.Pair = tbReq.Pair
+		}
+		guard.Record(&Trade{
+			Price:  treq.Price,
+			Pair:   treq.Pair.String(),
+			Type:   treq.Type,
+			Method: treq.Method,
+			ID:     itemResult.ID,
+		})
+	}
+	for _, itemResult := range mapBulkItems(tbReq.Cancel, tbRes.Cancel, nil) {
+		if itemResult.Err == nil {
+			guard.Forget(itemResult.Request.ID)
+		}
+	}
+}
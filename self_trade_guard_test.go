@@ -0,0 +1,276 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestSelfTradeGuard(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		err    error
+	)
+
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:     PairTokenomyIdk,
+		IsActive: true,
+	}, {
+		Pair:     PairBitcoinIdk,
+		IsActive: true,
+	}})
+
+	cl.SelfTrade, err = NewSelfTradeGuard(SelfTradeOptions{
+		Client: cl,
+		Policies: map[Pair]string{
+			PairBitcoinIdk: SelfTradeCancelResting,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newOrder := func(pair Pair, amount, price int64) *TradeRequest {
+		return &TradeRequest{
+			Amount: big.NewRat(amount),
+			Price:  big.NewRat(price),
+			Pair:   pair,
+			Method: TradeMethodLimit,
+		}
+	}
+
+	_, err = cl.TradeAsk(newOrder(PairTokenomyIdk, 1, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeAsk(newOrder(PairTokenomyIdk, 1, 102))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The bid below our asks is not crossed.
+	_, err = cl.TradeBid(newOrder(PairTokenomyIdk, 1, 99))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cl.TradeBid(newOrder(PairTokenomyIdk, 1, 101))
+	test.Assert(t, "reject", true, errors.Is(err, ErrSelfTrade))
+
+	cl.SelfTrade.opts.Policies[PairTokenomyIdk] = SelfTradeAdjustPrice
+	tres, err := cl.TradeBid(newOrder(PairTokenomyIdk, 1, 101))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "adjusted price", "99", tres.Order.Price.String())
+
+	_, err = cl.TradeBid(&TradeRequest{
+		Amount: big.NewRat(1),
+		Pair:   PairTokenomyIdk,
+		Method: TradeMethodMarket,
+	})
+	test.Assert(t, "adjust market", true, errors.Is(err, ErrSelfTrade))
+
+	// Cancel the resting bid before placing the crossing ask.
+	bid, err := cl.TradeBid(newOrder(PairBitcoinIdk, 1, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeAsk(newOrder(PairBitcoinIdk, 1, 490))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, order := range cl.SelfTrade.Orders() {
+		if order.ID == bid.Order.ID {
+			t.Fatalf("resting bid %d is not cancelled", bid.Order.ID)
+		}
+	}
+	for _, order := range ex.open() {
+		if order.ID == bid.Order.ID {
+			t.Fatalf("resting bid %d is still open on server", bid.Order.ID)
+		}
+	}
+	test.Assert(t, "open orders", 5, len(cl.SelfTrade.Orders()))
+
+	cl.SelfTrade.HandleOrdersClosed(ex.close(bid.Order.ID+1, TradeStatusFilled))
+	test.Assert(t, "open orders", 4, len(cl.SelfTrade.Orders()))
+
+	// The order rejected by risk engine does not cancel the resting
+	// order.
+	cl.Risk, err = NewRiskEngine(RiskOptions{
+		Limits: RiskLimits{
+			MaxOrderNotional: map[Pair]*big.Rat{
+				PairBitcoinIdk: big.NewRat(1000),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeBid(newOrder(PairBitcoinIdk, 1, 500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cl.TradeAsk(newOrder(PairBitcoinIdk, 10, 490))
+	var errRisk *RiskError
+	test.Assert(t, "risk rejected", true, errors.As(err, &errRisk))
+	test.Assert(t, "open orders", 5, len(cl.SelfTrade.Orders()))
+}
+
+func TestSelfTradeGuard_TradeBulk(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		ws     = &WebSocketPrivate{}
+		err    error
+	)
+
+	cl.Markets = NewMarketRegistry([]MarketInfo{{
+		Pair:     PairTokenomyIdk,
+		IsActive: true,
+	}})
+
+	cl.SelfTrade, err = NewSelfTradeGuard(SelfTradeOptions{
+		Client:    cl,
+		WebSocket: ws,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ask, err := cl.TradeAsk(&TradeRequest{
+		Amount: big.NewRat(1),
+		Price:  big.NewRat(100),
+		Pair:   PairTokenomyIdk,
+		Method: TradeMethodLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newBulk := func(price int64) *TradeBulk {
+		return &TradeBulk{
+			Pair: PairTokenomyIdk,
+			Orders: []*BulkOrderItem{{
+				TradeRequest: TradeRequest{
+					Amount: big.NewRat(1),
+					Price:  big.NewRat(price),
+					Type:   TradeTypeBid,
+					Method: TradeMethodLimit,
+				},
+			}},
+		}
+	}
+
+	_, err = cl.TradeBulk(newBulk(101))
+	test.Assert(t, "reject", true, errors.Is(err, ErrSelfTrade))
+
+	// The ask that is cancelled in the same request is not crossed.
+	tbReq := newBulk(101)
+	tbReq.Cancel = []*BulkOrderItem{{
+		TradeRequest: TradeRequest{Type: TradeTypeAsk},
+		ID:           ask.Order.ID,
+	}}
+	tbRes, err := cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bid := tbRes.Orders[0].ID
+	test.Assert(t, "orders", []int64{bid}, orderIDs(cl.SelfTrade.Orders()))
+
+	cl.SelfTrade.opts.DefaultPolicy = SelfTradeAdjustPrice
+	tbReq = newBulk(99)
+	tbReq.Orders[0].Type = TradeTypeAsk
+	tbRes, err = cl.TradeBulk(tbReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "request is not changed", "99", tbReq.Orders[0].Price.String())
+	for _, order := range ex.open() {
+		if order.ID == tbRes.Orders[0].ID {
+			test.Assert(t, "adjusted price", "102", order.Price.String())
+		}
+	}
+
+	cl.SelfTrade.opts.DefaultPolicy = SelfTradeCancelResting
+	tbRes, err = cl.TradeBulk(newBulk(103))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "resting ask is cancelled", 1, len(tbRes.Cancel))
+	test.Assert(t, "orders", []int64{bid, tbRes.Orders[0].ID},
+		orderIDs(cl.SelfTrade.Orders()))
+
+	// The filled order is removed by the registered handler.
+	ws.handleOrdersClosed(ex.close(bid, TradeStatusFilled))
+	test.Assert(t, "orders", []int64{tbRes.Orders[0].ID},
+		orderIDs(cl.SelfTrade.Orders()))
+
+	_, err = cl.TradeCancelAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "orders", []int64(nil), orderIDs(cl.SelfTrade.Orders()))
+}
+
+func orderIDs(orders []*Trade) (ids []int64) {
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	return ids
+}
+
+func TestSelfTradeGuard_dryRun(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		err    error
+	)
+
+	cl.SelfTrade, err = NewSelfTradeGuard(SelfTradeOptions{
+		Client:        cl,
+		DefaultPolicy: SelfTradeCancelResting,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bid, err := cl.TradeBid(&TradeRequest{
+		Amount: big.NewRat(1),
+		Price:  big.NewRat(500),
+		Pair:   PairBitcoinIdk,
+		Method: TradeMethodLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cl.env.IsDryRun = true
+
+	_, err = cl.TradeAsk(&TradeRequest{
+		Amount: big.NewRat(1),
+		Price:  big.NewRat(490),
+		Pair:   PairBitcoinIdk,
+		Method: TradeMethodLimit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "cancel requests", 0, ex.count(APITradeCancelBid))
+	test.Assert(t, "open on server", 1, len(ex.open()))
+	test.Assert(t, "orders", []int64{bid.Order.ID}, orderIDs(cl.SelfTrade.Orders()))
+
+	ws := &WebSocketPrivate{
+		env:       cl.env,
+		SelfTrade: cl.SelfTrade,
+	}
+	_, err = ws.TradeCancelBid(PairBitcoinIdk, bid.Order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "orders after cancel", []int64{bid.Order.ID}, orderIDs(cl.SelfTrade.Orders()))
+}
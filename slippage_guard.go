@@ -0,0 +1,90 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// SlippageGuard define the protection of market orders on Client.
+//
+// When its set on Client, each "market" order on TradeAsk and TradeBid is
+// converted into "limit" order with protective price, the worst price
+// level in MarketDepths that is within the MaxSlippage from the best
+// price.
+// The order is rejected with ErrTradeSlippage, before sending it to
+// server, if the market depths can not fill the order within the
+// protective price.
+type SlippageGuard struct {
+	// MaxSlippage, required, is the maximum distance between the best
+	// price and the protective price, in percentage of the best price,
+	// for example 0.5 for 0.5%.
+	MaxSlippage *big.Rat
+
+	// IsImmediateOrCancel, optional, send the protected order with
+	// TimeInForce "IOC" instead of "FOK", so the amount that can be
+	// filled within the protective price is executed and the rest is
+	// cancelled.
+	// In this mode, the order is rejected only if none of its amount
+	// can be filled.
+	IsImmediateOrCancel bool
+}
+
+// protectMarketOrder return the copy of market order converted into limit
+// order by the Client Slippage guard.
+// If the guard is not set or the order is not "market", it return the
+// treq as is.
+func (cl *Client) protectMarketOrder(api string, treq *TradeRequest) (
+	protected *TradeRequest, err error,
+) {
+	guard := cl.Slippage
+	if guard == nil || !strings.EqualFold(treq.Method, TradeMethodMarket) {
+		return treq, nil
+	}
+
+	logp := "SlippageGuard"
+	if guard.MaxSlippage == nil || guard.MaxSlippage.IsLessThanZero() {
+		return nil, fmt.Errorf("%s: invalid MaxSlippage", logp)
+	}
+	if treq.Amount == nil || !treq.Amount.IsGreaterThanZero() {
+		return nil, ErrInvalidAmount
+	}
+
+	tradeType := TradeTypeAsk
+	if api == APITradeBid {
+		tradeType = TradeTypeBid
+	}
+
+	depths, err := cl.MarketDepths(treq.Pair.String())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	tolerance := big.QuoRat(guard.MaxSlippage, 100)
+	within := depths.MaxAmountWithinImpact(tradeType, tolerance)
+	if !within.IsFilled {
+		return nil, fmt.Errorf("%s: empty market depths: %w", logp,
+			ErrTradeSlippage)
+	}
+	if !guard.IsImmediateOrCancel && within.Coin.IsLess(treq.Amount) {
+		return nil, fmt.Errorf("%s: %s of %s can be filled within %s%%: %w",
+			logp, within.Coin, treq.Amount, guard.MaxSlippage,
+			ErrTradeSlippage)
+	}
+
+	protected = &TradeRequest{}
+	*protected = *treq
+	protected.Type = tradeType
+	protected.Method = TradeMethodLimit
+	protected.Price = within.WorstPrice
+	protected.TimeInForce = TimeInForceFOK
+	if guard.IsImmediateOrCancel {
+		protected.TimeInForce = TimeInForceIOC
+	}
+	return protected, nil
+}
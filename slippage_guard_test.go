@@ -0,0 +1,84 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_SlippageGuard(t *testing.T) {
+	var (
+		gotParams url.Values
+		cancelled int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APIMarketDepths:
+			_, _ = w.Write([]byte(`{"data":{"asks":[` +
+				`{"price":"100","total_coin":"1"},` +
+				`{"price":"101","total_coin":"2"},` +
+				`{"price":"103","total_coin":"5"}],"bids":[]}}`))
+		case APITradeBid:
+			err := req.ParseForm()
+			if err != nil {
+				t.Error(err)
+			}
+			gotParams = req.PostForm
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":1,"coin_filled":"3"}}}`))
+		case APITradeCancelBid:
+			cancelled++
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":1,"status":"cancelled"}}}`))
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl.Slippage = &SlippageGuard{
+		MaxSlippage: big.NewRat(1),
+	}
+
+	treq := &TradeRequest{
+		Amount: big.NewRat(2),
+		Pair:   PairTokenomyIdk,
+		Method: TradeMethodMarket,
+	}
+	_, err = cl.TradeBid(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "method", TradeMethodLimit, gotParams.Get(ParamNameTradeMethod))
+	test.Assert(t, "price", "101", gotParams.Get(ParamNamePrice))
+	test.Assert(t, "time_in_force", TimeInForceFOK, gotParams.Get(ParamNameTimeInForce))
+	test.Assert(t, "treq.Method", TradeMethodMarket, treq.Method)
+
+	gotParams = nil
+	treq.Amount = big.NewRat(5)
+	_, err = cl.TradeBid(treq)
+	test.Assert(t, "ErrTradeSlippage", true, errors.Is(err, ErrTradeSlippage))
+	test.Assert(t, "not sent", url.Values(nil), gotParams)
+
+	cl.Slippage.IsImmediateOrCancel = true
+	tres, err := cl.TradeBid(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "IOC price", "101", gotParams.Get(ParamNamePrice))
+	test.Assert(t, "IOC status", TradeStatusCancelled, tres.Order.Status)
+	test.Assert(t, "IOC cancelled", 1, cancelled)
+}
@@ -0,0 +1,203 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of StopTrigger kinds.
+const (
+	// StopKindStopLoss trigger the order when the last price move
+	// against the position: drop to or below the StopPrice for "sell",
+	// or rise to or above the StopPrice for "buy".
+	StopKindStopLoss = "stop-loss"
+
+	// StopKindTakeProfit trigger the order when the last price move in
+	// favor of the position: rise to or above the StopPrice for
+	// "sell", or drop to or below the StopPrice for "buy".
+	StopKindTakeProfit = "take-profit"
+
+	// StopKindTrailing trigger the order when the last price retrace
+	// from its high-water mark, for "sell", or from its low-water mark,
+	// for "buy", by the trail distance.
+	StopKindTrailing = "trailing-stop"
+)
+
+// StopTrigger define an order that will be submitted by TriggerEngine when
+// the last price crossed the StopPrice.
+//
+// If the Request Method is "limit", the trigger act as stop-limit order
+// using the Request Price; if the Method is "market", the trigger act as
+// stop-market order.
+//
+// For StopKindTrailing, the StopPrice is ignored, and the stop price
+// follow the WaterMark by the TrailAmount or TrailPercent distance.
+type StopTrigger struct {
+	// StopPrice, required except for trailing stop, is the price level
+	// that trigger the order.
+	StopPrice *big.Rat `json:"stop_price,omitempty"`
+
+	// TrailAmount, required for trailing stop if TrailPercent is not
+	// set, define the absolute distance between WaterMark and the stop
+	// price.
+	TrailAmount *big.Rat `json:"trail_amount,omitempty"`
+
+	// TrailPercent, required for trailing stop if TrailAmount is not
+	// set, define the distance between WaterMark and the stop price in
+	// percentage of WaterMark, for example 2.5 for 2.5%.
+	TrailPercent *big.Rat `json:"trail_percent,omitempty"`
+
+	// WaterMark contains the highest price, for "sell", or the lowest
+	// price, for "buy", since the trailing stop armed.
+	// If its nil when armed, it will be set to the last price.
+	WaterMark *big.Rat `json:"water_mark,omitempty"`
+
+	// Request, required, is the order to be submitted when triggered.
+	Request TradeRequest `json:"request"`
+
+	// ID of trigger.
+	// If its empty, it will be generated when the trigger armed.
+	ID string `json:"id"`
+
+	// Kind of trigger, its either StopKindStopLoss, StopKindTakeProfit,
+	// or StopKindTrailing.
+	Kind string `json:"kind"`
+
+	// IsIntercepted, optional, if its true the trigger only fired if
+	// the interceptor with the same ID has been registered using
+	// TriggerEngine.Intercept, otherwise it is kept armed.
+	// It is used by the trigger that is owned by other component, for
+	// example OCOManager, so the trigger restored from file is not
+	// fired before its owner is ready.
+	IsIntercepted bool `json:"is_intercepted,omitempty"`
+
+	// OrderID contains the ID of submitted order, after the trigger
+	// fired.
+	OrderID int64 `json:"order_id,omitempty"`
+
+	// ArmedAt contains the time when the trigger armed, in Unix
+	// seconds.
+	ArmedAt int64 `json:"armed_at"`
+
+	// FiredAt contains the time when the order of trigger submitted,
+	// in Unix seconds.
+	FiredAt int64 `json:"fired_at,omitempty"`
+
+	// Attempts contains the number of failed submission since the
+	// trigger armed.
+	Attempts int `json:"attempts,omitempty"`
+
+	// retryAt define the time when the trigger can be fired again
+	// after failed submission.
+	retryAt time.Time
+}
+
+// CurrentStopPrice return the price level that trigger the order.
+// For trailing stop, it is computed from the WaterMark and trail distance.
+func (trigger *StopTrigger) CurrentStopPrice() *big.Rat {
+	if trigger.Kind != StopKindTrailing {
+		return trigger.StopPrice
+	}
+	if trigger.WaterMark == nil {
+		return nil
+	}
+
+	distance := trigger.TrailAmount
+	if distance == nil {
+		distance = big.MulRat(trigger.WaterMark, trigger.TrailPercent)
+		distance.Quo(100)
+	}
+	if trigger.Request.Type == TradeTypeAsk {
+		return big.SubRat(trigger.WaterMark, distance)
+	}
+	return big.AddRat(trigger.WaterMark, distance)
+}
+
+// IsTriggered return true if the price crossed the stop price.
+func (trigger *StopTrigger) IsTriggered(price *big.Rat) bool {
+	stopPrice := trigger.CurrentStopPrice()
+	if price == nil || stopPrice == nil {
+		return false
+	}
+	isSell := trigger.Request.Type == TradeTypeAsk
+	isStopLoss := trigger.Kind != StopKindTakeProfit
+
+	if isSell == isStopLoss {
+		// stop-loss on sell or take-profit on buy.
+		return price.IsLessOrEqual(stopPrice)
+	}
+	return !price.IsLess(stopPrice)
+}
+
+// updateWaterMark set the WaterMark of trailing stop if the price is
+// higher, for "sell", or lower, for "buy".
+// It return true if the WaterMark changes.
+func (trigger *StopTrigger) updateWaterMark(price *big.Rat) bool {
+	if trigger.Kind != StopKindTrailing || price == nil {
+		return false
+	}
+	if trigger.WaterMark != nil {
+		if trigger.Request.Type == TradeTypeAsk {
+			if !price.IsGreater(trigger.WaterMark) {
+				return false
+			}
+		} else if !price.IsLess(trigger.WaterMark) {
+			return false
+		}
+	}
+	trigger.WaterMark = big.NewRat(price)
+	return true
+}
+
+// validate the trigger fields.
+func (trigger *StopTrigger) validate() (err error) {
+	switch trigger.Kind {
+	case StopKindStopLoss, StopKindTakeProfit:
+		if trigger.StopPrice == nil || !trigger.StopPrice.IsGreaterThanZero() {
+			return errors.New("invalid StopPrice")
+		}
+	case StopKindTrailing:
+		err = trigger.validateTrail()
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("invalid Kind %q", trigger.Kind)
+	}
+	switch trigger.Request.Type {
+	case TradeTypeAsk, TradeTypeBid:
+	default:
+		return ErrInvalidTradeType
+	}
+
+	treq := trigger.Request
+	_, _, err = treq.Pack()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateTrail validate the trail distance of trailing stop.
+func (trigger *StopTrigger) validateTrail() error {
+	if (trigger.TrailAmount == nil) == (trigger.TrailPercent == nil) {
+		return errors.New("one of TrailAmount or TrailPercent must be set")
+	}
+	if trigger.TrailAmount != nil && !trigger.TrailAmount.IsGreaterThanZero() {
+		return errors.New("invalid TrailAmount")
+	}
+	if trigger.TrailPercent != nil {
+		if !trigger.TrailPercent.IsGreaterThanZero() ||
+			!trigger.TrailPercent.IsLess(100) {
+			return errors.New("invalid TrailPercent")
+		}
+	}
+	return nil
+}
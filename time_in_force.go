@@ -0,0 +1,135 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// orderCanceller define the common method to cancel an order on Client and
+// WebSocketPrivate.
+type orderCanceller interface {
+	TradeCancel(trade *Trade) (*Trade, error)
+}
+
+// checkTimeInForce validate the TimeInForce before the order send to
+// server.
+// The TimeInForce is only applicable for "limit" order.
+func checkTimeInForce(treq *TradeRequest, gtt *GTTScheduler) (err error) {
+	err = checkTimeInForceMethod(treq)
+	if err != nil {
+		return err
+	}
+	if treq.TimeInForce != TimeInForceGTT {
+		return nil
+	}
+	if gtt == nil {
+		return errors.New("TimeInForce GTT: empty GTT scheduler")
+	}
+	if treq.ExpireAt.IsZero() || !treq.ExpireAt.After(time.Now()) {
+		return errors.New("TimeInForce GTT: invalid ExpireAt")
+	}
+	return nil
+}
+
+// checkTimeInForceMethod return an error if the TimeInForce is set on
+// order with method other than "limit".
+// The empty Method is "limit".
+func checkTimeInForceMethod(treq *TradeRequest) (err error) {
+	if len(treq.TimeInForce) == 0 || len(treq.Method) == 0 {
+		return nil
+	}
+	if !strings.EqualFold(treq.Method, TradeMethodLimit) {
+		return fmt.Errorf("TimeInForce %s: not applicable for %s order",
+			treq.TimeInForce, treq.Method)
+	}
+	return nil
+}
+
+// checkBulkTimeInForce validate the TimeInForce of order in TradeBulk.
+// The IOC and GTT are emulated by client only on the single order, so
+// they are rejected in bulk order.
+func checkBulkTimeInForce(treq *TradeRequest) (err error) {
+	if treq.isEmulatedTimeInForce() {
+		return fmt.Errorf("TimeInForce %s: not supported in bulk order",
+			treq.TimeInForce)
+	}
+	return checkTimeInForceMethod(treq)
+}
+
+// applyTimeInForce apply the emulated TimeInForce after the order has been
+// placed.
+//
+// For IOC, the remaining amount that is not matched in
+// TradeResponse.Trades is cancelled immediately and the tres.Order is
+// updated with the cancelled status.
+//
+// For GTT, the order is scheduled to be cancelled at treq.ExpireAt.
+func applyTimeInForce(
+	canceller orderCanceller, gtt *GTTScheduler, treq *TradeRequest,
+	tres *TradeResponse,
+) (err error) {
+	if tres == nil || tres.Order == nil {
+		return nil
+	}
+
+	order := tres.Order
+	if len(order.Pair) == 0 {
+		order.Pair = treq.Pair.String()
+	}
+	if len(order.Type) == 0 {
+		order.Type = treq.Type
+	}
+	if len(order.Status) > 0 {
+		// The order has been closed.
+		return nil
+	}
+
+	switch treq.TimeInForce {
+	case TimeInForceIOC:
+		filled := matchedAmount(tres.Trades)
+		if order.CoinFilled != nil && order.CoinFilled.IsGreater(filled) {
+			filled = big.NewRat(order.CoinFilled)
+		}
+		remain := big.SubRat(treq.Amount, filled)
+		order.CoinFilled = filled
+		order.CoinRemain = remain
+		if !remain.IsGreaterThanZero() {
+			order.Status = TradeStatusFilled
+			return nil
+		}
+
+		_, err = canceller.TradeCancel(order)
+		if err != nil {
+			return fmt.Errorf("TimeInForce IOC: cancel order %d: %w",
+				order.ID, err)
+		}
+		order.Status = TradeStatusCancelled
+		order.FinishTime = timestamp()
+
+	case TimeInForceGTT:
+		err = gtt.Schedule(order, treq.ExpireAt)
+		if err != nil {
+			return fmt.Errorf("TimeInForce GTT: %w", err)
+		}
+	}
+	return nil
+}
+
+// matchedAmount return the total coin amount of matched trades.
+func matchedAmount(trades []Trade) (total *big.Rat) {
+	total = big.NewRat(0)
+	for _, trade := range trades {
+		if trade.CoinAmount != nil {
+			total.Add(trade.CoinAmount)
+		}
+	}
+	return total
+}
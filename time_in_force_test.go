@@ -0,0 +1,171 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestClient_TradeBid_IOC(t *testing.T) {
+	var (
+		gotTimeInForce = "-"
+		gotCancelID    string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case APITradeBid:
+			err := req.ParseForm()
+			if err != nil {
+				t.Error(err)
+			}
+			gotTimeInForce = req.PostForm.Get(ParamNameTimeInForce)
+			_, _ = w.Write([]byte(`{"data":{` +
+				`"order":{"id":7,"pair":"btc_idk","type":"buy","price":"100","coin_amount":"3"},` +
+				`"trades":[{"coin_amount":"0.5"},{"coin_amount":"0.5"}]}}`))
+		case APITradeCancelBid:
+			gotCancelID = req.URL.Query().Get(ParamNameTradeID)
+			_, _ = w.Write([]byte(`{"data":{"order":{"id":7,"status":"cancelled"}}}`))
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treq, err := NewOrderBuilder(PairBitcoinIdk).
+		Bid().Limit(100).Amount(3).ImmediateOrCancel().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tres, err := cl.TradeBid(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test.Assert(t, "time_in_force param", "", gotTimeInForce)
+	test.Assert(t, "cancelled ID", "7", gotCancelID)
+	test.Assert(t, "Status", TradeStatusCancelled, tres.Order.Status)
+	test.Assert(t, "CoinFilled", "1", tres.Order.CoinFilled.String())
+	test.Assert(t, "CoinRemain", "2", tres.Order.CoinRemain.String())
+}
+
+func TestClient_TradeAsk_GTT(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	treq, err := NewOrderBuilder(PairBitcoinIdk).
+		Ask().Limit(100).Amount(1).GoodTillTime(time.Now().Add(time.Minute)).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cl.TradeAsk(treq)
+	if err == nil {
+		t.Fatal("want error on empty GTT scheduler")
+	}
+
+	var (
+		path    = filepath.Join(t.TempDir(), "gtt.json")
+		expired []int64
+		opts    = GTTSchedulerOptions{
+			Client: cl,
+			Path:   path,
+			HandleExpired: func(expiry GTTExpiry, cancelled *Trade, err error) {
+				if err != nil {
+					t.Error(err)
+				}
+				expired = append(expired, expiry.ID)
+			},
+		}
+	)
+
+	cl.GTT, err = NewGTTScheduler(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tres, err := cl.TradeAsk(treq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the scheduler from file.
+	cl.GTT, err = NewGTTScheduler(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := cl.GTT.Pending()
+	test.Assert(t, "Pending", 1, len(pending))
+	test.Assert(t, "Pending.ID", tres.Order.ID, pending[0].ID)
+
+	cl.GTT.CancelExpired(time.Now())
+	test.Assert(t, "not expired", 0, len(expired))
+
+	cl.GTT.CancelExpired(time.Now().Add(2 * time.Minute))
+	test.Assert(t, "expired", []int64{tres.Order.ID}, expired)
+	test.Assert(t, "Pending", 0, len(cl.GTT.Pending()))
+	test.Assert(t, "open orders", 0, len(ex.open()))
+}
+
+func TestTimeInForce_rejected(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	// TimeInForce on market order.
+	_, err := cl.TradeBid(&TradeRequest{
+		Pair:        PairBitcoinIdk,
+		Type:        TradeTypeBid,
+		Method:      TradeMethodMarket,
+		Amount:      big.NewRat(1),
+		TimeInForce: TimeInForceFOK,
+	})
+	if err == nil {
+		t.Fatal("want error on market order with TimeInForce")
+	}
+
+	// The emulated TimeInForce in bulk order.
+	for _, tif := range []string{TimeInForceIOC, TimeInForceGTT} {
+		ob := NewOrderBuilder(PairBitcoinIdk).Bid().Limit(100).Amount(1).
+			TimeInForce(tif)
+		_, err = ob.BuildBulkItem()
+		if err == nil {
+			t.Fatalf("BuildBulkItem: want error on %s", tif)
+		}
+
+		treq, err := ob.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = cl.TradeBulk(&TradeBulk{
+			Pair:   PairBitcoinIdk,
+			Orders: []*BulkOrderItem{{TradeRequest: *treq}},
+		})
+		if err == nil {
+			t.Fatalf("TradeBulk: want error on %s", tif)
+		}
+	}
+
+	item, err := NewOrderBuilder(PairBitcoinIdk).Bid().Limit(100).Amount(1).
+		FillOrKill().BuildBulkItem()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "FOK bulk item", TimeInForceFOK, item.TimeInForce)
+
+	test.Assert(t, "sent bids", 0, ex.count(APITradeBid))
+	test.Assert(t, "sent bulks", 0, ex.count(APITradeBulk))
+}
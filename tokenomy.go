@@ -10,6 +10,8 @@ import (
 	"encoding/hex"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -138,8 +140,14 @@ const (
 )
 
 // List of valid values for TradeRequest.TimeInForce.
+//
+// The IOC and GTT are emulated by client: the order is send as normal limit
+// order, and then cancelled immediately (IOC) or at TradeRequest.ExpireAt
+// (GTT).
 const (
 	TimeInForceFOK = "FOK" // Fill-or-Kill.
+	TimeInForceIOC = "IOC" // Immediate-or-Cancel.
+	TimeInForceGTT = "GTT" // Good-till-Time.
 )
 
 // List of valid trade's status.
@@ -152,6 +160,7 @@ const (
 const (
 	EnvNameAddress = "TOKENOMY_ADDRESS"
 	EnvNameDebug   = "TOKENOMY_DEBUG"
+	EnvNameDryRun  = "TOKENOMY_DRY_RUN"
 	EnvNameToken   = "TOKENOMY_TOKEN"
 	EnvNameSecret  = "TOKENOMY_SECRET"
 	EnvNameTestE2E = "TOKENOMY_TEST_E2E"
@@ -272,6 +281,25 @@ var (
 		Message: "not enough amount in the market to process fill-or-kill order",
 		Name:    "ERR_TRADE_FILL_OR_KILL",
 	}
+	ErrTradeSlippage = &liberrors.E{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "not enough amount in the market within the maximum slippage",
+		Name:    "ERR_TRADE_SLIPPAGE",
+	}
+	// ErrTradePostOnly is the error for rejected post-only order.
+	// The server error for it is not documented, the Name is defined by
+	// this module; the rejection is also detected from the server error
+	// that mention "post-only" in its name or message.
+	ErrTradePostOnly = &liberrors.E{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "post-only order would be matched immediately",
+		Name:    "ERR_TRADE_POST_ONLY",
+	}
+	ErrSelfTrade = &liberrors.E{
+		Code:    http.StatusUnprocessableEntity,
+		Message: "order would be matched with our own open order",
+		Name:    "ERR_SELF_TRADE",
+	}
 
 	ErrWalletAddress = &errors.E{
 		Code:    http.StatusBadRequest,
@@ -302,3 +330,26 @@ func timestamp() int64 {
 func timestampAsString() string {
 	return strconv.FormatInt(timestamp(), 10)
 }
+
+// writeFileAtomic write the content into temporary file in the same
+// directory and rename it to path, so the file is never partially written.
+func writeFileAtomic(path string, content []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if err == nil {
+		err = tmp.Close()
+	} else {
+		_ = tmp.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -6,7 +6,7 @@ package tokenomy
 
 // TradeBulk contains the request for bulk trading.
 type TradeBulk struct {
-	Pair      string           `json:"pair"`
+	Pair      Pair             `json:"pair"`
 	Orders    []*BulkOrderItem `json:"orders"`
 	Cancel    []*BulkOrderItem `json:"cancel"`
 	Timestamp int64            `json:"timestamp"`
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/shuLhan/share/lib/math/big"
 )
@@ -25,13 +26,14 @@ type TradeRequest struct {
 	Method string `json:"method,omitempty"`
 
 	// Pair name using "<coin>_<base>" format.
-	Pair string `json:"pair"`
+	Pair Pair `json:"pair"`
 
 	// TimeInForce parameter only applicable if Method is "limit".
 	// This option may change the behaviour of order "limit" processed by
 	// broker.
-	// Currently, the valid values are empty "" (default) or "FOK"
-	// (fill-or-kill).
+	// Currently, the valid values are empty "" (default), "FOK"
+	// (fill-or-kill), "IOC" (immediate-or-cancel), or "GTT"
+	// (good-till-time).
 	//
 	// If its empty, the order request processed normally as "limit"
 	// request.
@@ -39,12 +41,36 @@ type TradeRequest struct {
 	// If its "FOK", the order will be success only if only all of
 	// requested amount is fulfilled, otherwise it will return as an error
 	// ErrTradeFillOrKill.
+	//
+	// If its "IOC", the order is send as normal "limit" request and the
+	// remaining amount that is not filled immediately is cancelled by
+	// client.
+	//
+	// If its "GTT", the order is send as normal "limit" request and
+	// cancelled by client GTTScheduler at ExpireAt.
+	//
+	// If the IOC or GTT can not be applied after the order has been
+	// placed, the trade response is returned along with the error.
+	// The IOC and GTT are not supported in TradeBulk.
 	TimeInForce string `json:"time_in_force,omitempty"`
 
 	// IsPostOnly parameter only applicable if Method is "limit".
 	// If its true, the order will be success if only if no matching
-	// trades happened, otherwise it will return an error.
+	// trades happened, otherwise the server return an error.
+	// Use Client.TradePostOnly to detect the rejection, reprice, and
+	// retry the rejected order.
 	IsPostOnly bool `json:"post_only,omitempty"`
+
+	// ClientOrderID, optional, is the local ID of order defined by
+	// client.
+	// It is not send to server, instead its recorded in the
+	// ClientOrderStore of Client or WebSocketPrivate after the order has
+	// been placed.
+	ClientOrderID string `json:"-"`
+
+	// ExpireAt define the time when the order with TimeInForce "GTT"
+	// is cancelled.
+	ExpireAt time.Time `json:"-"`
 }
 
 // Pack the TradeRequest object to be send by REST and/or WebSocket client.
@@ -63,26 +89,48 @@ func (treq *TradeRequest) Pack() (
 		}
 	}
 
+	err = treq.Pair.Validate()
+	if err != nil {
+		return nil, nil, err
+	}
 	if treq.Amount == nil || treq.Amount.IsLessOrEqual(0) {
 		return nil, nil, ErrInvalidAmount
 	}
 
 	params.Set(ParamNameTradeMethod, treq.Method)
-	params.Set(ParamNamePair, treq.Pair)
+	params.Set(ParamNamePair, treq.Pair.String())
 	params.Set(ParamNameAmount, treq.Amount.String())
 
 	wsparams = &WebSocketParams{
 		TradeRequest: *treq,
 	}
+	if treq.isEmulatedTimeInForce() {
+		// The emulated TimeInForce is interpreted by client, not
+		// by server.
+		wsparams.TimeInForce = ""
+	}
 
 	if treq.Method == TradeMethodLimit {
 		if treq.Price == nil || treq.Price.IsLessOrEqual(0) {
 			return nil, nil, ErrInvalidPrice
 		}
 		params.Set(ParamNamePrice, treq.Price.String())
+		if len(treq.TimeInForce) > 0 && !treq.isEmulatedTimeInForce() {
+			params.Set(ParamNameTimeInForce, treq.TimeInForce)
+		}
 	}
 
 	params.Set(ParamNamePostOnly, fmt.Sprintf("%t", treq.IsPostOnly))
 
 	return params, wsparams, nil
 }
+
+// isEmulatedTimeInForce return true if the TimeInForce is emulated by
+// client.
+func (treq *TradeRequest) isEmulatedTimeInForce() bool {
+	switch treq.TimeInForce {
+	case TimeInForceIOC, TimeInForceGTT:
+		return true
+	}
+	return false
+}
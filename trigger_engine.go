@@ -0,0 +1,816 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	liberrors "github.com/shuLhan/share/lib/errors"
+	"github.com/shuLhan/share/lib/math/big"
+)
+
+// List of default values for TriggerEngineOptions.
+const (
+	// DefaultTriggerMaxAttempts define the default number of failed
+	// submission before the trigger is dropped.
+	DefaultTriggerMaxAttempts = 5
+
+	// DefaultTriggerRetryInterval define the default delay before the
+	// trigger fired again after its first failed submission.
+	DefaultTriggerRetryInterval = time.Second
+
+	// DefaultTriggerFiredRetention define the default duration where
+	// the fired trigger wait for its order to be closed.
+	DefaultTriggerFiredRetention = 24 * time.Hour
+)
+
+// maxTriggerRetryInterval define the maximum delay between failed
+// submission of trigger.
+const maxTriggerRetryInterval = time.Minute
+
+// TriggerHandler define a callback that will be called after the trigger
+// fired and its order submitted.
+// The err parameter is non-nil if the order can not be submitted.
+// Both tres and err are nil if the order is skipped by TriggerInterceptor.
+type TriggerHandler func(trigger StopTrigger, tres *TradeResponse, err error)
+
+// TriggerInterceptor define a callback that will be called after the
+// trigger fired and before its order submitted.
+// It return the request to be submitted, which may be changed from the
+// trigger Request, or nil to skip the submission.
+// If it return an error, the order is not submitted and the trigger is
+// re-armed, the same as failed submission.
+type TriggerInterceptor func(trigger StopTrigger) (treq *TradeRequest, err error)
+
+// TriggerFillHandler define a callback that will be called when the order
+// from fired trigger is closed, either filled or cancelled.
+type TriggerFillHandler func(trigger StopTrigger, trade *Trade)
+
+// TriggerEngineOptions define the options for TriggerEngine.
+type TriggerEngineOptions struct {
+	// Client, required, is the REST client used to submit the order
+	// when the trigger fired, and to fetch the ticker if TickerInterval
+	// is set.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket where the closed
+	// orders broadcast is consumed to call HandleFilled.
+	// The handler is registered using AddOrdersClosedHandler.
+	WebSocket *WebSocketPrivate
+
+	// Public, optional, is the public WebSocket where the trades
+	// notification is consumed.
+	// If its set, the last price of each pair that have armed triggers
+	// is refreshed using Client.MarketTicker after the WebSocket
+	// reconnected, to catch the price movement while disconnected.
+	// The existing HandleReconnected, if any, is still called.
+	Public *WebSocketPublic
+
+	// HandleTriggered, optional, is the callback that will be called
+	// after the trigger fired.
+	HandleTriggered TriggerHandler
+
+	// HandleFilled, optional, is the callback that will be called when
+	// the order from fired trigger closed.
+	HandleFilled TriggerFillHandler
+
+	// Path, optional, is the file where the armed triggers are saved,
+	// so they are restored after restart.
+	Path string
+
+	// TickerInterval, optional, define the interval to fetch the last
+	// price using Client.MarketTicker for each pair that have armed
+	// triggers.
+	// If its zero, the price only updated from the trades notification
+	// or by calling UpdatePrice.
+	TickerInterval time.Duration
+
+	// MaxAttempts, optional, define the number of failed submission
+	// before the trigger is dropped.
+	// Default to DefaultTriggerMaxAttempts.
+	MaxAttempts int
+
+	// RetryInterval, optional, define the delay before the trigger can
+	// be fired again after its first failed submission.
+	// The delay is doubled on each failure, up to one minute.
+	// Default to DefaultTriggerRetryInterval.
+	RetryInterval time.Duration
+
+	// FiredRetention, optional, define how long the fired trigger is
+	// kept waiting for its order to be closed, to call HandleFilled.
+	// Default to DefaultTriggerFiredRetention.
+	FiredRetention time.Duration
+}
+
+// TriggerEngine watch the last traded price and submit the order of
+// StopTrigger when the price crossed its StopPrice.
+//
+// The price is updated from the NotifTrades channel of WebSocketPublic
+// passed to Start, from the Client.MarketTicker if TickerInterval is set,
+// or manually by calling UpdatePrice.
+//
+// Each trigger is disarmed before its order submitted.
+// If the order can not be submitted, the trigger is re-armed and fired
+// again on the next price update after the RetryInterval, which is
+// doubled on each failure.
+// The trigger is dropped if the server reject the request, with status
+// code 4xx except 408, 409, 422 and 429, or after MaxAttempts failures.
+//
+// The fired triggers are saved along with the armed one until their
+// order closed or until FiredRetention passed.
+type TriggerEngine struct {
+	opts TriggerEngineOptions
+
+	armed        map[string]*StopTrigger
+	fired        map[int64]*StopTrigger
+	prices       map[Pair]*big.Rat
+	subs         map[string][]*triggerSubscriber
+	interceptors map[string]TriggerInterceptor
+
+	done chan struct{}
+
+	locker sync.Mutex
+
+	isRunning bool
+}
+
+type triggerSubscriber struct {
+	handler TriggerHandler
+}
+
+// NewTriggerEngine create and initialize new TriggerEngine.
+// If the Path options is set, the armed triggers are loaded from it.
+func NewTriggerEngine(opts TriggerEngineOptions) (te *TriggerEngine, err error) {
+	logp := "NewTriggerEngine"
+
+	if opts.Client == nil {
+		return nil, fmt.Errorf("%s: empty Client", logp)
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultTriggerMaxAttempts
+	}
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = DefaultTriggerRetryInterval
+	}
+	if opts.FiredRetention <= 0 {
+		opts.FiredRetention = DefaultTriggerFiredRetention
+	}
+
+	te = &TriggerEngine{
+		opts:   opts,
+		armed:  make(map[string]*StopTrigger),
+		fired:  make(map[int64]*StopTrigger),
+		prices: make(map[Pair]*big.Rat),
+		subs:   make(map[string][]*triggerSubscriber),
+
+		interceptors: make(map[string]TriggerInterceptor),
+	}
+
+	err = te.load()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", logp, err)
+	}
+
+	if opts.WebSocket != nil {
+		opts.WebSocket.AddOrdersClosedHandler(te.HandleOrdersClosed)
+	}
+	if opts.Public != nil {
+		prev := opts.Public.HandleReconnected
+		opts.Public.HandleReconnected = func() {
+			te.Refresh()
+			if prev != nil {
+				prev()
+			}
+		}
+	}
+	return te, nil
+}
+
+// Arm add new trigger into engine and save it.
+// It return the ID of trigger.
+//
+// If the last price of the pair is known and already crossed the StopPrice,
+// the trigger will fired on the next price update.
+//
+// For trailing stop without WaterMark, the WaterMark is set to the last
+// known price of the pair, or to the last price from Client.MarketTicker.
+func (te *TriggerEngine) Arm(trigger StopTrigger) (id string, err error) {
+	logp := "Arm"
+
+	err = trigger.validate()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", logp, err)
+	}
+	if trigger.Kind == StopKindTrailing && trigger.WaterMark == nil {
+		price := te.LastPrice(trigger.Request.Pair)
+		if price == nil {
+			tick, err := te.opts.Client.MarketTicker(trigger.Request.Pair.String())
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", logp, err)
+			}
+			price = tick.LastPrice
+		}
+		if price == nil {
+			return "", fmt.Errorf("%s: unknown last price of %s", logp,
+				trigger.Request.Pair)
+		}
+		trigger.WaterMark = big.NewRat(price)
+	}
+	if len(trigger.ID) == 0 {
+		trigger.ID = NewClientOrderID()
+	}
+	trigger.ArmedAt = timestamp()
+	trigger.OrderID = 0
+	trigger.FiredAt = 0
+	trigger.Attempts = 0
+	trigger.retryAt = time.Time{}
+
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	if _, ok := te.armed[trigger.ID]; ok {
+		return "", fmt.Errorf("%s: duplicate ID %q", logp, trigger.ID)
+	}
+	te.armed[trigger.ID] = &trigger
+
+	err = te.save()
+	if err != nil {
+		delete(te.armed, trigger.ID)
+		return "", fmt.Errorf("%s: %w", logp, err)
+	}
+	return trigger.ID, nil
+}
+
+// Disarm remove the armed trigger by ID.
+// It return false if the trigger is not found.
+func (te *TriggerEngine) Disarm(id string) (ok bool, err error) {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	trigger := te.armed[id]
+	if trigger == nil {
+		return false, nil
+	}
+	delete(te.armed, id)
+
+	err = te.save()
+	if err != nil {
+		te.armed[id] = trigger
+		return false, fmt.Errorf("Disarm: %w", err)
+	}
+	return true, nil
+}
+
+// SetAmount change the order amount of armed trigger.
+// It return false if the trigger is not found.
+func (te *TriggerEngine) SetAmount(id string, amount *big.Rat) (ok bool, err error) {
+	if amount == nil || !amount.IsGreaterThanZero() {
+		return false, fmt.Errorf("SetAmount: %w", ErrInvalidAmount)
+	}
+
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	trigger := te.armed[id]
+	if trigger == nil {
+		return false, nil
+	}
+	prev := trigger.Request.Amount
+	trigger.Request.Amount = big.NewRat(amount)
+
+	err = te.save()
+	if err != nil {
+		trigger.Request.Amount = prev
+		return false, fmt.Errorf("SetAmount: %w", err)
+	}
+	return true, nil
+}
+
+// Intercept register the interceptor that will be called after the trigger
+// with specific ID fired and before its order submitted.
+// Only one interceptor can be registered for each trigger, the later
+// replace the former.
+// The interceptor is removed after the order submitted or skipped.
+// It return a function to remove the interceptor.
+func (te *TriggerEngine) Intercept(id string, interceptor TriggerInterceptor) (
+	remove func(),
+) {
+	te.locker.Lock()
+	te.interceptors[id] = interceptor
+	te.locker.Unlock()
+
+	return func() {
+		te.locker.Lock()
+		delete(te.interceptors, id)
+		te.locker.Unlock()
+	}
+}
+
+// Subscribe register the handler that will be called after the trigger
+// with specific ID fired, in addition to HandleTriggered options.
+// The handler is removed after the order of trigger submitted.
+// It return a function to unsubscribe the handler.
+func (te *TriggerEngine) Subscribe(id string, handler TriggerHandler) (
+	unsubscribe func(),
+) {
+	sub := &triggerSubscriber{
+		handler: handler,
+	}
+
+	te.locker.Lock()
+	te.subs[id] = append(te.subs[id], sub)
+	te.locker.Unlock()
+
+	return func() {
+		te.locker.Lock()
+		defer te.locker.Unlock()
+
+		list := te.subs[id]
+		for x, p := range list {
+			if p == sub {
+				te.subs[id] = append(list[:x], list[x+1:]...)
+				break
+			}
+		}
+		if len(te.subs[id]) == 0 {
+			delete(te.subs, id)
+		}
+	}
+}
+
+// Triggers return the list of armed triggers, ordered by the time they are
+// armed.
+func (te *TriggerEngine) Triggers() (triggers []StopTrigger) {
+	te.locker.Lock()
+	for _, trigger := range te.armed {
+		triggers = append(triggers, *trigger)
+	}
+	te.locker.Unlock()
+
+	sort.Slice(triggers, func(x, y int) bool {
+		if triggers[x].ArmedAt == triggers[y].ArmedAt {
+			return triggers[x].ID < triggers[y].ID
+		}
+		return triggers[x].ArmedAt < triggers[y].ArmedAt
+	})
+	return triggers
+}
+
+// HandleOrdersClosed consume the closed order broadcast from
+// WebSocketPrivate to call the HandleFilled callback.
+// If the NewTriggerEngine is created with WebSocket options, this method
+// is registered automatically.
+func (te *TriggerEngine) HandleOrdersClosed(trade *Trade) {
+	if trade == nil {
+		return
+	}
+
+	te.locker.Lock()
+	trigger := te.fired[trade.ID]
+	if trigger != nil {
+		delete(te.fired, trade.ID)
+		err := te.save()
+		if err != nil {
+			log.Printf("TriggerEngine: %s", err)
+		}
+	}
+	te.locker.Unlock()
+
+	if trigger != nil && te.opts.HandleFilled != nil {
+		te.opts.HandleFilled(*trigger, trade)
+	}
+}
+
+// HandleTrade update the last price of pair from the public trade
+// notification.
+// Only the trade that has been matched, with status "filled" or with
+// non-zero CoinFilled, is used.
+func (te *TriggerEngine) HandleTrade(trade *Trade) {
+	if trade == nil || trade.Price == nil {
+		return
+	}
+	isMatched := trade.Status == TradeStatusFilled ||
+		(trade.CoinFilled != nil && trade.CoinFilled.IsGreaterThanZero())
+	if !isMatched {
+		return
+	}
+	te.UpdatePrice(Pair(trade.Pair), trade.Price)
+}
+
+// LastPrice return the last known price of pair, or nil if its unknown.
+func (te *TriggerEngine) LastPrice(pair Pair) *big.Rat {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+	return copyRat(te.prices[pair])
+}
+
+// UpdatePrice set the last price of pair and fire all of the armed
+// triggers on that pair that crossed their StopPrice.
+// The trigger whose submission failed is not fired until its retry delay
+// passed.
+func (te *TriggerEngine) UpdatePrice(pair Pair, price *big.Rat) {
+	if price == nil {
+		return
+	}
+
+	var (
+		now       = time.Now()
+		triggered []*StopTrigger
+		isChanged bool
+	)
+
+	te.locker.Lock()
+	te.prices[pair] = big.NewRat(price)
+	for id, trigger := range te.armed {
+		if trigger.Request.Pair != pair {
+			continue
+		}
+		if trigger.updateWaterMark(price) {
+			isChanged = true
+		}
+		if !trigger.IsTriggered(price) || now.Before(trigger.retryAt) {
+			continue
+		}
+		triggered = append(triggered, trigger)
+		delete(te.armed, id)
+	}
+	if isChanged || len(triggered) > 0 {
+		err := te.save()
+		if err != nil {
+			log.Printf("TriggerEngine: %s", err)
+		}
+	}
+	te.locker.Unlock()
+
+	sort.Slice(triggered, func(x, y int) bool {
+		return triggered[x].ArmedAt < triggered[y].ArmedAt
+	})
+	for _, trigger := range triggered {
+		te.fire(trigger)
+	}
+}
+
+// Start watching the trades notification in the background.
+// The notif parameter is usually the NotifTrades field from
+// WebSocketPublic, and it may be nil if the price is updated from ticker
+// only.
+// Calling Start on running TriggerEngine has no effect.
+func (te *TriggerEngine) Start(notif <-chan Trade) {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	if te.isRunning {
+		return
+	}
+	te.isRunning = true
+	te.done = make(chan struct{})
+
+	go te.run(te.done, notif)
+}
+
+// Stop watching the price.
+// The armed triggers are kept.
+func (te *TriggerEngine) Stop() {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	if !te.isRunning {
+		return
+	}
+	close(te.done)
+	te.isRunning = false
+}
+
+// stopped mark the engine as not running if the done is from the current
+// Start, so it can be started again.
+func (te *TriggerEngine) stopped(done chan struct{}) {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	if te.isRunning && te.done == done {
+		close(te.done)
+		te.isRunning = false
+	}
+}
+
+func (te *TriggerEngine) run(done chan struct{}, notif <-chan Trade) {
+	var tickerC <-chan time.Time
+
+	if te.opts.TickerInterval > 0 {
+		ticker := time.NewTicker(te.opts.TickerInterval)
+		defer ticker.Stop()
+		tickerC = ticker.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		case trade, ok := <-notif:
+			if !ok {
+				// The notification channel is closed, for
+				// example the WebSocket has been closed.
+				te.stopped(done)
+				return
+			}
+			te.HandleTrade(&trade)
+		case <-tickerC:
+			te.Refresh()
+		}
+	}
+}
+
+// Refresh update the last price of each pair that have armed triggers
+// using Client.MarketTicker.
+func (te *TriggerEngine) Refresh() {
+	for _, pair := range te.armedPairs() {
+		tick, err := te.opts.Client.MarketTicker(pair.String())
+		if err != nil {
+			log.Printf("TriggerEngine: %s", err)
+			continue
+		}
+		te.UpdatePrice(pair, tick.LastPrice)
+	}
+}
+
+func (te *TriggerEngine) armedPairs() (pairs []Pair) {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	seen := make(map[Pair]bool)
+	for _, trigger := range te.armed {
+		if !seen[trigger.Request.Pair] {
+			seen[trigger.Request.Pair] = true
+			pairs = append(pairs, trigger.Request.Pair)
+		}
+	}
+	return pairs
+}
+
+// fire submit the order of trigger.
+//
+// If the trigger has interceptor, the order is submitted using the request
+// returned by interceptor, or skipped if its nil.
+// If the submission failed, the trigger is re-armed.
+func (te *TriggerEngine) fire(trigger *StopTrigger) {
+	var (
+		treq = trigger.Request
+		tres *TradeResponse
+		err  error
+	)
+
+	te.locker.Lock()
+	interceptor := te.interceptors[trigger.ID]
+	te.locker.Unlock()
+
+	if interceptor == nil && trigger.IsIntercepted {
+		log.Printf("TriggerEngine: %s: waiting for interceptor", trigger.ID)
+		te.rearm(trigger)
+		return
+	}
+	if interceptor != nil {
+		var req *TradeRequest
+
+		req, err = interceptor(*trigger)
+		if err != nil {
+			te.fail(trigger, err)
+			return
+		}
+		if req == nil {
+			log.Printf("TriggerEngine: %s: skipped", trigger.ID)
+			te.notify(*trigger, te.release(trigger.ID), nil, nil)
+			return
+		}
+		treq = *req
+	}
+	if len(treq.ClientOrderID) == 0 {
+		treq.ClientOrderID = trigger.ID
+	}
+
+	log.Printf("TriggerEngine: %s %s %s at %s", trigger.Kind, treq.Pair,
+		treq.Type, trigger.CurrentStopPrice())
+
+	if treq.Type == TradeTypeAsk {
+		tres, err = te.opts.Client.TradeAsk(&treq)
+	} else {
+		tres, err = te.opts.Client.TradeBid(&treq)
+	}
+	if err == nil && (tres == nil || tres.Order == nil) {
+		err = errors.New("empty order in response")
+	}
+	if err != nil {
+		te.fail(trigger, err)
+		return
+	}
+
+	subs := te.release(trigger.ID)
+
+	trigger.OrderID = tres.Order.ID
+	trigger.FiredAt = timestamp()
+	isClosed := len(tres.Order.Status) > 0
+	if !isClosed {
+		te.locker.Lock()
+		te.fired[trigger.OrderID] = trigger
+		err = te.save()
+		if err != nil {
+			log.Printf("TriggerEngine: %s", err)
+		}
+		te.locker.Unlock()
+	}
+
+	te.notify(*trigger, subs, tres, nil)
+	if isClosed && te.opts.HandleFilled != nil {
+		// The order, usually market order, has been closed
+		// immediately.
+		te.opts.HandleFilled(*trigger, tres.Order)
+	}
+}
+
+// release remove the subscribers and interceptor of trigger and return
+// the subscribers.
+func (te *TriggerEngine) release(id string) (subs []*triggerSubscriber) {
+	te.locker.Lock()
+	subs = te.subs[id]
+	delete(te.subs, id)
+	delete(te.interceptors, id)
+	te.locker.Unlock()
+	return subs
+}
+
+// fail re-arm the trigger whose order can not be submitted and notify the
+// subscribers with the error.
+//
+// The trigger is re-armed with the retry delay, doubled on each failure.
+// If the error is not retryable or the trigger has been failed
+// MaxAttempts times, the trigger is dropped and its subscribers and
+// interceptor are removed.
+func (te *TriggerEngine) fail(trigger *StopTrigger, err error) {
+	trigger.Attempts++
+
+	if !isRetryableError(err) || trigger.Attempts >= te.opts.MaxAttempts {
+		log.Printf("TriggerEngine: %s: dropped after %d attempts: %s",
+			trigger.ID, trigger.Attempts, err)
+		te.notify(*trigger, te.release(trigger.ID), nil, err)
+		return
+	}
+
+	delay := te.opts.RetryInterval << (trigger.Attempts - 1)
+	if delay <= 0 || delay > maxTriggerRetryInterval {
+		delay = maxTriggerRetryInterval
+	}
+	trigger.retryAt = time.Now().Add(delay)
+
+	log.Printf("TriggerEngine: %s: retry in %s: %s", trigger.ID, delay, err)
+	te.rearm(trigger)
+
+	te.locker.Lock()
+	subs := make([]*triggerSubscriber, len(te.subs[trigger.ID]))
+	copy(subs, te.subs[trigger.ID])
+	te.locker.Unlock()
+
+	te.notify(*trigger, subs, nil, err)
+}
+
+// isRetryableError return false if the err is the rejection from server
+// that will not change by resending the same request, that is status
+// code 4xx except 408 (timeout), 409 (conflict), 422 (the market can not
+// fulfill the order at this time), and 429 (too many requests).
+func isRetryableError(err error) bool {
+	var errServer *liberrors.E
+	if !errors.As(err, &errServer) {
+		return true
+	}
+	if errServer.Code < 400 || errServer.Code >= 500 {
+		return true
+	}
+	switch errServer.Code {
+	case http.StatusRequestTimeout, http.StatusConflict,
+		http.StatusUnprocessableEntity, http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// rearm put back the trigger whose order can not be submitted, so it will
+// fire again on the next price update.
+// The trigger is not re-armed if other trigger with the same ID has been
+// armed in the mean time.
+func (te *TriggerEngine) rearm(trigger *StopTrigger) {
+	te.locker.Lock()
+	defer te.locker.Unlock()
+
+	if _, ok := te.armed[trigger.ID]; ok {
+		return
+	}
+	te.armed[trigger.ID] = trigger
+
+	err := te.save()
+	if err != nil {
+		log.Printf("TriggerEngine: %s", err)
+	}
+}
+
+func (te *TriggerEngine) notify(
+	trigger StopTrigger, subs []*triggerSubscriber, tres *TradeResponse, err error,
+) {
+	if te.opts.HandleTriggered != nil {
+		te.opts.HandleTriggered(trigger, tres, err)
+	}
+	for _, sub := range subs {
+		sub.handler(trigger, tres, err)
+	}
+}
+
+// triggerEntry is the StopTrigger saved into file, including the fields
+// of Request that are not marshaled into JSON.
+type triggerEntry struct {
+	*StopTrigger
+	ExpireAt      *time.Time `json:"expire_at,omitempty"`
+	ClientOrderID string     `json:"client_order_id,omitempty"`
+}
+
+// load the armed and fired triggers from file.
+func (te *TriggerEngine) load() (err error) {
+	if len(te.opts.Path) == 0 {
+		return nil
+	}
+
+	b, err := os.ReadFile(te.opts.Path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var entries []triggerEntry
+	err = json.Unmarshal(b, &entries)
+	if err != nil {
+		return fmt.Errorf("%s: %w", te.opts.Path, err)
+	}
+	for _, entry := range entries {
+		trigger := entry.StopTrigger
+		if trigger == nil {
+			continue
+		}
+		trigger.Request.ClientOrderID = entry.ClientOrderID
+		if entry.ExpireAt != nil {
+			trigger.Request.ExpireAt = *entry.ExpireAt
+		}
+		if trigger.OrderID != 0 {
+			te.fired[trigger.OrderID] = trigger
+		} else {
+			te.armed[trigger.ID] = trigger
+		}
+	}
+	return nil
+}
+
+// save the armed and fired triggers into file.
+// The fired triggers that are older than FiredRetention are removed.
+// It must be called while holding the lock.
+func (te *TriggerEngine) save() (err error) {
+	expired := time.Now().Add(-te.opts.FiredRetention).Unix()
+	for id, trigger := range te.fired {
+		if trigger.FiredAt < expired {
+			delete(te.fired, id)
+		}
+	}
+
+	if len(te.opts.Path) == 0 {
+		return nil
+	}
+
+	entries := make([]triggerEntry, 0, len(te.armed)+len(te.fired))
+	for _, trigger := range te.armed {
+		entries = append(entries, newTriggerEntry(trigger))
+	}
+	for _, trigger := range te.fired {
+		entries = append(entries, newTriggerEntry(trigger))
+	}
+
+	b, err := json.MarshalIndent(entries, "", "\t")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(te.opts.Path, b)
+}
+
+func newTriggerEntry(trigger *StopTrigger) (entry triggerEntry) {
+	entry.StopTrigger = trigger
+	entry.ClientOrderID = trigger.Request.ClientOrderID
+	if !trigger.Request.ExpireAt.IsZero() {
+		entry.ExpireAt = &trigger.Request.ExpireAt
+	}
+	return entry
+}
@@ -0,0 +1,401 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/math/big"
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestStopTrigger_IsTriggered(t *testing.T) {
+	cases := []struct {
+		kind  string
+		side  string
+		price int
+		exp   bool
+	}{
+		{StopKindStopLoss, TradeTypeAsk, 101, false},
+		{StopKindStopLoss, TradeTypeAsk, 100, true},
+		{StopKindTakeProfit, TradeTypeAsk, 99, false},
+		{StopKindTakeProfit, TradeTypeAsk, 100, true},
+		{StopKindStopLoss, TradeTypeBid, 99, false},
+		{StopKindStopLoss, TradeTypeBid, 101, true},
+		{StopKindTakeProfit, TradeTypeBid, 101, false},
+		{StopKindTakeProfit, TradeTypeBid, 99, true},
+	}
+	for _, c := range cases {
+		trigger := &StopTrigger{
+			StopPrice: big.NewRat(100),
+			Request:   TradeRequest{Type: c.side},
+			Kind:      c.kind,
+		}
+		name := c.kind + " " + c.side
+		test.Assert(t, name, c.exp, trigger.IsTriggered(big.NewRat(c.price)))
+	}
+}
+
+func TestTriggerEngine(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var (
+		path  = filepath.Join(t.TempDir(), "triggers.json")
+		fired []string
+		opts  = TriggerEngineOptions{
+			Client: cl,
+			Path:   path,
+			HandleTriggered: func(trigger StopTrigger, tres *TradeResponse, err error) {
+				if err != nil {
+					t.Error(err)
+				}
+				fired = append(fired, trigger.ID)
+			},
+		}
+	)
+
+	te, err := NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stopMarket := StopTrigger{
+		ID:        "stop",
+		Kind:      StopKindStopLoss,
+		StopPrice: big.NewRat(90),
+		Request: TradeRequest{
+			Pair:   PairBitcoinIdk,
+			Type:   TradeTypeAsk,
+			Method: TradeMethodMarket,
+			Amount: big.NewRat(1),
+		},
+	}
+	takeProfit := StopTrigger{
+		ID:        "profit",
+		Kind:      StopKindTakeProfit,
+		StopPrice: big.NewRat(120),
+		Request: TradeRequest{
+			Pair:   PairBitcoinIdk,
+			Type:   TradeTypeAsk,
+			Method: TradeMethodLimit,
+			Price:  big.NewRat(119),
+			Amount: big.NewRat(1),
+		},
+	}
+	for _, trigger := range []StopTrigger{stopMarket, takeProfit} {
+		_, err = te.Arm(trigger)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Trade on other pair and unmatched order must be ignored.
+	te.HandleTrade(&Trade{Pair: PairTokenomyIdk, Price: big.NewRat(50), Status: TradeStatusFilled})
+	te.HandleTrade(&Trade{Pair: PairBitcoinIdk, Price: big.NewRat(50)})
+	test.Assert(t, "fired on ignored trades", 0, len(fired))
+
+	te.HandleTrade(&Trade{Pair: PairBitcoinIdk, Price: big.NewRat(89), Status: TradeStatusFilled})
+	test.Assert(t, "fired", []string{"stop"}, fired)
+
+	// The armed triggers is restored from file.
+	te, err = NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triggers := te.Triggers()
+	test.Assert(t, "restored", 1, len(triggers))
+	test.Assert(t, "restored ID", "profit", triggers[0].ID)
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(125))
+	test.Assert(t, "fired", []string{"stop", "profit"}, fired)
+	test.Assert(t, "armed", 0, len(te.Triggers()))
+	test.Assert(t, "sent orders", 2, ex.count(APITradeAsk))
+	test.Assert(t, "resting take-profit", 1, len(ex.open()))
+}
+
+func TestTriggerEngine_trailing(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var fired []string
+
+	te, err := NewTriggerEngine(TriggerEngineOptions{
+		Client: cl,
+		HandleTriggered: func(trigger StopTrigger, tres *TradeResponse, err error) {
+			fired = append(fired, trigger.ID+" "+trigger.CurrentStopPrice().String())
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(100))
+
+	treq := TradeRequest{
+		Pair:   PairBitcoinIdk,
+		Method: TradeMethodMarket,
+		Amount: big.NewRat(1),
+	}
+
+	sell := StopTrigger{
+		ID:           "sell",
+		Kind:         StopKindTrailing,
+		TrailPercent: big.NewRat(10),
+		Request:      treq,
+	}
+	sell.Request.Type = TradeTypeAsk
+
+	buy := StopTrigger{
+		ID:          "buy",
+		Kind:        StopKindTrailing,
+		TrailAmount: big.NewRat(5),
+		Request:     treq,
+	}
+	buy.Request.Type = TradeTypeBid
+
+	for _, trigger := range []StopTrigger{sell, buy} {
+		_, err = te.Arm(trigger)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Low-water mark of buy move to 96, the stop price is 101.
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(96))
+	// High-water mark of sell move to 120, the stop price is 108.
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(100))
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(120))
+	test.Assert(t, "buy fired", []string{"buy 101"}, fired)
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(109))
+	test.Assert(t, "sell not fired", 1, len(fired))
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(108))
+	test.Assert(t, "sell fired", []string{"buy 101", "sell 108"}, fired)
+	test.Assert(t, "orders", []int{1, 1}, []int{ex.count(APITradeBid), ex.count(APITradeAsk)})
+}
+
+func TestTriggerEngine_rearm(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var errs []error
+
+	te, err := NewTriggerEngine(TriggerEngineOptions{
+		Client: cl,
+		HandleTriggered: func(trigger StopTrigger, tres *TradeResponse, err error) {
+			errs = append(errs, err)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = te.Arm(StopTrigger{
+		ID:        "stop",
+		Kind:      StopKindStopLoss,
+		StopPrice: big.NewRat(90),
+		Request: TradeRequest{
+			Pair:   PairBitcoinIdk,
+			Type:   TradeTypeAsk,
+			Method: TradeMethodMarket,
+			Amount: big.NewRat(1),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first submit is rejected, the trigger is armed back.
+	var isRejected bool
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if isRejected {
+			return false
+		}
+		isRejected = true
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"code":503,"message":"maintenance","name":"ERR_MAINTENANCE"}`))
+		return true
+	}
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	test.Assert(t, "armed after error", 1, len(te.Triggers()))
+	test.Assert(t, "attempts", 1, te.Triggers()[0].Attempts)
+
+	// The trigger is not fired again before the retry delay passed.
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(88))
+	test.Assert(t, "asks before retry delay", 1, ex.count(APITradeAsk))
+
+	te.locker.Lock()
+	te.armed["stop"].retryAt = time.Time{}
+	te.locker.Unlock()
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(88))
+	test.Assert(t, "armed after submit", 0, len(te.Triggers()))
+	test.Assert(t, "errors", 2, len(errs))
+	if errs[0] == nil || errs[1] != nil {
+		t.Fatalf("want error on first submit only, got %v", errs)
+	}
+	test.Assert(t, "asks", 2, ex.count(APITradeAsk))
+
+	// Closing the notification channel stop the engine.
+	notif := make(chan Trade)
+	te.Start(notif)
+	close(notif)
+	for x := 0; x < 100; x++ {
+		te.locker.Lock()
+		isRunning := te.isRunning
+		te.locker.Unlock()
+		if !isRunning {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("engine still running after notification closed")
+}
+
+func TestTriggerEngine_drop(t *testing.T) {
+	ex, cl := newTestExchange(t)
+
+	var errs []error
+
+	te, err := NewTriggerEngine(TriggerEngineOptions{
+		Client:      cl,
+		MaxAttempts: 2,
+		HandleTriggered: func(trigger StopTrigger, tres *TradeResponse, err error) {
+			errs = append(errs, err)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := StopTrigger{
+		Kind:      StopKindStopLoss,
+		StopPrice: big.NewRat(90),
+		Request: TradeRequest{
+			Pair:   PairBitcoinIdk,
+			Type:   TradeTypeAsk,
+			Method: TradeMethodMarket,
+			Amount: big.NewRat(1),
+		},
+	}
+
+	code := http.StatusServiceUnavailable
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte(`{"code":400,"message":"rejected","name":"ERR_REJECTED"}`))
+		return true
+	}
+
+	// The trigger is dropped after MaxAttempts failures.
+	stop.ID = "retry"
+	_, err = te.Arm(stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for x := 0; x < 3; x++ {
+		te.locker.Lock()
+		if trigger := te.armed["retry"]; trigger != nil {
+			trigger.retryAt = time.Time{}
+		}
+		te.locker.Unlock()
+		te.UpdatePrice(PairBitcoinIdk, big.NewRat(89))
+	}
+	test.Assert(t, "asks on max attempts", 2, ex.count(APITradeAsk))
+	test.Assert(t, "armed on max attempts", 0, len(te.Triggers()))
+
+	// The trigger is dropped on the first rejection that is not
+	// retryable.
+	code = http.StatusBadRequest
+	stop.ID = "reject"
+	_, err = te.Arm(stop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(88))
+	test.Assert(t, "asks on rejection", 3, ex.count(APITradeAsk))
+	test.Assert(t, "armed on rejection", 0, len(te.Triggers()))
+	test.Assert(t, "errors", 3, len(errs))
+}
+
+func TestTriggerEngine_persistFired(t *testing.T) {
+	_, cl := newTestExchange(t)
+
+	var (
+		path     = filepath.Join(t.TempDir(), "triggers.json")
+		expireAt = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		filled   []string
+		opts     = TriggerEngineOptions{
+			Client: cl,
+			Path:   path,
+			HandleFilled: func(trigger StopTrigger, trade *Trade) {
+				filled = append(filled, trigger.ID+" "+trigger.Request.ClientOrderID)
+			},
+		}
+	)
+
+	te, err := NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = te.Arm(StopTrigger{
+		ID:        "profit",
+		Kind:      StopKindTakeProfit,
+		StopPrice: big.NewRat(120),
+		Request: TradeRequest{
+			Pair:          PairBitcoinIdk,
+			Type:          TradeTypeAsk,
+			Price:         big.NewRat(119),
+			Amount:        big.NewRat(1),
+			ClientOrderID: "tp-1",
+			ExpireAt:      expireAt,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request fields that are not marshaled are restored.
+	te, err = NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	triggers := te.Triggers()
+	test.Assert(t, "restored ClientOrderID", "tp-1", triggers[0].Request.ClientOrderID)
+	test.Assert(t, "restored ExpireAt", true, expireAt.Equal(triggers[0].Request.ExpireAt))
+
+	te.UpdatePrice(PairBitcoinIdk, big.NewRat(121))
+
+	// The fired trigger is restored, so the closed order after restart
+	// still call HandleFilled.
+	te, err = NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "armed", 0, len(te.Triggers()))
+	test.Assert(t, "fired", 1, len(te.fired))
+
+	for id := range te.fired {
+		te.HandleOrdersClosed(&Trade{ID: id, Status: TradeStatusFilled})
+	}
+	test.Assert(t, "filled", []string{"profit tp-1"}, filled)
+
+	te, err = NewTriggerEngine(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "fired after closed", 0, len(te.fired))
+
+	// The fired trigger is removed after FiredRetention.
+	te.locker.Lock()
+	te.fired[1] = &StopTrigger{ID: "old", OrderID: 1, FiredAt: 1}
+	err = te.save()
+	te.locker.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	test.Assert(t, "fired after retention", 0, len(te.fired))
+}
@@ -0,0 +1,282 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// List of reasons passed to WatchdogHandler.
+const (
+	WatchdogReasonHeartbeat  = "heartbeat"
+	WatchdogReasonDisconnect = "disconnect"
+)
+
+// minWatchdogCheckInterval define the minimum interval where the Watchdog
+// check the heartbeat and connection.
+const minWatchdogCheckInterval = 100 * time.Millisecond
+
+// maxWatchdogRetryInterval define the maximum delay between retries when
+// the Watchdog failed to cancel the orders.
+const maxWatchdogRetryInterval = 30 * time.Second
+
+// WatchdogHandler define a callback that will be called after the Watchdog
+// cancelled the open orders.
+// The reason parameter is either WatchdogReasonHeartbeat or
+// WatchdogReasonDisconnect.
+type WatchdogHandler func(reason string, cancelled []Trade, err error)
+
+// WatchdogOptions define the options for Watchdog.
+type WatchdogOptions struct {
+	// Client, required, is the REST client used to cancel the orders.
+	// It should be independent from the client that used by
+	// application to place the orders.
+	Client *Client
+
+	// WebSocket, optional, is the private WebSocket to be watched.
+	// If its set, the open orders will be cancelled when the WebSocket
+	// disconnected longer than GracePeriod.
+	WebSocket *WebSocketPrivate
+
+	// HandleCancelled, optional, is the callback that will be called
+	// after orders has been cancelled.
+	HandleCancelled WatchdogHandler
+
+	// Pairs, optional, list of pairs where the open orders will be
+	// cancelled.
+	// If its empty, all orders in all pairs will be cancelled using
+	// TradeCancelAll.
+	Pairs []string
+
+	// Timeout, required, is the maximum duration between two Heartbeat
+	// calls before the orders cancelled.
+	Timeout time.Duration
+
+	// GracePeriod, optional, is the maximum duration the WebSocket can
+	// be disconnected before the orders cancelled.
+	// Default to Timeout.
+	GracePeriod time.Duration
+}
+
+// Watchdog is a dead man's switch that cancel the open orders when the
+// application stop sending heartbeat or when the private WebSocket
+// disconnected for too long.
+//
+// Once the orders cancelled, the Watchdog will not cancel them again until
+// the next Heartbeat called.
+// If the cancel failed, for example when the network is down, it is
+// retried with increasing delay until it succeed or the next Heartbeat
+// called.
+type Watchdog struct {
+	opts WatchdogOptions
+
+	lastHeartbeat time.Time
+	done          chan struct{}
+
+	// retryAt and retryDelay define when the failed cancel is
+	// retried.
+	retryAt    time.Time
+	retryDelay time.Duration
+
+	locker sync.Mutex
+
+	isTriggered bool
+	isRunning   bool
+}
+
+// NewWatchdog create and initialize new Watchdog.
+// The Watchdog is not running until Start is called.
+func NewWatchdog(opts WatchdogOptions) (wd *Watchdog, err error) {
+	if opts.Client == nil {
+		return nil, errors.New("NewWatchdog: empty Client")
+	}
+	if opts.Timeout <= 0 {
+		return nil, errors.New("NewWatchdog: invalid Timeout")
+	}
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = opts.Timeout
+	}
+
+	wd = &Watchdog{
+		opts: opts,
+	}
+	return wd, nil
+}
+
+// Heartbeat tell the Watchdog that the application is still alive.
+// It also re-arm the Watchdog after its triggered.
+func (wd *Watchdog) Heartbeat() {
+	wd.locker.Lock()
+	wd.lastHeartbeat = time.Now()
+	wd.isTriggered = false
+	wd.retryAt = time.Time{}
+	wd.retryDelay = 0
+	wd.locker.Unlock()
+}
+
+// Start watching the heartbeat and connection in the background.
+// Calling Start on running Watchdog has no effect.
+func (wd *Watchdog) Start() {
+	wd.locker.Lock()
+	defer wd.locker.Unlock()
+
+	if wd.isRunning {
+		return
+	}
+
+	wd.lastHeartbeat = time.Now()
+	wd.isTriggered = false
+	wd.retryAt = time.Time{}
+	wd.retryDelay = 0
+	wd.isRunning = true
+	wd.done = make(chan struct{})
+
+	go wd.run(wd.done)
+}
+
+// Stop the Watchdog without cancelling any orders.
+func (wd *Watchdog) Stop() {
+	wd.locker.Lock()
+	defer wd.locker.Unlock()
+
+	if !wd.isRunning {
+		return
+	}
+	close(wd.done)
+	wd.isRunning = false
+}
+
+func (wd *Watchdog) run(done chan struct{}) {
+	interval := wd.opts.Timeout / 4
+	if wd.opts.GracePeriod/4 < interval {
+		interval = wd.opts.GracePeriod / 4
+	}
+	if interval < minWatchdogCheckInterval {
+		interval = minWatchdogCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case now := <-ticker.C:
+			reason, lastHeartbeat := wd.check(now)
+			if len(reason) > 0 {
+				wd.trigger(reason, lastHeartbeat, interval)
+			}
+		}
+	}
+}
+
+// check return non-empty reason if the orders should be cancelled, and
+// the time of last heartbeat when its checked.
+func (wd *Watchdog) check(now time.Time) (reason string, lastHeartbeat time.Time) {
+	wd.locker.Lock()
+	defer wd.locker.Unlock()
+
+	lastHeartbeat = wd.lastHeartbeat
+	if wd.isTriggered || now.Before(wd.retryAt) {
+		return "", lastHeartbeat
+	}
+	if now.Sub(wd.lastHeartbeat) > wd.opts.Timeout {
+		reason = WatchdogReasonHeartbeat
+	} else if wd.opts.WebSocket != nil {
+		disconnectedAt := wd.opts.WebSocket.DisconnectedAt()
+		if !disconnectedAt.IsZero() && now.Sub(disconnectedAt) > wd.opts.GracePeriod {
+			reason = WatchdogReasonDisconnect
+		}
+	}
+	return reason, lastHeartbeat
+}
+
+// trigger cancel the orders.
+// The Watchdog is marked as triggered only if the cancel succeed and no
+// Heartbeat called since check, otherwise the cancel is retried after
+// the delay that start from interval and doubled on each failure.
+func (wd *Watchdog) trigger(reason string, lastHeartbeat time.Time, interval time.Duration) {
+	logp := "Watchdog"
+
+	log.Printf("%s: %s timeout, cancelling open orders ...", logp, reason)
+
+	cancelled, err := wd.cancel()
+	for _, trade := range cancelled {
+		log.Printf("%s: cancelled %s %s id=%d price=%s remain=%s", logp,
+			trade.Pair, trade.Type, trade.ID, trade.Price,
+			trade.CoinRemain)
+	}
+	if err != nil {
+		log.Printf("%s: %s", logp, err)
+	}
+
+	wd.locker.Lock()
+	if wd.lastHeartbeat.Equal(lastHeartbeat) {
+		if err == nil {
+			wd.isTriggered = true
+			wd.retryAt = time.Time{}
+			wd.retryDelay = 0
+		} else {
+			wd.retryDelay *= 2
+			if wd.retryDelay < interval {
+				wd.retryDelay = interval
+			}
+			if wd.retryDelay > maxWatchdogRetryInterval {
+				wd.retryDelay = maxWatchdogRetryInterval
+			}
+			wd.retryAt = time.Now().Add(wd.retryDelay)
+			log.Printf("%s: retrying in %s", logp, wd.retryDelay)
+		}
+	}
+	wd.locker.Unlock()
+
+	if wd.opts.HandleCancelled != nil {
+		wd.opts.HandleCancelled(reason, cancelled, err)
+	}
+}
+
+// cancel the open orders on all pairs or on specific pairs only.
+func (wd *Watchdog) cancel() (cancelled []Trade, err error) {
+	if len(wd.opts.Pairs) == 0 {
+		cancelled, err = wd.opts.Client.TradeCancelAll()
+		if err != nil {
+			return nil, fmt.Errorf("TradeCancelAll: %w", err)
+		}
+		return cancelled, nil
+	}
+
+	var errs []string
+
+	for _, pair := range wd.opts.Pairs {
+		pairTradesOpen, err := wd.opts.Client.UserOrdersOpen(pair)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		openTrades := pairTradesOpen.Trades()
+		for x := range openTrades {
+			trade, err := wd.opts.Client.TradeCancel(&openTrades[x])
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("TradeCancel %s %d: %s",
+					openTrades[x].Pair, openTrades[x].ID, err))
+				continue
+			}
+			if trade == nil {
+				trade = &openTrades[x]
+			}
+			cancelled = append(cancelled, *trade)
+		}
+	}
+	if len(errs) > 0 {
+		return cancelled, fmt.Errorf("cancel: %s", strings.Join(errs, "; "))
+	}
+	return cancelled, nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestWatchdog(t *testing.T) {
+	var (
+		locker    sync.Mutex
+		cancelled []string
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		err := req.ParseForm()
+		if err != nil {
+			t.Error(err)
+		}
+
+		switch req.URL.Path {
+		case APIUserOrdersOpen:
+			// The server does not set the type on open orders.
+			_, _ = w.Write([]byte(`{"data":{"btc_idk":{` +
+				`"asks":[{"id":1,"price":"110"}],` +
+				`"bids":[{"id":2,"price":"90"}]}}}`))
+		case APITradeCancelAsk, APITradeCancelBid:
+			locker.Lock()
+			cancelled = append(cancelled, req.URL.Path+" "+req.Form.Get(ParamNameTradeID))
+			locker.Unlock()
+			fmt.Fprintf(w, `{"data":{"order":{"id":%s,"status":"cancelled"}}}`,
+				req.Form.Get(ParamNameTradeID))
+		default:
+			t.Errorf("unexpected request %s", req.URL)
+		}
+	}))
+	defer srv.Close()
+
+	cl, err := NewClient(&Environment{Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		reason    string
+		cancelled []Trade
+		err       error
+	}
+	resultq := make(chan result, 1)
+
+	wd, err := NewWatchdog(WatchdogOptions{
+		Client:  cl,
+		Pairs:   []string{PairBitcoinIdk},
+		Timeout: 200 * time.Millisecond,
+		HandleCancelled: func(reason string, trades []Trade, err error) {
+			resultq <- result{reason, trades, err}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd.Start()
+	defer wd.Stop()
+
+	var got result
+	select {
+	case got = <-resultq:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchdog is not triggered")
+	}
+
+	test.Assert(t, "reason", WatchdogReasonHeartbeat, got.reason)
+	test.Assert(t, "error", nil, got.err)
+	test.Assert(t, "cancelled", 2, len(got.cancelled))
+
+	locker.Lock()
+	sort.Strings(cancelled)
+	exp := []string{APITradeCancelAsk + " 1", APITradeCancelBid + " 2"}
+	sort.Strings(exp)
+	test.Assert(t, "requests", exp, cancelled)
+	locker.Unlock()
+}
+
+func TestWatchdog_retry(t *testing.T) {
+	var (
+		ex, cl = newTestExchange(t)
+		nfail  = 2
+	)
+
+	ex.handle = func(w http.ResponseWriter, req *http.Request) bool {
+		if req.URL.Path != APITradeCancelAll {
+			return false
+		}
+		ex.locker.Lock()
+		defer ex.locker.Unlock()
+		if nfail == 0 {
+			return false
+		}
+		nfail--
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte(`{"code":502,"message":"bad gateway"}`))
+		return true
+	}
+
+	errq := make(chan error, 4)
+	wd, err := NewWatchdog(WatchdogOptions{
+		Client:  cl,
+		Timeout: 100 * time.Millisecond,
+		HandleCancelled: func(reason string, trades []Trade, err error) {
+			errq <- err
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd.Start()
+	defer wd.Stop()
+
+	for x := 0; x < 3; x++ {
+		select {
+		case err = <-errq:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("watchdog is not retried after %d attempts", x)
+		}
+		test.Assert(t, fmt.Sprintf("attempt %d failed", x), x < 2, err != nil)
+	}
+
+	// No more cancel after its succeed.
+	time.Sleep(300 * time.Millisecond)
+	test.Assert(t, "requests", 3, ex.count(APITradeCancelAll))
+}
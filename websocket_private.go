@@ -8,14 +8,15 @@ import (
 	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	liberrors "github.com/shuLhan/share/lib/errors"
 	"github.com/shuLhan/share/lib/websocket"
 )
 
@@ -25,17 +26,60 @@ type OrdersClosedHandler func(trade *Trade)
 
 // WebSocketPrivate define the private WebSocket client for APIv2.
 type WebSocketPrivate struct {
-	env  *Environment
+	// disconnectedAt contains the Unix time in nanoseconds when the
+	// connection is lost unexpectedly, or zero if its connected.
+	// It is placed at the top to make it 64-bit aligned for atomic
+	// operations.
+	disconnectedAt int64
+
+	env *Environment
+
+	// conn is the current connection.
+	// It is replaced by RotateCredential, guarded by requestsLocker.
 	conn *websocket.Client
 
 	requests map[uint64]chan *websocket.Response
 
+	// Markets, optional, contains the registry of markets.
+	// If its set, the pair on trade requests is validated against it
+	// before sending the request to server.
+	Markets *MarketRegistry
+
+	// ClientOrders, optional, is the store where the ClientOrderID of
+	// placed orders are recorded.
+	// The closed orders are marked automatically in the store.
+	ClientOrders *ClientOrderStore
+
+	// GTT, optional, is the scheduler to cancel the orders with
+	// TimeInForce "GTT".
+	// It is required to place the order with TimeInForce "GTT".
+	GTT *GTTScheduler
+
+	// Risk, optional, check each order on TradeAsk and TradeBid against
+	// the pre-trade risk limits.
+	Risk *RiskEngine
+
+	// SelfTrade, optional, prevent the orders on TradeAsk and TradeBid
+	// from being matched with our own open orders.
+	// The closed orders are removed from it if the SelfTradeGuard is
+	// created with this WebSocket in its options.
+	SelfTrade *SelfTradeGuard
+
+	// Balances, optional, is the local balances that is updated from
+	// the response of TradeAsk, TradeBid, and cancel.
+	Balances *BalanceTracker
+
 	// HandleOrdersClosed define the callback that will be called
 	// automatically by client when one of the user's orders closed in the
 	// market.
+	// It is called after all of the handlers registered by
+	// AddOrdersClosedHandler.
 	HandleOrdersClosed OrdersClosedHandler
 
+	ordersClosedHandlers []OrdersClosedHandler
+
 	requestsLocker sync.Mutex
+	handlersLocker sync.Mutex
 }
 
 // NewWebSocketPrivate create and initialize new WebSocket connection to
@@ -51,22 +95,13 @@ func NewWebSocketPrivate(env *Environment) (
 	}
 
 	cl = &WebSocketPrivate{
-		env: env,
-		conn: &websocket.Client{
-			Headers: make(http.Header),
-		},
+		env:      env,
 		requests: make(map[uint64](chan *websocket.Response)),
 	}
-	if env.IsInsecure {
-		cl.conn.TLSConfig = &tls.Config{
-			InsecureSkipVerify: env.IsInsecure,
-		}
-	}
-
-	cl.conn.HandleText = cl.handleText
-	cl.conn.HandleQuit = cl.handleUnexpectedQuit
+	cl.conn = cl.newConn()
 
-	err = cl.connect()
+	token, secret := env.credential()
+	err = cl.connect(cl.conn, token, secret)
 	if err != nil {
 		return nil, fmt.Errorf("NewWebSocketPrivate: %w", err)
 	}
@@ -74,17 +109,79 @@ func NewWebSocketPrivate(env *Environment) (
 	return cl, nil
 }
 
+// AddOrdersClosedHandler register the handler that will be called when
+// one of the user's orders closed in the market.
+// The handlers are called in the order they are registered, from the
+// goroutine that read the connection.
+func (cl *WebSocketPrivate) AddOrdersClosedHandler(handler OrdersClosedHandler) {
+	if handler == nil {
+		return
+	}
+	cl.handlersLocker.Lock()
+	cl.ordersClosedHandlers = append(cl.ordersClosedHandlers, handler)
+	cl.handlersLocker.Unlock()
+}
+
 // Close the connection and release all the resource.
 func (cl *WebSocketPrivate) Close() error {
 	cl.requestsLocker.Lock()
-	for id, ch := range cl.requests {
-		ch <- nil
-		close(ch)
-		delete(cl.requests, id)
+	cl.failRequests()
+	conn := cl.conn
+	cl.requestsLocker.Unlock()
+
+	return conn.Close()
+}
+
+// DisconnectedAt return the time when the connection is lost unexpectedly.
+// It will return zero time if the client is connected.
+func (cl *WebSocketPrivate) DisconnectedAt() time.Time {
+	nsec := atomic.LoadInt64(&cl.disconnectedAt)
+	if nsec == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nsec)
+}
+
+// RotateCredential reload the Token and Secret from the Environment Provider
+// and reconnect the WebSocket using the new credential.
+//
+// The new connection is opened before the old one is closed.
+// The credential and connection are swapped under the same lock that
+// guard the pending requests, and the requests that are still waiting
+// for response on the old connection return with an error.
+func (cl *WebSocketPrivate) RotateCredential() (err error) {
+	logp := "RotateCredential"
+
+	if cl.env.Provider == nil {
+		return fmt.Errorf("%s: %w", logp, ErrCredentialEmpty)
+	}
+
+	cred, err := cl.env.Provider.Credential()
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
+	}
+
+	conn := cl.newConn()
+
+	err = cl.connect(conn, cred.Token, cred.Secret)
+	if err != nil {
+		return fmt.Errorf("%s: %w", logp, err)
 	}
+
+	cl.requestsLocker.Lock()
+	cl.env.SetCredential(cred)
+	oldConn := cl.conn
+	cl.conn = conn
+	cl.failRequests()
 	cl.requestsLocker.Unlock()
 
-	return cl.conn.Close()
+	// The old connection is not the current one anymore, so its
+	// HandleQuit will not reconnect it.
+	err = oldConn.Close()
+	if err != nil {
+		log.Printf("%s: %s", logp, err)
+	}
+	return nil
 }
 
 // TradeAsk request to sell the coin on market with specific method, amount,
@@ -111,8 +208,56 @@ func (cl *WebSocketPrivate) TradeAsk(treq *TradeRequest) (
 	if err != nil {
 		return nil, err
 	}
+	if cl.Markets != nil {
+		err = cl.Markets.Validate(treq.Pair)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = checkTimeInForce(treq, cl.GTT)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRisk(cl.Risk, APITradeAsk, treq)
+	if err != nil {
+		return nil, err
+	}
+	guarded, err := checkSelfTrade(cl.SelfTrade, cl.Risk, APITradeAsk, treq, cl.env.IsDryRun)
+	if err != nil {
+		return nil, err
+	}
+	if guarded != treq {
+		treq = guarded
+		_, wsparams, err = treq.Pack()
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return cl.sendTradeRequest(http.MethodPost, APITradeAsk, wsparams)
+	if !cl.env.IsDryRun {
+		err = recordClientOrderPending(cl.ClientOrders, APITradeAsk, treq)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	trade, err = cl.sendTradeRequest(http.MethodPost, APITradeAsk, wsparams)
+	if err != nil {
+		rejectClientOrderPending(cl.ClientOrders, treq, err)
+		return nil, err
+	}
+	if cl.env.IsDryRun {
+		return trade, nil
+	}
+	recordClientOrder(cl.ClientOrders, treq, trade.Order)
+	recordRisk(cl.Risk, treq, trade)
+	recordSelfTrade(cl.SelfTrade, APITradeAsk, treq, trade)
+	recordBalance(cl.Balances, APITradeAsk, treq, trade)
+	err = applyTimeInForce(cl, cl.GTT, treq, trade)
+	if err != nil {
+		return trade, err
+	}
+	return trade, nil
 }
 
 // TradeBid request to buy the coin on market with specific method, amount,
@@ -139,8 +284,56 @@ func (cl *WebSocketPrivate) TradeBid(treq *TradeRequest) (
 	if err != nil {
 		return nil, err
 	}
+	if cl.Markets != nil {
+		err = cl.Markets.Validate(treq.Pair)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = checkTimeInForce(treq, cl.GTT)
+	if err != nil {
+		return nil, err
+	}
+	err = checkRisk(cl.Risk, APITradeBid, treq)
+	if err != nil {
+		return nil, err
+	}
+	guarded, err := checkSelfTrade(cl.SelfTrade, cl.Risk, APITradeBid, treq, cl.env.IsDryRun)
+	if err != nil {
+		return nil, err
+	}
+	if guarded != treq {
+		treq = guarded
+		_, wsparams, err = treq.Pack()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !cl.env.IsDryRun {
+		err = recordClientOrderPending(cl.ClientOrders, APITradeBid, treq)
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	return cl.sendTradeRequest(http.MethodPost, APITradeBid, wsparams)
+	trade, err = cl.sendTradeRequest(http.MethodPost, APITradeBid, wsparams)
+	if err != nil {
+		rejectClientOrderPending(cl.ClientOrders, treq, err)
+		return nil, err
+	}
+	if cl.env.IsDryRun {
+		return trade, nil
+	}
+	recordClientOrder(cl.ClientOrders, treq, trade.Order)
+	recordRisk(cl.Risk, treq, trade)
+	recordSelfTrade(cl.SelfTrade, APITradeBid, treq, trade)
+	recordBalance(cl.Balances, APITradeBid, treq, trade)
+	err = applyTimeInForce(cl, cl.GTT, treq, trade)
+	if err != nil {
+		return trade, err
+	}
+	return trade, nil
 }
 
 // TradeCancel cancel the open trade using ID and pair information in Trade.
@@ -177,6 +370,15 @@ func (cl *WebSocketPrivate) TradeCancel(trade *Trade) (
 func (cl *WebSocketPrivate) TradeCancelAll() (
 	trades []Trade, err error,
 ) {
+	if cl.env.IsDryRun {
+		dryRunLog(http.MethodDelete, APITradeCancelAll, nil, nil)
+		pto, err := cl.UserOrdersOpen("")
+		if err != nil {
+			return nil, err
+		}
+		return newDryRunCancelAll(pto), nil
+	}
+
 	wsres, err := cl.send(http.MethodDelete, APITradeCancelAll, nil)
 	if err != nil {
 		return nil, err
@@ -192,6 +394,12 @@ func (cl *WebSocketPrivate) TradeCancelAll() (
 		return nil, err
 	}
 
+	for _, trade := range trades {
+		forgetRisk(cl.Risk, trade.ID)
+		forgetSelfTrade(cl.SelfTrade, trade.ID)
+	}
+	recordBalanceCancelled(cl.Balances, trades)
+
 	return trades, nil
 }
 
@@ -199,32 +407,45 @@ func (cl *WebSocketPrivate) TradeCancelAll() (
 func (cl *WebSocketPrivate) TradeCancelAsk(pairName string, id int64) (
 	trade *TradeResponse, err error,
 ) {
-	if id <= 0 {
-		return nil, ErrInvalidTradeID
-	}
-	wsparams := &WebSocketParams{
-		TradeRequest: TradeRequest{
-			Pair: pairName,
-		},
-		TradeID: id,
-	}
-	return cl.sendTradeRequest(http.MethodDelete, APITradeCancelAsk, wsparams)
+	return cl.cancel(APITradeCancelAsk, pairName, id)
 }
 
 // TradeCancelBid cancel the specific open buy by pair and ID.
 func (cl *WebSocketPrivate) TradeCancelBid(pairName string, id int64) (
 	trade *TradeResponse, err error,
 ) {
+	return cl.cancel(APITradeCancelBid, pairName, id)
+}
+
+func (cl *WebSocketPrivate) cancel(api, pairName string, id int64) (
+	trade *TradeResponse, err error,
+) {
+	if cl.Markets != nil {
+		err = cl.Markets.Validate(Pair(pairName))
+		if err != nil {
+			return nil, err
+		}
+	}
 	if id <= 0 {
 		return nil, ErrInvalidTradeID
 	}
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pairName,
+			Pair: Pair(pairName),
 		},
 		TradeID: id,
 	}
-	return cl.sendTradeRequest(http.MethodDelete, APITradeCancelBid, wsparams)
+	trade, err = cl.sendTradeRequest(http.MethodDelete, api, wsparams)
+	if err != nil {
+		return nil, err
+	}
+	if cl.env.IsDryRun {
+		return trade, nil
+	}
+	forgetRisk(cl.Risk, id)
+	forgetSelfTrade(cl.SelfTrade, id)
+	recordBalance(cl.Balances, api, nil, trade)
+	return trade, nil
 }
 
 // UserInfo fetch the user information and balances.
@@ -259,7 +480,7 @@ func (cl *WebSocketPrivate) UserOrderInfo(pairName string, id int64) (
 	}
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pairName,
+			Pair: Pair(pairName),
 		},
 		TradeID: id,
 	}
@@ -290,7 +511,7 @@ func (cl *WebSocketPrivate) UserOrdersOpen(pairName string) (
 ) {
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pairName,
+			Pair: Pair(pairName),
 		},
 	}
 
@@ -314,20 +535,41 @@ func (cl *WebSocketPrivate) UserOrdersOpen(pairName string) (
 	return pairTradesOpen, nil
 }
 
-func (cl *WebSocketPrivate) connect() error {
+// newConn create new WebSocket client that is not connected yet.
+func (cl *WebSocketPrivate) newConn() (conn *websocket.Client) {
+	conn = &websocket.Client{
+		Headers: make(http.Header),
+	}
+	if cl.env.IsInsecure {
+		conn.TLSConfig = &tls.Config{
+			InsecureSkipVerify: cl.env.IsInsecure,
+		}
+	}
+	conn.HandleText = cl.handleText
+	conn.HandleQuit = func() {
+		cl.handleUnexpectedQuit(conn)
+	}
+	return conn
+}
+
+// connect sign the connection using the token and secret and connect it to
+// the server.
+func (cl *WebSocketPrivate) connect(
+	conn *websocket.Client, token string, secret Secret,
+) error {
 	params := make(url.Values)
 
 	params.Set(ParamNameTimestamp, timestampAsString())
 
 	payload := params.Encode()
-	sign := Sign(payload, cl.env.Secret)
+	sign := Sign(payload, string(secret))
 
-	cl.conn.Endpoint = cl.env.Address + WSPrivate + "?" + payload
+	conn.Endpoint = cl.env.Address + WSPrivate + "?" + payload
 
-	cl.conn.Headers.Set(HeaderNameKey, cl.env.Token)
-	cl.conn.Headers.Set(HeaderNameSign, sign)
+	conn.Headers.Set(HeaderNameKey, token)
+	conn.Headers.Set(HeaderNameSign, sign)
 
-	err := cl.conn.Connect()
+	err := conn.Connect()
 	if err != nil {
 		return fmt.Errorf("connect: %w", err)
 	}
@@ -335,6 +577,16 @@ func (cl *WebSocketPrivate) connect() error {
 	return nil
 }
 
+// failRequests release all of the pending requests with nil response.
+// The caller must hold the requestsLocker.
+func (cl *WebSocketPrivate) failRequests() {
+	for id, ch := range cl.requests {
+		ch <- nil
+		close(ch)
+		delete(cl.requests, id)
+	}
+}
+
 func (cl *WebSocketPrivate) send(
 	method, target string, wsparams *WebSocketParams,
 ) (
@@ -361,18 +613,27 @@ func (cl *WebSocketPrivate) send(
 		return nil, err
 	}
 
-	chres := cl.requestPush(req)
+	chres, conn := cl.requestPush(req)
 
-	err = cl.conn.SendText(payload)
+	err = conn.SendText(payload)
 	if err != nil {
 		cl.requestPop(req.ID)
 		return nil, err
 	}
 
 	res = <-chres
+	if res == nil {
+		// The connection is closed or replaced before the response
+		// is received.
+		return nil, fmt.Errorf("%s %s: %w", method, target,
+			websocket.ErrConnClosed)
+	}
 
 	if res.Code != http.StatusOK {
-		return nil, errors.New(res.Message)
+		return nil, &liberrors.E{
+			Code:    int(res.Code),
+			Message: res.Message,
+		}
 	}
 
 	return res, nil
@@ -383,6 +644,10 @@ func (cl *WebSocketPrivate) sendTradeRequest(
 ) (
 	trade *TradeResponse, err error,
 ) {
+	if cl.env.IsDryRun {
+		return cl.dryRunTradeRequest(method, target, wsparams)
+	}
+
 	res, err := cl.send(method, target, wsparams)
 	if err != nil {
 		return nil, err
@@ -403,6 +668,30 @@ func (cl *WebSocketPrivate) sendTradeRequest(
 	return trade, nil
 }
 
+// dryRunTradeRequest log the trade request and return the synthetic
+// response.
+func (cl *WebSocketPrivate) dryRunTradeRequest(
+	method, target string, wsparams *WebSocketParams,
+) (
+	trade *TradeResponse, err error,
+) {
+	body, err := wsparams.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	dryRunLog(method, target, nil, body)
+
+	switch target {
+	case APITradeCancelAsk, APITradeCancelBid:
+		trade = newDryRunCancelResponse(target, wsparams.Pair.String(),
+			wsparams.TradeID)
+	default:
+		trade = newDryRunTradeResponse(target, &wsparams.TradeRequest)
+	}
+	return trade, nil
+}
+
 func (cl *WebSocketPrivate) handleText(
 	wsclient *websocket.Client, frame *websocket.Frame,
 ) (
@@ -429,10 +718,6 @@ func (cl *WebSocketPrivate) handleText(
 
 	// Handle broadcast from server.
 	if res.Message == APIUserOrdersClosed {
-		if cl.HandleOrdersClosed == nil {
-			return nil
-		}
-
 		resb, err := base64.StdEncoding.DecodeString(res.Body)
 		if err != nil {
 			log.Printf("handleText: %s %s",
@@ -447,16 +732,42 @@ func (cl *WebSocketPrivate) handleText(
 				APIUserOrdersClosed, err.Error())
 			return nil
 		}
-		cl.HandleOrdersClosed(trade)
+		cl.handleOrdersClosed(trade)
 	}
 
 	return nil
 }
 
-func (cl *WebSocketPrivate) handleUnexpectedQuit() {
+// handleOrdersClosed pass the closed order to ClientOrders, all of the
+// registered handlers, and then to HandleOrdersClosed.
+func (cl *WebSocketPrivate) handleOrdersClosed(trade *Trade) {
+	cl.handlersLocker.Lock()
+	handlers := make([]OrdersClosedHandler, len(cl.ordersClosedHandlers))
+	copy(handlers, cl.ordersClosedHandlers)
+	cl.handlersLocker.Unlock()
+
+	if cl.ClientOrders != nil {
+		cl.ClientOrders.HandleOrdersClosed(trade)
+	}
+	for _, handler := range handlers {
+		handler(trade)
+	}
+	if cl.HandleOrdersClosed != nil {
+		cl.HandleOrdersClosed(trade)
+	}
+}
+
+// handleUnexpectedQuit reconnect the conn if its still the current
+// connection.
+func (cl *WebSocketPrivate) handleUnexpectedQuit(conn *websocket.Client) {
+	if !cl.isCurrentConn(conn) {
+		return
+	}
 	log.Println("handleUnexpectedQuit: disconnected ...")
-	for {
-		err := cl.connect()
+	atomic.StoreInt64(&cl.disconnectedAt, time.Now().UnixNano())
+	for cl.isCurrentConn(conn) {
+		token, secret := cl.env.credential()
+		err := cl.connect(conn, token, secret)
 		if err != nil {
 			log.Printf("Connect: %s", err.Error())
 			time.Sleep(5 * time.Second)
@@ -464,17 +775,29 @@ func (cl *WebSocketPrivate) handleUnexpectedQuit() {
 		}
 		break
 	}
+	atomic.StoreInt64(&cl.disconnectedAt, 0)
 	log.Println("handleUnexpectedQuit: reconnected ...")
 }
 
+// isCurrentConn return true if the conn has not been replaced by
+// RotateCredential.
+func (cl *WebSocketPrivate) isCurrentConn(conn *websocket.Client) bool {
+	cl.requestsLocker.Lock()
+	defer cl.requestsLocker.Unlock()
+	return cl.conn == conn
+}
+
+// requestPush register the request and return the channel for its
+// response and the connection where the request should be sent.
 func (cl *WebSocketPrivate) requestPush(req *websocket.Request) (
-	chres chan *websocket.Response,
+	chres chan *websocket.Response, conn *websocket.Client,
 ) {
 	chres = make(chan *websocket.Response, 1)
 	cl.requestsLocker.Lock()
 	cl.requests[req.ID] = chres
+	conn = cl.conn
 	cl.requestsLocker.Unlock()
-	return chres
+	return chres, conn
 }
 
 func (cl *WebSocketPrivate) requestPop(id uint64) (
@@ -16,7 +16,7 @@ func TestWebSocketPrivate_UserInfo(t *testing.T) {
 	env := &Environment{
 		Address: os.Getenv(EnvNameAddress),
 		Token:   os.Getenv(EnvNameToken),
-		Secret:  os.Getenv(EnvNameSecret),
+		Secret:  Secret(os.Getenv(EnvNameSecret)),
 	}
 
 	cl, err := NewWebSocketPrivate(env)
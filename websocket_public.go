@@ -36,9 +36,24 @@ type WebSocketPublic struct {
 	// (open, closed, cancelled order) after calling SubscribeTrades
 	// method.
 	NotifTrades <-chan Trade
+
+	// NotifDepths is a channel that will receive the market depths after
+	// calling SubscribeDepths method.
+	// If there are channels from NotifyDepths, the depths are dropped
+	// from this channel when its full, instead of blocking them.
 	NotifDepths <-chan MarketDepths
 
+	// HandleReconnected, optional, define the callback that will be
+	// called after the client reconnected to server.
+	// The notifications while disconnected are lost, so the application
+	// may use this callback to resubscribe and refresh its state.
+	HandleReconnected func()
+
+	// depthsNotifs contains the channels from NotifyDepths.
+	depthsNotifs []chan MarketDepths
+
 	requestsLocker sync.Mutex
+	notifsLocker   sync.Mutex
 }
 
 // NewWebSocketPublic create new WebSocket connection to public APIs.
@@ -106,7 +121,7 @@ func (cl *WebSocketPublic) MarketDepths(pair string) (
 
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pair,
+			Pair: Pair(pair),
 		},
 	}
 
@@ -150,7 +165,7 @@ func (cl *WebSocketPublic) MarketTicker(pair string) (tick *MarketTicker, err er
 
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pair,
+			Pair: Pair(pair),
 		},
 	}
 
@@ -197,7 +212,7 @@ func (cl *WebSocketPublic) MarketTrades(pair string, offset, limit int64) (
 
 	wsparams := &WebSocketParams{
 		TradeRequest: TradeRequest{
-			Pair: pair,
+			Pair: Pair(pair),
 		},
 		Offset: offset,
 		Limit:  limit,
@@ -218,6 +233,36 @@ func (cl *WebSocketPublic) MarketTrades(pair string, offset, limit int64) (
 	return marketTrades, nil
 }
 
+// NotifyDepths return new channel that receive the copy of each market
+// depths broadcast, independent of NotifDepths and the other channels, so
+// the depths can be consumed by more than one consumer, for example
+// MarketMaker and ArbitrageScanner.
+// The remove function must be called when the channel no longer consumed;
+// the channel is not closed.
+//
+// The depths is send without blocking the WebSocket; if the channel is
+// full, the oldest depths in the channel is dropped.
+func (cl *WebSocketPublic) NotifyDepths() (notif <-chan MarketDepths, remove func()) {
+	ch := make(chan MarketDepths, maxQueue)
+
+	cl.notifsLocker.Lock()
+	cl.depthsNotifs = append(cl.depthsNotifs, ch)
+	cl.notifsLocker.Unlock()
+
+	remove = func() {
+		cl.notifsLocker.Lock()
+		defer cl.notifsLocker.Unlock()
+		for x, other := range cl.depthsNotifs {
+			if other == ch {
+				cl.depthsNotifs = append(cl.depthsNotifs[:x],
+					cl.depthsNotifs[x+1:]...)
+				return
+			}
+		}
+	}
+	return ch, remove
+}
+
 // Subscription return the list and status of subscription.
 func (cl *WebSocketPublic) Subscription() (*PublicSubscription, error) {
 	_, resbody, err := cl.send(http.MethodGet, WSPublicSubscription, nil)
@@ -419,7 +464,7 @@ func (cl *WebSocketPublic) handleText(
 					res.Message, err)
 				return nil
 			}
-			cl.topicDepths <- depths
+			cl.notifyDepths(depths)
 		}
 	} else {
 		chres := cl.requestPop(res.ID)
@@ -432,6 +477,43 @@ func (cl *WebSocketPublic) handleText(
 	return nil
 }
 
+// notifyDepths send the depths to NotifDepths and each channel from
+// NotifyDepths.
+func (cl *WebSocketPublic) notifyDepths(depths MarketDepths) {
+	cl.notifsLocker.Lock()
+	notifs := make([]chan MarketDepths, len(cl.depthsNotifs))
+	copy(notifs, cl.depthsNotifs)
+	cl.notifsLocker.Unlock()
+
+	if len(notifs) == 0 {
+		cl.topicDepths <- depths
+		return
+	}
+	select {
+	case cl.topicDepths <- depths:
+	default:
+	}
+	for _, ch := range notifs {
+		sendLatestDepths(ch, depths)
+	}
+}
+
+// sendLatestDepths send the depths to ch without blocking, by dropping
+// the oldest depths in ch if its full.
+func sendLatestDepths(ch chan MarketDepths, depths MarketDepths) {
+	for {
+		select {
+		case ch <- depths:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
 func (cl *WebSocketPublic) handleUnexpectedQuit() {
 	log.Println("handleUnexpectedQuit: disconnected ...")
 	for {
@@ -444,6 +526,9 @@ func (cl *WebSocketPublic) handleUnexpectedQuit() {
 		break
 	}
 	log.Println("handleUnexpectedQuit: reconnected ...")
+	if cl.HandleReconnected != nil {
+		go cl.HandleReconnected()
+	}
 }
 
 func (cl *WebSocketPublic) requestPush(req *websocket.Request) (
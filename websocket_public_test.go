@@ -0,0 +1,47 @@
+// Copyright 2026 Tokenomy Technologies Ltd. All rights reserved.
+// Use of this source code is governed by a MIT-style license that can be
+// found in the LICENSE file.
+
+package tokenomy
+
+import (
+	"testing"
+
+	"github.com/shuLhan/share/lib/test"
+)
+
+func TestWebSocketPublic_NotifyDepths(t *testing.T) {
+	cl := &WebSocketPublic{
+		topicDepths: make(chan MarketDepths, 1),
+	}
+	cl.NotifDepths = cl.topicDepths
+
+	notifA, removeA := cl.NotifyDepths()
+	notifB, removeB := cl.NotifyDepths()
+	defer removeB()
+
+	// The full NotifDepths does not block the other channels.
+	cl.notifyDepths(MarketDepths{})
+	cl.notifyDepths(MarketDepths{})
+	test.Assert(t, "NotifDepths", 1, len(cl.NotifDepths))
+	test.Assert(t, "notifA", 2, len(notifA))
+	test.Assert(t, "notifB", 2, len(notifB))
+
+	removeA()
+	cl.notifyDepths(MarketDepths{})
+	test.Assert(t, "notifA after remove", 2, len(notifA))
+	test.Assert(t, "notifB", 3, len(notifB))
+
+	// The full channel keep the latest depths, instead of blocking.
+	for x := len(notifB); x < cap(notifB); x++ {
+		cl.notifyDepths(MarketDepths{})
+	}
+	cl.notifyDepths(MarketDepths{Pair: "latest"})
+	test.Assert(t, "notifB full", cap(notifB), len(notifB))
+
+	var last MarketDepths
+	for len(notifB) > 0 {
+		last = <-notifB
+	}
+	test.Assert(t, "notifB latest", "latest", last.Pair)
+}